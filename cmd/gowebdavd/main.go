@@ -7,7 +7,11 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"gowebdavd/internal/config"
 	"gowebdavd/internal/daemon"
 	"gowebdavd/internal/logger"
 	"gowebdavd/internal/pidfile"
@@ -33,6 +37,12 @@ func main() {
 	case "status":
 		handleStatus()
 
+	case "install-service":
+		handleInstallService()
+
+	case "uninstall-service":
+		handleUninstallService()
+
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", command)
 		printUsage()
@@ -48,48 +58,630 @@ func printUsage() {
 	fmt.Println("  stop    - Stop WebDAV server")
 	fmt.Println("  status  - Show service status")
 	fmt.Println("  run     - Run WebDAV server in foreground")
+	fmt.Println("  install-service    - Register as a Windows service running with the given options")
+	fmt.Println("  uninstall-service  - Remove the Windows service")
 	fmt.Println("")
-	fmt.Println("Options for start/run:")
-	fmt.Println("  -dir string    Directory to serve (default \".\")")
+	fmt.Println("Options for start/run/install-service:")
+	fmt.Println("  -config path   TOML file with flag values; flags on the command line take precedence")
+	fmt.Println("  -dir string    Directory to serve (default \".\"), or name=path to serve it under /name/ (repeatable)")
 	fmt.Println("  -port int      Port to listen on (default 8080)")
 	fmt.Println("  -bind string   IP address to bind to (default \"127.0.0.1\")")
 	fmt.Println("  -log           Enable HTTP request logging (default: false)")
 	fmt.Println("  -log-dir       Custom log directory (requires -log, must exist)")
+	fmt.Println("  -log-max-size  Start a new log file when the current one reaches this size, e.g. 50MB")
+	fmt.Println("  -log-async N   Buffer N log entries and write them in the background, dropping on overflow")
+	fmt.Println("  -log-retention-days N  Remove log files older than N days, 0 keeps all (default 30)")
+	fmt.Println("  -log-format    Log entry format: text (default), json, one object per request, or Apache combined")
+	fmt.Println("  -log-timezone  IANA timezone of json and combined log timestamps and midnight rotation, e.g. America/New_York (default: local)")
+	fmt.Println("  -log-stdout    Write log entries to standard output instead of a file, e.g. in containers")
+	fmt.Println("  -log-syslog    Send log entries to the local syslog daemon instead of a file (Unix only)")
+	fmt.Println("  -log-syslog-facility  Syslog facility of log entries, e.g. local0 (default: daemon)")
+	fmt.Println("  -accesslog-rotate-at-midnight  Start a new log file, named by its date, every midnight")
+	fmt.Println("  -single-instance-lock  Refuse to start if another instance serves the directory")
+	fmt.Println("  -response-buffer-size  Copy buffer size in bytes for file downloads (default: Go default)")
+	fmt.Println("  -listing       Serve an HTML listing for GET on directories (default: false)")
+	fmt.Println("  -index         Serve this file, e.g. index.html, for GET on directories holding it")
+	fmt.Println("  -etag          ETag of files: mtime (modification time and size, default) or strong (SHA-256 of the content)")
+	fmt.Println("  -mime-type ext=type  Content-Type for files with this extension, e.g. .md=text/markdown (repeatable)")
+	fmt.Println("  -fancy-index   Serve browsers a sortable HTML listing for GET on directories (default: false)")
+	fmt.Println("  -no-symlinks   Reject paths that symbolic links lead outside the served directory with 403")
+	fmt.Println("  -case-insensitive  Match request paths to existing files ignoring case")
+	fmt.Println("  -read-only     Reject every method that modifies files with 405")
+	fmt.Println("  -webhook-url   URL to POST a JSON event to after each successful PUT, DELETE, MOVE or MKCOL")
+	fmt.Println("  -webhook-methods  Comma-separated methods reported to -webhook-url (default: PUT,DELETE,MOVE,MKCOL)")
+	fmt.Println("  -disable-method  Reject a method such as DELETE with 405 and leave it out of Allow (repeatable)")
+	fmt.Println("  -dry-run       Log every modification, e.g. PUT or DELETE, instead of applying it")
+	fmt.Println("  -read-only-fallback  Switch to read-only mode while the file system rejects writes as read-only")
+	fmt.Println("  -read-only-locks  Answer LOCK/UNLOCK in read-only mode: forbid (403, default) or grant (no-op)")
+	fmt.Println("  -dir-config    Apply .gowebdavd.json files to the subtree of their directory")
+	fmt.Println("  -dead-props    Keep properties set with PROPPATCH in .gowebdavd-props.json files")
+	fmt.Println("  -rename-on-conflict  Store a PUT to an existing file as \"name (1).ext\" instead of overwriting it")
+	fmt.Println("  -cross-device-move  Complete MOVE between mounted filesystems by copying, then removing the source")
+	fmt.Println("  -partial-put   Write PUT bodies with Content-Range at the range offset so uploads can resume")
+	fmt.Println("  -preserve-mtime  Give files copied by COPY the modification times of their sources")
+	fmt.Println("  -delete-multistatus  Report collection members DELETE could not remove with 207 Multi-Status")
+	fmt.Println("  -lock-owner-required  Reject LOCK requests without an owner element")
+	fmt.Println("  -lock-timeout  Longest timeout a lock can have, e.g. 1h (default: unlimited)")
+	fmt.Println("  -lock-mode     Lock system: memory, none or readonly (default: memory)")
+	fmt.Println("  -lock-file path  Keep locks in a file shared with other instances")
+	fmt.Println("  -verbose-errors  Include the underlying error in 5xx responses (debugging only)")
+	fmt.Println("  -max-body      Maximum request body size, e.g. 100MB (default: unlimited)")
+	fmt.Println("  -quota         Maximum total size of the served files, e.g. 10GB (default: unlimited)")
+	fmt.Println("  -report-quota  Report quota-available-bytes and quota-used-bytes in PROPFIND (default: false)")
+	fmt.Println("  -max-move-copy-size  Reject COPY/MOVE of sources larger than this many bytes (default: no limit)")
+	fmt.Println("  -min-free-inodes N  Reject PUT/MKCOL/COPY with 507 when fewer inodes are free (Unix only)")
+	fmt.Println("  -protect-files Forbid writing or deleting protected file names like .htaccess")
+	fmt.Println("  -protected-names  Comma-separated protected names (requires -protect-files)")
+	fmt.Println("  -content-length-required  Reject PUT requests without Content-Length (chunked uploads) with 411")
+	fmt.Println("  -strip-props   Comma-separated live properties hidden from PROPFIND")
+	fmt.Println("  -propfind-allowed-depths  Comma-separated Depth values PROPFIND accepts: 0, 1, infinity (default: all)")
+	fmt.Println("  -batch-propfind  Answer a PROPFIND listing hrefs in its body for exactly those resources (default: false)")
+	fmt.Println("  -search        Answer DASL SEARCH requests for files by name, size and date (default: false)")
+	fmt.Println("  -checksums     Answer GET with ?checksum=sha256 or Want-Digest with a Digest header (default: false)")
+	fmt.Println("  -auth-basic user:pass  Require HTTP Basic authentication (repeatable)")
+	fmt.Println("  -auth-file     File with user:pass lines enabling authentication")
+	fmt.Println("  -auth-digest   Authenticate with HTTP Digest instead of Basic")
+	fmt.Println("  -auth-nonce-ttl  Validity of a Digest nonce (default 5m)")
+	fmt.Println("  -gzip          Compress responses for clients accepting gzip")
+	fmt.Println("  -gzip-min-size Smallest response body in bytes to compress (default 1024)")
+	fmt.Println("  -allow        Comma-separated CIDR ranges allowed to connect (default: all)")
+	fmt.Println("  -trusted-proxies  Comma-separated CIDR ranges of proxies trusted for X-Forwarded-For")
+	fmt.Println("  -rate-limit N  Allow each client IP at most N requests per second (default: unlimited)")
+	fmt.Println("  -max-request-rate-per-method  Per-method limits for each client IP, e.g. PROPFIND=10/s,PUT=5/s")
+	fmt.Println("  -max-conns-per-ip N  Close new connections from a client IP with N already open")
+	fmt.Println("  -bandwidth-total SIZE  Cap the combined download rate of all clients per second, e.g. 10MB")
+	fmt.Println("  -max-rate RATE  Cap uploads and downloads of all connections together, each direction, e.g. 2MB/s")
+	fmt.Println("  -max-rate-per-conn RATE  Cap uploads and downloads of each connection, each direction, e.g. 512KB/s")
+	fmt.Println("  -tcp-nodelay   Disable Nagle's algorithm on connections (default true)")
+	fmt.Println("  -dual-stack    Accept IPv4 on an IPv6 listener: on or off (default: OS setting)")
+	fmt.Println("  -tls-cert      PEM certificate file, serves HTTPS (requires -tls-key)")
+	fmt.Println("  -tls-key       PEM private key file (requires -tls-cert)")
+	fmt.Println("  -tls-self-signed  Serve HTTPS with a certificate generated at startup")
+	fmt.Println("  -serve-error-pages  Directory with custom HTML error pages, e.g. 404.html, for browsers")
+	fmt.Println("  -secure-headers  Add nosniff, X-Frame-Options and Content-Security-Policy headers, and HSTS over HTTPS")
+	fmt.Println("  -advertise-features  List enabled optional features in an X-GoWebDAVd-Features header on OPTIONS")
+	fmt.Println("  -health-body   Response body of the /health endpoint (default \"OK\")")
+	fmt.Println("  -health-status Status code of the /health endpoint (default 200)")
+	fmt.Println("  -metrics       Serve Prometheus metrics on /metrics (default: false)")
+	fmt.Println("  -stats         Report request counters, goroutines and uptime as JSON on /stats (default: false)")
+	fmt.Println("  -read-timeout  Time limit for reading a whole request, including its body (default: unlimited)")
+	fmt.Println("  -write-timeout  Time limit for writing a whole response (default: unlimited)")
+	fmt.Println("  -idle-timeout  Close keep-alive connections idle for longer, 0 keeps them open (default 2m)")
+	fmt.Println("  -request-timeout  Answer 503 to requests not answered within this time, e.g. 30s (default: unlimited)")
+	fmt.Println("  -upload-timeout  Time limit for PUT requests instead of -request-timeout (default: unlimited)")
+	fmt.Println("  -shutdown-timeout  Time to wait for in-flight requests on shutdown, 0 waits without limit (default 30s)")
+	fmt.Println("  -shutdown-file path  Shut down gracefully once this file appears")
+	fmt.Println("  -graceful-lock-release-on-shutdown  Unlock and log the locks still held on shutdown")
+	fmt.Println("")
+	fmt.Println("Options for start/stop/status/run:")
+	fmt.Println("  -pidfile path  PID file of the background service (default: gowebdavd.pid in the temporary directory)")
+	fmt.Println("")
+	fmt.Println("Options for start:")
+	fmt.Println("  -daemon-log-file  File receiving the background process stdout/stderr")
+	fmt.Println("  -supervised    Stay in the foreground and stop the server if start is killed (Linux)")
+	fmt.Println("  -start-timeout  How long to wait for the server to answer /livez (default 10s, 0 does not wait)")
+}
+
+// startFlags holds the flags shared by start and run
+type startFlags struct {
+	fs              *flag.FlagSet
+	configFile      *string
+	dirs            stringList
+	zipFile         *string
+	port            *int
+	bind            *string
+	enableLog       *bool
+	logDir          *string
+	logAsync        *int
+	logMaxSize      *string
+	logRetention    *int
+	logFormat       *string
+	logTimezone     *string
+	logDaily        *bool
+	logSyslog       *bool
+	logStdout       *bool
+	logFacility     *string
+	singleInstance  *bool
+	bufferSize      *int
+	listing         *bool
+	fancyIndex      *bool
+	index           *string
+	caseInsensitive *bool
+	noSymlinks      *bool
+	readOnly        *bool
+	dryRun          *bool
+	roFallback      *bool
+	readOnlyLocks   *string
+	dirConfig       *bool
+	deadProps       *bool
+	deleteStatus    *bool
+	crossDevice     *bool
+	preserveMtime   *bool
+	partialPut      *bool
+	renameConflict  *bool
+	lengthRequired  *bool
+	lockOwner       *bool
+	lockTimeout     *time.Duration
+	lockMode        *string
+	lockFile        *string
+	verboseErrors   *bool
+	maxBody         *string
+	quota           *string
+	reportQuota     *bool
+	maxMoveCopy     *int64
+	minFreeInodes   *int64
+	protectFiles    *bool
+	protectedNames  *string
+	stripPropsList  *string
+	propfindDepths  *string
+	batchPropfind   *bool
+	search          *bool
+	checksums       *bool
+	authBasic       stringList
+	mimeTypes       stringList
+	etag            *string
+	disabledMethods stringList
+	webhookURL      *string
+	webhookMethods  *string
+	authFile        *string
+	authDigest      *bool
+	nonceTTL        *time.Duration
+	gzipResponses   *bool
+	gzipMinSize     *int
+	allow           *string
+	trustedProxies  *string
+	rateLimit       *int
+	methodRates     *string
+	maxConnsPerIP   *int
+	bandwidthTotal  *string
+	maxRate         *string
+	maxRatePerConn  *string
+	tcpNoDelay      *bool
+	dualStack       *string
+	tlsCert         *string
+	tlsKey          *string
+	tlsSelfSigned   *bool
+	secureHeaders   *bool
+	errorPages      *string
+	advertise       *bool
+	healthBody      *string
+	healthStatus    *int
+	metrics         *bool
+	stats           *bool
+	shutdownFile    *string
+	releaseLocks    *bool
+	shutdownTimeout *time.Duration
+	readTimeout     *time.Duration
+	writeTimeout    *time.Duration
+	idleTimeout     *time.Duration
+	requestTimeout  *time.Duration
+	uploadTimeout   *time.Duration
+	pidFile         *string
+	daemonLogFile   *string
+	supervised      *bool
+	startTimeout    *time.Duration
+}
+
+// newStartFlags defines the start and run flags on a new flag set
+func newStartFlags(errorHandling flag.ErrorHandling) *startFlags {
+	fs := flag.NewFlagSet("start", errorHandling)
+	f := &startFlags{fs: fs}
+	f.configFile = fs.String("config", "", "TOML file with flag values")
+	fs.Var(&f.dirs, "dir", "Directory, or name=path mount (repeatable)")
+	f.zipFile = fs.String("zip", "", "Serve a zip archive read-only instead of a directory")
+	f.port = fs.Int("port", 8080, "Port")
+	f.bind = fs.String("bind", "127.0.0.1", "IP")
+	f.enableLog = fs.Bool("log", false, "Enable HTTP request logging")
+	f.logDir = fs.String("log-dir", "", "Custom log directory (requires -log)")
+	f.logMaxSize = fs.String("log-max-size", "0", "Start a new log file when the current one reaches this size, e.g. 50MB (requires -log)")
+	f.logRetention = fs.Int("log-retention-days", logger.DefaultRetentionDays, "Remove log files older than N days, 0 keeps all (requires -log)")
+	f.logFormat = fs.String("log-format", "text", "Log entry format: text, json or combined (requires -log)")
+	f.logTimezone = fs.String("log-timezone", "", "IANA timezone of json and combined log timestamps and midnight rotation (requires -log)")
+	f.logStdout = fs.Bool("log-stdout", false, "Write log entries to standard output instead of a file (requires -log)")
+	f.logSyslog = fs.Bool("log-syslog", false, "Send log entries to the local syslog daemon instead of a file (requires -log, Unix only)")
+	f.logFacility = fs.String("log-syslog-facility", logger.DefaultSyslogFacility, "Syslog facility of log entries, e.g. daemon or local0")
+	f.logDaily = fs.Bool("accesslog-rotate-at-midnight", false, "Start a new log file, named by its date, every midnight (requires -log)")
+	f.logAsync = fs.Int("log-async", 0, "Write log entries asynchronously, buffering N entries and dropping on overflow (requires -log)")
+	f.singleInstance = fs.Bool("single-instance-lock", false, "Refuse to start if another instance serves the directory")
+	f.bufferSize = fs.Int("response-buffer-size", 0, "Copy buffer size in bytes for file downloads")
+	f.listing = fs.Bool("listing", false, "Serve an HTML listing for GET on directories")
+	f.index = fs.String("index", "", "Serve this file, e.g. index.html, for GET on directories holding it")
+	f.etag = fs.String("etag", server.ETagModTime, "ETag of files: mtime (modification time and size) or strong (SHA-256 of the content)")
+	fs.Var(&f.mimeTypes, "mime-type", "Content-Type for an extension as ext=type, e.g. .md=text/markdown (repeatable)")
+	f.fancyIndex = fs.Bool("fancy-index", false, "Serve browsers a sortable HTML listing for GET on directories")
+	f.noSymlinks = fs.Bool("no-symlinks", false, "Reject paths that symbolic links lead outside the served directory with 403")
+	f.caseInsensitive = fs.Bool("case-insensitive", false, "Match request paths to existing files ignoring case")
+	f.readOnly = fs.Bool("read-only", false, "Reject every method that modifies files with 405")
+	f.webhookURL = fs.String("webhook-url", "", "URL to POST a JSON event to after each successful change")
+	f.webhookMethods = fs.String("webhook-methods", strings.Join(server.DefaultWebhookMethods, ","), "Comma-separated methods reported to -webhook-url")
+	fs.Var(&f.disabledMethods, "disable-method", "Reject a method such as DELETE with 405 (repeatable)")
+	f.dryRun = fs.Bool("dry-run", false, "Log every modification instead of applying it")
+	f.roFallback = fs.Bool("read-only-fallback", false, "Switch to read-only mode while the file system rejects writes as read-only")
+	f.readOnlyLocks = fs.String("read-only-locks", server.ReadOnlyLocksForbid, "Answer LOCK/UNLOCK in read-only mode: forbid or grant")
+	f.renameConflict = fs.Bool("rename-on-conflict", false, "Store a PUT to an existing file under a new name instead of overwriting it")
+	f.crossDevice = fs.Bool("cross-device-move", false, "Complete MOVE between filesystems mounted below -dir by copying, then removing the source")
+	f.partialPut = fs.Bool("partial-put", false, "Write PUT bodies with Content-Range at the range offset so uploads can resume")
+	f.preserveMtime = fs.Bool("preserve-mtime", false, "Give files copied by COPY the modification times of their sources")
+	f.deleteStatus = fs.Bool("delete-multistatus", false, "Report collection members DELETE could not remove with 207 Multi-Status")
+	f.dirConfig = fs.Bool("dir-config", false, "Apply .gowebdavd.json files to the subtree of their directory")
+	f.deadProps = fs.Bool("dead-props", false, "Keep properties set with PROPPATCH in .gowebdavd-props.json files")
+	f.lengthRequired = fs.Bool("content-length-required", false, "Reject PUT requests without Content-Length with 411")
+	f.lockOwner = fs.Bool("lock-owner-required", false, "Reject LOCK requests without an owner element")
+	f.lockTimeout = fs.Duration("lock-timeout", 0, "Longest timeout a lock can have, e.g. 1h (0 = unlimited)")
+	f.lockMode = fs.String("lock-mode", string(server.LockModeMemory), "Lock system: memory, none or readonly")
+	f.lockFile = fs.String("lock-file", "", "Keep locks in a file shared with other instances")
+	f.verboseErrors = fs.Bool("verbose-errors", false, "Include the underlying error in 5xx responses")
+	f.maxBody = fs.String("max-body", "0", "Maximum request body size, e.g. 100MB (0 = unlimited)")
+	f.quota = fs.String("quota", "0", "Maximum total size of the served files, e.g. 10GB (0 = unlimited)")
+	f.reportQuota = fs.Bool("report-quota", false, "Report quota-available-bytes and quota-used-bytes in PROPFIND")
+	f.maxMoveCopy = fs.Int64("max-move-copy-size", 0, "Reject COPY/MOVE of sources larger than this many bytes")
+	f.minFreeInodes = fs.Int64("min-free-inodes", 0, "Reject PUT/MKCOL/COPY with 507 when fewer inodes are free (Unix only)")
+	f.protectFiles = fs.Bool("protect-files", false, "Forbid writing or deleting protected file names like .htaccess")
+	f.protectedNames = fs.String("protected-names", strings.Join(server.DefaultProtectedNames, ","), "Comma-separated protected names (requires -protect-files)")
+	f.stripPropsList = fs.String("strip-props", "", "Comma-separated live properties hidden from PROPFIND")
+	f.propfindDepths = fs.String("propfind-allowed-depths", "", "Comma-separated Depth values PROPFIND accepts: 0, 1, infinity (default: all)")
+	f.batchPropfind = fs.Bool("batch-propfind", false, "Answer a PROPFIND listing hrefs in its body for exactly those resources")
+	f.search = fs.Bool("search", false, "Answer DASL SEARCH requests for files by name, size and date")
+	f.checksums = fs.Bool("checksums", false, "Answer GET with ?checksum=sha256 or Want-Digest with a Digest header")
+	fs.Var(&f.authBasic, "auth-basic", "Require HTTP Basic authentication as user:pass (repeatable)")
+	f.authFile = fs.String("auth-file", "", "File with user:pass lines enabling authentication")
+	f.authDigest = fs.Bool("auth-digest", false, "Authenticate with HTTP Digest instead of Basic")
+	f.nonceTTL = fs.Duration("auth-nonce-ttl", server.DefaultNonceTTL, "Validity of a Digest nonce")
+	f.gzipResponses = fs.Bool("gzip", false, "Compress responses for clients accepting gzip")
+	f.gzipMinSize = fs.Int("gzip-min-size", server.DefaultGzipMinSize, "Smallest response body in bytes to compress")
+	f.allow = fs.String("allow", "", "Comma-separated CIDR ranges allowed to connect")
+	f.trustedProxies = fs.String("trusted-proxies", "", "Comma-separated CIDR ranges of proxies trusted for X-Forwarded-For")
+	f.rateLimit = fs.Int("rate-limit", 0, "Allow each client IP at most N requests per second")
+	f.methodRates = fs.String("max-request-rate-per-method", "", "Per-method limits for each client IP, e.g. PROPFIND=10/s,PUT=5/s")
+	f.maxConnsPerIP = fs.Int("max-conns-per-ip", 0, "Close new connections from a client IP with N already open (0 = unlimited)")
+	f.maxRate = fs.String("max-rate", "0", "Body bytes per second of all connections together, each direction, e.g. 2MB/s (0 = unlimited)")
+	f.maxRatePerConn = fs.String("max-rate-per-conn", "0", "Body bytes per second of each connection, each direction, e.g. 512KB/s (0 = unlimited)")
+	f.bandwidthTotal = fs.String("bandwidth-total", "0", "Combined response bytes per second of all clients, e.g. 10MB (0 = unlimited)")
+	f.tcpNoDelay = fs.Bool("tcp-nodelay", true, "Disable Nagle's algorithm on connections")
+	f.dualStack = fs.String("dual-stack", "", "Accept IPv4 on an IPv6 listener: on or off (default: OS setting)")
+	f.tlsCert = fs.String("tls-cert", "", "PEM certificate file, serves HTTPS (requires -tls-key)")
+	f.tlsKey = fs.String("tls-key", "", "PEM private key file (requires -tls-cert)")
+	f.tlsSelfSigned = fs.Bool("tls-self-signed", false, "Serve HTTPS with a certificate generated at startup")
+	f.errorPages = fs.String("serve-error-pages", "", "Directory with custom HTML error pages, e.g. 404.html, for browsers")
+	f.secureHeaders = fs.Bool("secure-headers", false, "Add nosniff, X-Frame-Options and Content-Security-Policy headers, and HSTS over HTTPS")
+	f.advertise = fs.Bool("advertise-features", false, "List enabled optional features in an X-GoWebDAVd-Features header on OPTIONS")
+	f.healthBody = fs.String("health-body", server.DefaultHealthBody, "Response body of the /health endpoint")
+	f.healthStatus = fs.Int("health-status", server.DefaultHealthStatus, "Status code of the /health endpoint")
+	f.metrics = fs.Bool("metrics", false, "Serve Prometheus metrics on /metrics")
+	f.stats = fs.Bool("stats", false, "Report request counters, goroutines and uptime on /stats (requires credentials unless -bind is loopback)")
+	f.readTimeout = fs.Duration("read-timeout", 0, "Time limit for reading a whole request, including its body (0 = unlimited)")
+	f.writeTimeout = fs.Duration("write-timeout", 0, "Time limit for writing a whole response (0 = unlimited)")
+	f.idleTimeout = fs.Duration("idle-timeout", server.DefaultIdleTimeout, "Close keep-alive connections idle for longer, 0 keeps them open without limit")
+	f.requestTimeout = fs.Duration("request-timeout", 0, "Answer 503 to requests not answered within this time, e.g. 30s (0 = unlimited)")
+	f.uploadTimeout = fs.Duration("upload-timeout", 0, "Time limit for PUT requests instead of -request-timeout (0 = unlimited)")
+	f.shutdownTimeout = fs.Duration("shutdown-timeout", server.DefaultShutdownTimeout, "Time to wait for in-flight requests on shutdown, 0 waits without limit")
+	f.shutdownFile = fs.String("shutdown-file", "", "Shut down gracefully once this file appears")
+	f.releaseLocks = fs.Bool("graceful-lock-release-on-shutdown", false, "Unlock and log the locks still held on shutdown")
+	f.pidFile = fs.String("pidfile", "", "PID file of the background service")
+	f.daemonLogFile = fs.String("daemon-log-file", "", "File receiving the background process stdout/stderr")
+	f.supervised = fs.Bool("supervised", false, "Stay in the foreground and stop the server if start is killed (Linux)")
+	f.startTimeout = fs.Duration("start-timeout", daemon.DefaultStartTimeout, "How long start waits for the background server to answer /livez, 0 does not wait")
+	return f
+}
+
+// applyConfig fills the flags not given on the command line from the -config file
+func (f *startFlags) applyConfig() error {
+	if *f.configFile == "" {
+		return nil
+	}
+	values, err := config.Load(*f.configFile)
+	if err != nil {
+		return err
+	}
+	return config.Apply(f.fs, values)
+}
+
+// serverOptions converts the flags into server options
+func (f *startFlags) serverOptions() (server.Options, error) {
+	folder, mounts, err := parseDirs(f.dirs)
+	if err != nil {
+		return server.Options{}, err
+	}
+	maxBodySize, err := server.ParseSize(*f.maxBody)
+	if err != nil {
+		return server.Options{}, fmt.Errorf("-max-body: %w", err)
+	}
+	quota, err := server.ParseSize(*f.quota)
+	if err != nil {
+		return server.Options{}, fmt.Errorf("-quota: %w", err)
+	}
+	bandwidthTotal, err := server.ParseSize(*f.bandwidthTotal)
+	if err != nil {
+		return server.Options{}, fmt.Errorf("-bandwidth-total: %w", err)
+	}
+	maxRate, err := server.ParseRate(*f.maxRate)
+	if err != nil {
+		return server.Options{}, fmt.Errorf("-max-rate: %w", err)
+	}
+	maxRatePerConn, err := server.ParseRate(*f.maxRatePerConn)
+	if err != nil {
+		return server.Options{}, fmt.Errorf("-max-rate-per-conn: %w", err)
+	}
+	methodRates, err := server.ParseMethodRates(*f.methodRates)
+	if err != nil {
+		return server.Options{}, fmt.Errorf("-max-request-rate-per-method: %w", err)
+	}
+	mimeTypes, err := server.ParseMIMETypes(f.mimeTypes)
+	if err != nil {
+		return server.Options{}, fmt.Errorf("-mime-type: %w", err)
+	}
+	disabledMethods, err := server.ParseDisabledMethods(f.disabledMethods)
+	if err != nil {
+		return server.Options{}, fmt.Errorf("-disable-method: %w", err)
+	}
+	webhookMethods, err := server.ParseWebhookMethods(*f.webhookMethods)
+	if err != nil {
+		return server.Options{}, fmt.Errorf("-webhook-methods: %w", err)
+	}
+	entries := append([]string(nil), f.authBasic...)
+	if v := os.Getenv(authBasicEnv); v != "" {
+		entries = append(entries, strings.Split(v, "\n")...)
+	}
+	creds, err := loadCredentials(entries, *f.authFile)
+	if err != nil {
+		return server.Options{}, err
+	}
+	shutdownTimeout := *f.shutdownTimeout
+	switch {
+	case shutdownTimeout < 0:
+		return server.Options{}, fmt.Errorf("-shutdown-timeout must not be negative: %s", shutdownTimeout)
+	case shutdownTimeout == 0:
+		// server.Options reserve 0 for the default
+		shutdownTimeout = -1
+	}
+	idleTimeout := *f.idleTimeout
+	switch {
+	case idleTimeout < 0:
+		return server.Options{}, fmt.Errorf("-idle-timeout must not be negative: %s", idleTimeout)
+	case idleTimeout == 0:
+		idleTimeout = -1
+	}
+	var protected []string
+	if *f.protectFiles {
+		protected = splitList(*f.protectedNames)
+	}
+
+	return server.Options{
+		Folder:                folder,
+		ZipFile:               *f.zipFile,
+		Mounts:                mounts,
+		Port:                  *f.port,
+		Bind:                  *f.bind,
+		SingleInstanceLock:    *f.singleInstance,
+		ResponseBufferSize:    *f.bufferSize,
+		DirListing:            *f.listing,
+		FancyIndex:            *f.fancyIndex,
+		Index:                 *f.index,
+		MIMETypes:             mimeTypes,
+		ETag:                  *f.etag,
+		CaseInsensitive:       *f.caseInsensitive,
+		NoSymlinks:            *f.noSymlinks,
+		ReadOnly:              *f.readOnly,
+		DryRun:                *f.dryRun,
+		DisabledMethods:       disabledMethods,
+		WebhookURL:            *f.webhookURL,
+		WebhookMethods:        webhookMethods,
+		ReadOnlyFallback:      *f.roFallback,
+		ReadOnlyLocks:         *f.readOnlyLocks,
+		DirConfig:             *f.dirConfig,
+		DeadProps:             *f.deadProps,
+		DeleteMultiStatus:     *f.deleteStatus,
+		CrossDeviceMove:       *f.crossDevice,
+		PreserveModTimes:      *f.preserveMtime,
+		PartialPut:            *f.partialPut,
+		RenameOnConflict:      *f.renameConflict,
+		LockOwnerRequired:     *f.lockOwner,
+		LockTimeout:           *f.lockTimeout,
+		LockMode:              server.LockMode(*f.lockMode),
+		LockFile:              *f.lockFile,
+		ContentLengthRequired: *f.lengthRequired,
+		VerboseErrors:         *f.verboseErrors,
+		MaxBodySize:           maxBodySize,
+		Quota:                 quota,
+		ReportQuota:           *f.reportQuota,
+		MaxMoveCopySize:       *f.maxMoveCopy,
+		MinFreeInodes:         *f.minFreeInodes,
+		ProtectedNames:        protected,
+		StripProperties:       splitList(*f.stripPropsList),
+		PropfindAllowedDepths: splitList(*f.propfindDepths),
+		BatchPropfind:         *f.batchPropfind,
+		Search:                *f.search,
+		Checksums:             *f.checksums,
+		Credentials:           creds,
+		DigestAuth:            *f.authDigest,
+		NonceTTL:              *f.nonceTTL,
+		Gzip:                  *f.gzipResponses,
+		GzipMinSize:           *f.gzipMinSize,
+		AllowedNets:           splitList(*f.allow),
+		TrustedProxies:        splitList(*f.trustedProxies),
+		RateLimit:             *f.rateLimit,
+		MethodRateLimits:      methodRates,
+		MaxConnsPerIP:         *f.maxConnsPerIP,
+		BandwidthTotal:        bandwidthTotal,
+		MaxRate:               maxRate,
+		MaxRatePerConn:        maxRatePerConn,
+		DisableTCPNoDelay:     !*f.tcpNoDelay,
+		DualStack:             *f.dualStack,
+		TLSCert:               *f.tlsCert,
+		TLSKey:                *f.tlsKey,
+		TLSSelfSigned:         *f.tlsSelfSigned,
+		SecureHeaders:         *f.secureHeaders,
+		ErrorPagesDir:         *f.errorPages,
+		AdvertiseFeatures:     *f.advertise,
+		HealthBody:            *f.healthBody,
+		HealthStatus:          *f.healthStatus,
+		Metrics:               *f.metrics,
+		Stats:                 *f.stats,
+		ShutdownFile:          *f.shutdownFile,
+		ShutdownReleaseLocks:  *f.releaseLocks,
+		ShutdownTimeout:       shutdownTimeout,
+		ReadTimeout:           *f.readTimeout,
+		WriteTimeout:          *f.writeTimeout,
+		IdleTimeout:           idleTimeout,
+		RequestTimeout:        *f.requestTimeout,
+		UploadTimeout:         *f.uploadTimeout,
+	}, nil
+}
+
+// reloadOptions parses args and the config file they name again, for a
+// reload on SIGHUP
+func reloadOptions(args []string) (server.Options, error) {
+	f := newStartFlags(flag.ContinueOnError)
+	if err := f.fs.Parse(args); err != nil {
+		return server.Options{}, err
+	}
+	if err := f.applyConfig(); err != nil {
+		return server.Options{}, err
+	}
+	return f.serverOptions()
 }
 
 func handleStartOrRun(command string) {
-	startCmd := flag.NewFlagSet("start", flag.ExitOnError)
-	folder := startCmd.String("dir", ".", "Directory")
-	port := startCmd.Int("port", 8080, "Port")
-	bind := startCmd.String("bind", "127.0.0.1", "IP")
-	enableLog := startCmd.Bool("log", false, "Enable HTTP request logging")
-	logDir := startCmd.String("log-dir", "", "Custom log directory (requires -log)")
-	startCmd.Parse(os.Args[2:])
-
-	if _, err := os.Stat(*folder); os.IsNotExist(err) {
-		fmt.Fprintf(os.Stderr, "Directory does not exist: %s\n", *folder)
+	f := newStartFlags(flag.ExitOnError)
+	f.fs.Parse(os.Args[2:])
+
+	// The background process reads the config file itself, so that it can
+	// reload it, and gets only the flags given on the command line
+	for _, name := range []string{"config", "pidfile"} {
+		if value := f.fs.Lookup(name).Value.String(); value != "" {
+			if abs, err := filepath.Abs(value); err == nil {
+				f.fs.Set(name, abs)
+			}
+		}
+	}
+	// Passwords would be visible in the process list, the background
+	// process gets them in its environment instead
+	serverArgs := forwardedArgs(f.fs, "dir", "port", "bind", "log", "log-dir", "daemon-log-file", "supervised", "start-timeout", "auth-basic")
+	var serverEnv []string
+	if len(f.authBasic) > 0 {
+		serverEnv = []string{authBasicEnv + "=" + strings.Join(f.authBasic, "\n")}
+	}
+
+	if err := f.applyConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts, err := f.serverOptions()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	logMaxSize, err := server.ParseSize(*f.logMaxSize)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: -log-max-size: %v\n", err)
+		os.Exit(1)
+	}
+	logFormat, err := logger.ParseFormat(*f.logFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: -log-format: %v\n", err)
+		os.Exit(1)
+	}
+	logLocation := time.Local
+	if *f.logTimezone != "" {
+		if logLocation, err = time.LoadLocation(*f.logTimezone); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -log-timezone: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
-	if command == "start" {
-		d := daemon.New(pidfile.New(), process.NewManager(), os.Args[0])
-		if err := d.Start(*folder, *port, *bind, *enableLog, *logDir); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	served := []string{opts.Folder}
+	if opts.ZipFile != "" {
+		if len(f.dirs) > 0 {
+			fmt.Fprintf(os.Stderr, "Error: -zip cannot be combined with -dir\n")
+			os.Exit(1)
+		}
+		if _, err := os.Stat(opts.ZipFile); os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "Zip file does not exist: %s\n", opts.ZipFile)
 			os.Exit(1)
 		}
+		served = nil
+	} else if len(opts.Mounts) > 0 {
+		served = served[:0]
+		for _, path := range opts.Mounts {
+			served = append(served, path)
+		}
+	}
+	for _, path := range served {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "Directory does not exist: %s\n", path)
+			os.Exit(1)
+		}
+	}
+
+	if command == "start" {
+		d := daemon.New(newPIDFile(*f.pidFile), process.NewManager(), os.Args[0])
+		var mountArgs []string
+		if len(opts.Mounts) > 0 {
+			mountArgs = f.dirs
+		}
+		dopts := daemon.Options{
+			Folder:       opts.Folder,
+			Mounts:       mountArgs,
+			Port:         opts.Port,
+			Bind:         opts.Bind,
+			EnableLog:    *f.enableLog,
+			LogDir:       *f.logDir,
+			OutputFile:   *f.daemonLogFile,
+			ServerArgs:   serverArgs,
+			Env:          serverEnv,
+			Supervised:   *f.supervised,
+			StartTimeout: *f.startTimeout,
+		}
+		if err := d.Start(dopts); err != nil {
+			exitWith(err)
+		}
 	} else {
 		var log *logger.Logger
-		var err error
-		if *enableLog {
-			log, err = logger.New(true, *logDir)
+		if *f.enableLog {
+			log, err = logger.NewWithOptions(true, *f.logDir, logger.Options{
+				MaxSize:        logMaxSize,
+				RetentionDays:  *f.logRetention,
+				RotateDaily:    *f.logDaily,
+				Location:       logLocation,
+				Syslog:         *f.logSyslog,
+				Stdout:         *f.logStdout,
+				SyslogFacility: *f.logFacility,
+			})
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
 				os.Exit(1)
 			}
+			log.SetFormat(logFormat)
+			log.SetLocation(logLocation)
+			log.StartAsync(*f.logAsync)
 			defer log.Close()
 		}
-		srv := server.New(*folder, *port, *bind, log)
-		if err := srv.Start(); err != nil {
+		args := os.Args[2:]
+		opts.Reload = func() (server.Options, error) {
+			return reloadOptions(args)
+		}
+		srv, err := server.NewWithOptions(opts, log)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		info := daemon.Info{
+			PID:     os.Getpid(),
+			Folder:  opts.Folder,
+			ZipFile: opts.ZipFile,
+			Port:    opts.Port,
+			Bind:    opts.Bind,
+			Started: time.Now(),
+		}
+		if len(opts.Mounts) > 0 {
+			info.Mounts = f.dirs
+		}
+		pf := newPIDFile(*f.pidFile)
+		if err := daemon.WriteInfo(pf, info); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		defer daemon.RemoveInfo(pf, info.PID)
+		if err := serveForeground(srv); err != nil {
 			fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
 			os.Exit(1)
 		}
@@ -97,17 +689,145 @@ func handleStartOrRun(command string) {
 }
 
 func handleStop() {
-	d := daemon.New(pidfile.New(), process.NewManager(), os.Args[0])
+	d := daemon.New(parsePIDFileFlag("stop"), process.NewManager(), os.Args[0])
 	if err := d.Stop(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		exitWith(err)
 	}
 }
 
 func handleStatus() {
-	d := daemon.New(pidfile.New(), process.NewManager(), os.Args[0])
+	d := daemon.New(parsePIDFileFlag("status"), process.NewManager(), os.Args[0])
 	if err := d.Status(); err != nil {
+		exitWith(err)
+	}
+}
+
+// exitWith exits with the code daemon.ExitCode assigns to err. Only
+// failures are reported, the daemon has already printed the state.
+func exitWith(err error) {
+	code := daemon.ExitCode(err)
+	if code == daemon.ExitError {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
 	}
+	os.Exit(code)
+}
+
+// parsePIDFileFlag parses the -pidfile flag of the stop and status commands
+func parsePIDFileFlag(command string) pidfile.File {
+	fs := flag.NewFlagSet(command, flag.ExitOnError)
+	path := fs.String("pidfile", "", "PID file of the background service")
+	fs.Parse(os.Args[2:])
+	return newPIDFile(*path)
+}
+
+// newPIDFile returns the PID file at path, or the default one when path is empty
+func newPIDFile(path string) pidfile.File {
+	if path == "" {
+		return pidfile.New()
+	}
+	return pidfile.NewWithPath(path)
+}
+
+// forwardedArgs returns the flags explicitly set on fs, except the skipped
+// ones, in a form the background run process can parse again
+func forwardedArgs(fs *flag.FlagSet, skip ...string) []string {
+	skipped := make(map[string]bool, len(skip))
+	for _, name := range skip {
+		skipped[name] = true
+	}
+
+	var args []string
+	fs.Visit(func(f *flag.Flag) {
+		if skipped[f.Name] {
+			return
+		}
+		if list, ok := f.Value.(*stringList); ok {
+			for _, v := range *list {
+				args = append(args, "-"+f.Name+"="+v)
+			}
+			return
+		}
+		args = append(args, "-"+f.Name+"="+f.Value.String())
+	})
+	return args
+}
+
+// parseDirs splits the -dir values into a single served folder or named
+// mounts. A value is a mount when the part before its first "=" is a plain
+// name; paths containing "=" can be given with a directory component such
+// as "./a=b".
+func parseDirs(values []string) (string, map[string]string, error) {
+	if len(values) == 0 {
+		return ".", nil, nil
+	}
+
+	var folders []string
+	mounts := make(map[string]string)
+	for _, v := range values {
+		name, path, ok := strings.Cut(v, "=")
+		if !ok || name == "" || strings.ContainsAny(name, `/\`) {
+			folders = append(folders, v)
+			continue
+		}
+		if _, dup := mounts[name]; dup {
+			return "", nil, fmt.Errorf("duplicate mount name: %s", name)
+		}
+		if path == "" {
+			return "", nil, fmt.Errorf("missing directory for mount: %s", name)
+		}
+		mounts[name] = path
+	}
+
+	switch {
+	case len(mounts) > 0 && len(folders) > 0:
+		return "", nil, fmt.Errorf("-dir name=path mounts cannot be combined with a plain directory")
+	case len(folders) > 1:
+		return "", nil, fmt.Errorf("only one plain -dir is allowed; use name=path to serve several directories")
+	case len(mounts) > 0:
+		return "", mounts, nil
+	}
+	return folders[0], nil, nil
+}
+
+// authBasicEnv carries the -auth-basic entries of start to the background
+// process, one per line
+const authBasicEnv = "GOWEBDAVD_AUTH_BASIC"
+
+// loadCredentials merges the -auth-basic entries with the -auth-file contents
+func loadCredentials(entries []string, file string) (server.Credentials, error) {
+	creds, err := server.ParseCredentials(entries)
+	if err != nil || file == "" {
+		return creds, err
+	}
+	fromFile, err := server.LoadCredentials(file)
+	if err != nil {
+		return nil, err
+	}
+	for user, pass := range fromFile {
+		creds[user] = pass
+	}
+	return creds, nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// stringList is a flag.Value collecting every occurrence of a repeatable flag
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
 }
@@ -0,0 +1,28 @@
+//go:build !windows
+
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gowebdavd/internal/server"
+)
+
+func handleInstallService() {
+	fmt.Fprintln(os.Stderr, "Error: install-service is only supported on Windows")
+	os.Exit(1)
+}
+
+func handleUninstallService() {
+	fmt.Fprintln(os.Stderr, "Error: uninstall-service is only supported on Windows")
+	os.Exit(1)
+}
+
+// serveForeground runs srv until it is shut down
+func serveForeground(srv *server.WebDAV) error {
+	return srv.Start()
+}
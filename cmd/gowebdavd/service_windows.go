@@ -0,0 +1,195 @@
+//go:build windows
+
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+
+	"gowebdavd/internal/server"
+)
+
+// serviceName is the name gowebdavd registers with the Service Control Manager
+const serviceName = "gowebdavd"
+
+// handleInstallService registers gowebdavd as a Windows service running
+// "run" with the flags given now. Paths are stored as absolute paths, since
+// services start in the system directory.
+func handleInstallService() {
+	f := newStartFlags(flag.ExitOnError)
+	f.fs.Parse(os.Args[2:])
+
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	args, err := serviceArgs(f)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to connect to the service manager: %v\n", err)
+		os.Exit(1)
+	}
+	defer m.Disconnect()
+
+	if s, err := m.OpenService(serviceName); err == nil {
+		s.Close()
+		fmt.Fprintf(os.Stderr, "Error: service %s already exists\n", serviceName)
+		os.Exit(1)
+	}
+	s, err := m.CreateService(serviceName, exe, mgr.Config{
+		DisplayName: "gowebdavd",
+		Description: "WebDAV server",
+		StartType:   mgr.StartAutomatic,
+	}, args...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to create service: %v\n", err)
+		os.Exit(1)
+	}
+	s.Close()
+	fmt.Printf("Service %s installed\n", serviceName)
+}
+
+// handleUninstallService removes the service registered by install-service
+func handleUninstallService() {
+	m, err := mgr.Connect()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to connect to the service manager: %v\n", err)
+		os.Exit(1)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: service %s is not installed\n", serviceName)
+		os.Exit(1)
+	}
+	defer s.Close()
+	if err := s.Delete(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to remove service: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Service %s removed\n", serviceName)
+}
+
+// serviceArgs returns the arguments the service passes to gowebdavd: the run
+// command followed by the flags set on f, with paths made absolute
+func serviceArgs(f *startFlags) ([]string, error) {
+	for _, name := range []string{"config", "zip", "log-dir", "auth-file", "tls-cert", "tls-key", "shutdown-file", "pidfile", "lock-file", "serve-error-pages"} {
+		fl := f.fs.Lookup(name)
+		if fl == nil || fl.Value.String() == "" {
+			continue
+		}
+		abs, err := filepath.Abs(fl.Value.String())
+		if err != nil {
+			return nil, err
+		}
+		f.fs.Set(name, abs)
+	}
+
+	folder, mounts, err := parseDirs(f.dirs)
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"run"}
+	if len(mounts) > 0 {
+		names := make([]string, 0, len(mounts))
+		for name := range mounts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			abs, err := filepath.Abs(mounts[name])
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, "-dir="+name+"="+abs)
+		}
+	} else {
+		abs, err := filepath.Abs(folder)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "-dir="+abs)
+	}
+	return append(args, forwardedArgs(f.fs, "dir", "daemon-log-file", "supervised")...), nil
+}
+
+// serveForeground runs srv until it is shut down. Started by the Service
+// Control Manager, it runs inside the service control loop instead.
+func serveForeground(srv *server.WebDAV) error {
+	isService, err := svc.IsWindowsService()
+	if err != nil {
+		return err
+	}
+	if !isService {
+		return srv.Start()
+	}
+	h := &service{srv: srv}
+	if err := svc.Run(serviceName, h); err != nil {
+		return err
+	}
+	return h.err
+}
+
+// service runs the server under the Service Control Manager
+type service struct {
+	srv *server.WebDAV
+	err error
+}
+
+// Execute starts the server and shuts it down on a stop or shutdown request
+func (s *service) Execute(args []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	const accepted = svc.AcceptStop | svc.AcceptShutdown
+	status <- svc.Status{State: svc.StartPending}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.srv.Start()
+	}()
+	status <- svc.Status{State: svc.Running, Accepts: accepted}
+
+	for {
+		select {
+		case err := <-done:
+			// The server stopped on its own, e.g. the port is in use
+			s.err = err
+			status <- svc.Status{State: svc.StopPending}
+			if err != nil {
+				return false, 1
+			}
+			return false, 0
+		case r := <-requests:
+			switch r.Cmd {
+			case svc.Interrogate:
+				status <- r.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				if err := s.srv.Shutdown(); err != nil {
+					s.err = err
+				}
+				if err := <-done; err != nil && s.err == nil {
+					s.err = err
+				}
+				if s.err != nil {
+					return false, 1
+				}
+				return false, 0
+			}
+		}
+	}
+}
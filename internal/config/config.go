@@ -0,0 +1,272 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+// Package config loads command line flag values from a TOML file.
+//
+// Only the subset of TOML needed for flat flag settings is understood:
+// top-level key/value pairs with string, integer, float, boolean and array
+// values. Tables are rejected, since every key mirrors a flag name.
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Values maps flag names to their values. Arrays hold one entry per element,
+// every other value a single entry.
+type Values map[string][]string
+
+// Load reads and parses the TOML file at path
+func Load(path string) (Values, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	values, err := Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// Parse parses TOML key/value pairs from data
+func Parse(data string) (Values, error) {
+	p := &parser{data: data, line: 1}
+	values := make(Values)
+	for {
+		p.skipSpace(true)
+		if p.eof() {
+			return values, nil
+		}
+		if p.peek() == '[' {
+			return nil, p.errorf("tables are not supported")
+		}
+
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := values[key]; dup {
+			return nil, p.errorf("duplicate key %q", key)
+		}
+		p.skipSpace(false)
+		if p.eof() || p.peek() != '=' {
+			return nil, p.errorf("expected = after key %q", key)
+		}
+		p.pos++
+		p.skipSpace(false)
+
+		var value []string
+		if !p.eof() && p.peek() == '[' {
+			value, err = p.array()
+		} else {
+			var v string
+			v, err = p.scalar()
+			value = []string{v}
+		}
+		if err != nil {
+			return nil, err
+		}
+		values[key] = value
+
+		p.skipSpace(false)
+		if !p.eof() && p.peek() != '\n' {
+			return nil, p.errorf("unexpected %q after value of %q", p.peek(), key)
+		}
+	}
+}
+
+// Apply sets every value on fs, except for flags already set on the command
+// line, so that explicit flags override the file. The values become visible
+// to fs.Visit like flags given on the command line.
+func Apply(fs *flag.FlagSet, values Values) error {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if fs.Lookup(key) == nil {
+			return fmt.Errorf("unknown config key %q", key)
+		}
+		if set[key] {
+			continue
+		}
+		for _, v := range values[key] {
+			if err := fs.Set(key, v); err != nil {
+				return fmt.Errorf("invalid value %q for config key %q: %w", v, key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// parser walks the file content keeping track of the line for errors
+type parser struct {
+	data string
+	pos  int
+	line int
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.data)
+}
+
+func (p *parser) peek() byte {
+	return p.data[p.pos]
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+// skipSpace skips blanks and comments, and newlines when newlines is set
+func (p *parser) skipSpace(newlines bool) {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '\n' && newlines:
+			p.pos++
+			p.line++
+		case c == '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// key parses a bare or quoted key
+func (p *parser) key() (string, error) {
+	if c := p.peek(); c == '"' || c == '\'' {
+		return p.str()
+	}
+	start := p.pos
+	for !p.eof() && isBareKeyChar(p.peek()) {
+		p.pos++
+	}
+	if start == p.pos {
+		return "", p.errorf("expected a key, found %q", p.peek())
+	}
+	return p.data[start:p.pos], nil
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_'
+}
+
+// array parses an array of scalars, which may span several lines
+func (p *parser) array() ([]string, error) {
+	p.pos++ // [
+	items := []string{}
+	for {
+		p.skipSpace(true)
+		if p.eof() {
+			return nil, p.errorf("unterminated array")
+		}
+		if p.peek() == ']' {
+			p.pos++
+			return items, nil
+		}
+		v, err := p.scalar()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+
+		p.skipSpace(true)
+		if p.eof() {
+			return nil, p.errorf("unterminated array")
+		}
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return nil, p.errorf("expected , or ] in array, found %q", p.peek())
+		}
+	}
+}
+
+// scalar parses a string, number or boolean, returning it in the form the
+// corresponding flag accepts
+func (p *parser) scalar() (string, error) {
+	if p.eof() || p.peek() == '\n' {
+		return "", p.errorf("missing value")
+	}
+	if c := p.peek(); c == '"' || c == '\'' {
+		return p.str()
+	}
+
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(" \t\r\n#,]", rune(p.peek())) {
+		p.pos++
+	}
+	token := p.data[start:p.pos]
+	switch {
+	case token == "true" || token == "false":
+		return token, nil
+	case token == "":
+		return "", p.errorf("unexpected %q", p.peek())
+	}
+	number := strings.ReplaceAll(token, "_", "")
+	if _, err := strconv.ParseInt(number, 0, 64); err == nil {
+		return number, nil
+	}
+	if _, err := strconv.ParseFloat(number, 64); err == nil {
+		return number, nil
+	}
+	return "", p.errorf("invalid value %q (strings must be quoted)", token)
+}
+
+// str parses a basic "..." string with escapes or a literal '...' string
+func (p *parser) str() (string, error) {
+	quote := p.peek()
+	p.pos++
+	var b strings.Builder
+	for !p.eof() {
+		c := p.peek()
+		p.pos++
+		switch {
+		case c == quote:
+			return b.String(), nil
+		case c == '\n':
+			return "", p.errorf("unterminated string")
+		case c == '\\' && quote == '"':
+			if p.eof() {
+				return "", p.errorf("unterminated string")
+			}
+			e := p.peek()
+			p.pos++
+			switch e {
+			case '\\', '"':
+				b.WriteByte(e)
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			default:
+				return "", p.errorf("invalid escape \\%c", e)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
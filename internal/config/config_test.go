@@ -0,0 +1,144 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	data := `# gowebdavd settings
+dir = "/srv/webdav"   # served folder
+port = 9090
+bind = '127.0.0.1'
+log = true
+rate-limit = 1_000
+"log-dir" = "C:\\logs"
+auth-basic = [
+  "alice:secret", # first user
+  "bob:hunter2",
+]
+allow = []
+`
+	values, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := Values{
+		"dir":        {"/srv/webdav"},
+		"port":       {"9090"},
+		"bind":       {"127.0.0.1"},
+		"log":        {"true"},
+		"rate-limit": {"1000"},
+		"log-dir":    {`C:\logs`},
+		"auth-basic": {"alice:secret", "bob:hunter2"},
+		"allow":      {},
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("Parse() = %v, want %v", values, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "unquoted string", data: "dir = /srv", want: "line 1"},
+		{name: "missing equals", data: "\nport 8080", want: "line 2"},
+		{name: "missing value", data: "port =\n", want: "missing value"},
+		{name: "duplicate key", data: "port = 1\nport = 2", want: "duplicate"},
+		{name: "table", data: "[server]\nport = 1", want: "tables"},
+		{name: "unterminated string", data: `dir = "/srv`, want: "unterminated"},
+		{name: "unterminated array", data: `dir = ["a",`, want: "unterminated"},
+		{name: "trailing garbage", data: "port = 1 2", want: "unexpected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gowebdavd.toml")
+	if err := os.WriteFile(path, []byte("port = 9090\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	values, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := values["port"]; len(got) != 1 || got[0] != "9090" {
+		t.Errorf("port = %v, want [9090]", got)
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.toml")); err == nil {
+		t.Error("Load() should fail for a missing file")
+	}
+}
+
+// repeatable collects every value it is set to
+type repeatable []string
+
+func (r *repeatable) String() string     { return strings.Join(*r, ",") }
+func (r *repeatable) Set(v string) error { *r = append(*r, v); return nil }
+
+func TestApply(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	port := fs.Int("port", 8080, "")
+	bind := fs.String("bind", "127.0.0.1", "")
+	enableLog := fs.Bool("log", false, "")
+	var dirs repeatable
+	fs.Var(&dirs, "dir", "")
+	if err := fs.Parse([]string{"-port", "7070"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	err := Apply(fs, Values{
+		"port": {"9090"},
+		"bind": {"0.0.0.0"},
+		"log":  {"true"},
+		"dir":  {"a=/srv/a", "b=/srv/b"},
+	})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	if *port != 7070 {
+		t.Errorf("port = %d, want command line value 7070", *port)
+	}
+	if *bind != "0.0.0.0" || !*enableLog {
+		t.Errorf("bind = %q, log = %v, want file values", *bind, *enableLog)
+	}
+	if !reflect.DeepEqual([]string(dirs), []string{"a=/srv/a", "b=/srv/b"}) {
+		t.Errorf("dir = %v, want both mounts", dirs)
+	}
+
+	var visited []string
+	fs.Visit(func(f *flag.Flag) { visited = append(visited, f.Name) })
+	if len(visited) != 4 {
+		t.Errorf("Visit() saw %v, want every applied flag", visited)
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Int("port", 8080, "")
+
+	if err := Apply(fs, Values{"prot": {"1"}}); err == nil || !strings.Contains(err.Error(), "unknown") {
+		t.Errorf("Apply() error = %v, want unknown key", err)
+	}
+	if err := Apply(fs, Values{"port": {"abc"}}); err == nil || !strings.Contains(err.Error(), "port") {
+		t.Errorf("Apply() error = %v, want invalid port", err)
+	}
+}
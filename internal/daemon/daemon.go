@@ -0,0 +1,241 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+// Package daemon provides daemon management functionality.
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"syscall"
+	"time"
+
+	"gowebdavd/internal/pidfile"
+	"gowebdavd/internal/process"
+)
+
+// Daemon manages the WebDAV background service
+type Daemon struct {
+	pidFile  pidfile.File
+	procMgr  process.Manager
+	execPath string
+}
+
+// Options configures the background service started by Start
+type Options struct {
+	Folder string
+	// Mounts are name=path entries served instead of Folder when not empty
+	Mounts    []string
+	Port      int
+	Bind      string
+	EnableLog bool
+	LogDir    string
+	// OutputFile receives the child's stdout and stderr. Output is discarded when empty.
+	OutputFile string
+	// ServerArgs are additional flags passed through to the run process
+	ServerArgs []string
+	// Env holds KEY=value entries added to the run process environment, for
+	// values that must not show up in its command line
+	Env []string
+	// Supervised keeps Start in the foreground until the child exits, and has
+	// the child terminated if the launcher dies, instead of detaching it
+	Supervised bool
+	// StartTimeout is how long Start waits for a detached service to answer
+	// its /livez endpoint before stopping it and failing, 0 does not wait
+	StartTimeout time.Duration
+}
+
+// New creates a new Daemon instance
+func New(pf pidfile.File, pm process.Manager, execPath string) *Daemon {
+	return &Daemon{
+		pidFile:  pf,
+		procMgr:  pm,
+		execPath: execPath,
+	}
+}
+
+// Start starts the WebDAV service in background. The PID file is locked from
+// the check for a running service until the new PID is written, so that
+// concurrent starts cannot both spawn a server; a start that finds the lock
+// held fails with pidfile.ErrLocked, and one that finds the service running
+// with ErrAlreadyRunning. With StartTimeout, a detached service that exits
+// or does not answer in time is stopped and its PID file removed.
+func (d *Daemon) Start(opts Options) error {
+	if err := d.pidFile.Lock(); err != nil {
+		if errors.Is(err, pidfile.ErrLocked) {
+			return fmt.Errorf("another start is in progress: %w", err)
+		}
+		return err
+	}
+	locked := true
+	unlock := func() {
+		if locked {
+			d.pidFile.Unlock()
+			locked = false
+		}
+	}
+	defer unlock()
+
+	pid, err := d.pidFile.Read()
+	if err == nil && d.procMgr.IsRunning(pid) {
+		fmt.Printf("Service is already running (PID: %d)\n", pid)
+		return ErrAlreadyRunning
+	}
+
+	if err == nil {
+		d.pidFile.Remove()
+	}
+
+	attr, err := opts.procAttr()
+	if err != nil {
+		return err
+	}
+	wait := !opts.Supervised && opts.StartTimeout > 0 && opts.Port > 0
+	if wait {
+		if err := checkAddrFree(opts.Bind, opts.Port); err != nil {
+			return err
+		}
+	}
+	cmd := exec.Command(d.execPath, opts.args()...)
+	cmd.SysProcAttr = attr
+	if len(opts.Env) > 0 {
+		cmd.Env = append(os.Environ(), opts.Env...)
+	}
+
+	if opts.Supervised {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		// The parent-death signal fires when the thread that started the
+		// child exits, so keep this goroutine on it until the child is gone
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+	}
+	if opts.OutputFile != "" {
+		out, err := os.OpenFile(opts.OutputFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open daemon log file: %w", err)
+		}
+		// The child inherits its own copy of the descriptor
+		defer out.Close()
+		cmd.Stdout = out
+		cmd.Stderr = out
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start service: %w", err)
+	}
+
+	if err := d.pidFile.Write(cmd.Process.Pid); err != nil {
+		cmd.Process.Kill()
+		return fmt.Errorf("failed to write PID: %w", err)
+	}
+
+	if wait {
+		exited := make(chan error, 1)
+		go func() { exited <- cmd.Wait() }()
+		if err := waitForService(livezURL(opts.Bind, opts.Port), exited, opts.StartTimeout); err != nil {
+			cmd.Process.Kill()
+			d.pidFile.Remove()
+			RemoveInfo(d.pidFile, cmd.Process.Pid)
+			if opts.OutputFile != "" {
+				return fmt.Errorf("%w, see %s", err, opts.OutputFile)
+			}
+			return fmt.Errorf("%w, run it in the foreground with the run command to see why", err)
+		}
+	}
+
+	fmt.Printf("Service started (PID: %d)\n", cmd.Process.Pid)
+	if !opts.Supervised {
+		return nil
+	}
+	unlock()
+
+	err = cmd.Wait()
+	d.pidFile.Remove()
+	if err != nil {
+		return fmt.Errorf("service exited: %w", err)
+	}
+	return nil
+}
+
+// Stop stops the WebDAV service. It returns ErrNotRunning when there is
+// none.
+func (d *Daemon) Stop() error {
+	pid, err := d.pidFile.Read()
+	if err != nil {
+		fmt.Println("Service is not running")
+		return ErrNotRunning
+	}
+
+	if !d.procMgr.IsRunning(pid) {
+		d.pidFile.Remove()
+		fmt.Println("Service is not running")
+		return ErrNotRunning
+	}
+
+	if err := d.procMgr.Terminate(pid); err != nil {
+		if err := d.procMgr.Kill(pid); err != nil {
+			return fmt.Errorf("failed to stop service: %w", err)
+		}
+	}
+
+	d.pidFile.Remove()
+	RemoveInfo(d.pidFile, pid)
+	fmt.Println("Service stopped")
+	return nil
+}
+
+// Status checks the service status. It returns ErrNotRunning when there is
+// no running service.
+func (d *Daemon) Status() error {
+	pid, err := d.pidFile.Read()
+	if err != nil {
+		fmt.Println("Service is not running")
+		return ErrNotRunning
+	}
+
+	if d.procMgr.IsRunning(pid) {
+		fmt.Printf("Service is running (PID: %d)\n", pid)
+		// Instances started before the sidecar existed only report their PID
+		if info, err := readInfo(d.pidFile); err == nil && info.PID == pid {
+			fmt.Print(info.describe(time.Now()))
+		}
+	} else {
+		fmt.Printf("PID file exists but process %d not found\n", pid)
+		d.pidFile.Remove()
+		RemoveInfo(d.pidFile, pid)
+		return ErrNotRunning
+	}
+	return nil
+}
+
+// procAttr returns the process attributes for the mode selected by o
+func (o Options) procAttr() (*syscall.SysProcAttr, error) {
+	if o.Supervised {
+		return supervisedProcAttr()
+	}
+	return sysProcAttr(), nil
+}
+
+// args builds the command line for the foreground run process
+func (o Options) args() []string {
+	args := []string{"run"}
+	if len(o.Mounts) == 0 {
+		args = append(args, "-dir", o.Folder)
+	}
+	for _, m := range o.Mounts {
+		args = append(args, "-dir", m)
+	}
+	args = append(args, "-port", strconv.Itoa(o.Port), "-bind", o.Bind)
+	if o.EnableLog {
+		args = append(args, "-log")
+		if o.LogDir != "" {
+			args = append(args, "-log-dir", o.LogDir)
+		}
+	}
+	return append(args, o.ServerArgs...)
+}
@@ -0,0 +1,16 @@
+//go:build linux
+
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package daemon
+
+import "syscall"
+
+// supervisedProcAttr keeps the child in the launcher's session and has the
+// kernel send it SIGTERM when the launcher dies
+func supervisedProcAttr() (*syscall.SysProcAttr, error) {
+	return &syscall.SysProcAttr{
+		Pdeathsig: syscall.SIGTERM,
+	}, nil
+}
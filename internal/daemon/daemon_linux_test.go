@@ -0,0 +1,49 @@
+package daemon
+
+import (
+	"os"
+	"syscall"
+	"testing"
+
+	"gowebdavd/internal/process"
+)
+
+func TestProcAttrSupervised(t *testing.T) {
+	attr, err := Options{Supervised: true}.procAttr()
+	if err != nil {
+		t.Fatalf("procAttr() error = %v", err)
+	}
+	if attr.Pdeathsig != syscall.SIGTERM {
+		t.Errorf("Pdeathsig = %v, want %v", attr.Pdeathsig, syscall.SIGTERM)
+	}
+	if attr.Setsid {
+		t.Error("supervised child should not be detached into its own session")
+	}
+
+	attr, err = Options{}.procAttr()
+	if err != nil {
+		t.Fatalf("procAttr() error = %v", err)
+	}
+	if attr.Pdeathsig != 0 || !attr.Setsid {
+		t.Errorf("detached child attributes = %+v, want Setsid without Pdeathsig", attr)
+	}
+}
+
+func TestStartSupervisedWaitsForChild(t *testing.T) {
+	tmpDir := t.TempDir()
+	execPath := createStderrExecutable(t, tmpDir, "bind failed")
+
+	pf := &MockPIDFile{ReadErr: os.ErrNotExist}
+	d := New(pf, &process.MockManager{}, execPath)
+
+	err := d.Start(Options{Folder: tmpDir, Port: 18080, Bind: "127.0.0.1", Supervised: true})
+	if err == nil {
+		t.Error("Start() should report the child's failing exit")
+	}
+	if pf.Written == 0 {
+		t.Error("Start() should write the child's PID")
+	}
+	if !pf.Removed {
+		t.Error("Start() should remove the PID file once the child exits")
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !linux
+
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package daemon
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// supervisedProcAttr is unavailable: only Linux can signal a child when its
+// parent dies
+func supervisedProcAttr() (*syscall.SysProcAttr, error) {
+	return nil, fmt.Errorf("supervised mode is only supported on Linux")
+}
@@ -5,8 +5,11 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
+	"time"
 
+	"gowebdavd/internal/pidfile"
 	"gowebdavd/internal/process"
 )
 
@@ -15,18 +18,27 @@ func createTestExecutable(t *testing.T, dir string) string {
 	t.Helper()
 
 	if runtime.GOOS == "windows" {
-		execPath := filepath.Join(dir, "testexec.bat")
 		// Create a batch file that exits immediately
-		content := []byte("@echo off\nexit /b 0")
-		if err := os.WriteFile(execPath, content, 0755); err != nil {
-			t.Fatalf("Failed to create test executable: %v", err)
-		}
-		return execPath
+		return writeTestScript(t, dir, "testexec.bat", "@echo off\nexit /b 0")
+	}
+	return writeTestScript(t, dir, "testexec", "#!/bin/sh\nexit 0")
+}
+
+// createStderrExecutable creates a test executable that writes msg to stderr
+func createStderrExecutable(t *testing.T, dir, msg string) string {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		return writeTestScript(t, dir, "stderrexec.bat", "@echo off\necho "+msg+" 1>&2\nexit /b 1")
 	}
+	return writeTestScript(t, dir, "stderrexec", "#!/bin/sh\necho "+msg+" >&2\nexit 1")
+}
+
+func writeTestScript(t *testing.T, dir, name, content string) string {
+	t.Helper()
 
-	execPath := filepath.Join(dir, "testexec")
-	content := []byte("#!/bin/sh\nexit 0")
-	if err := os.WriteFile(execPath, content, 0755); err != nil {
+	execPath := filepath.Join(dir, name)
+	if err := os.WriteFile(execPath, []byte(content), 0755); err != nil {
 		t.Fatalf("Failed to create test executable: %v", err)
 	}
 	return execPath
@@ -39,8 +51,10 @@ type MockPIDFile struct {
 	WriteErr  error
 	RemoveErr error
 	PathValue string
+	LockErr   error
 	Removed   bool
 	Written   int
+	Locked    bool
 }
 
 func (m *MockPIDFile) Read() (int, error) {
@@ -64,6 +78,19 @@ func (m *MockPIDFile) Remove() error {
 	return m.RemoveErr
 }
 
+func (m *MockPIDFile) Lock() error {
+	if m.LockErr != nil {
+		return m.LockErr
+	}
+	m.Locked = true
+	return nil
+}
+
+func (m *MockPIDFile) Unlock() error {
+	m.Locked = false
+	return nil
+}
+
 func (m *MockPIDFile) Path() string {
 	if m.PathValue != "" {
 		return m.PathValue
@@ -77,8 +104,8 @@ func TestStatusNotRunning(t *testing.T) {
 	d := New(pf, pm, "/bin/test")
 
 	err := d.Status()
-	if err != nil {
-		t.Errorf("Status() error = %v", err)
+	if !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Status() error = %v, want %v", err, ErrNotRunning)
 	}
 }
 
@@ -103,8 +130,8 @@ func TestStatusStalePID(t *testing.T) {
 	d := New(pf, pm, "/bin/test")
 
 	err := d.Status()
-	if err != nil {
-		t.Errorf("Status() error = %v", err)
+	if !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Status() error = %v, want %v", err, ErrNotRunning)
 	}
 	if !pf.Removed {
 		t.Error("Status() should remove stale PID file")
@@ -117,8 +144,8 @@ func TestStopNotRunning(t *testing.T) {
 	d := New(pf, pm, "/bin/test")
 
 	err := d.Stop()
-	if err != nil {
-		t.Errorf("Stop() error = %v", err)
+	if !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Stop() error = %v, want %v", err, ErrNotRunning)
 	}
 }
 
@@ -130,8 +157,8 @@ func TestStopStalePID(t *testing.T) {
 	d := New(pf, pm, "/bin/test")
 
 	err := d.Stop()
-	if err != nil {
-		t.Errorf("Stop() error = %v", err)
+	if !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Stop() error = %v, want %v", err, ErrNotRunning)
 	}
 	if !pf.Removed {
 		t.Error("Stop() should remove stale PID file")
@@ -178,7 +205,7 @@ func TestStartNew(t *testing.T) {
 
 	// This will fail because our test script is not a valid Go binary
 	// but we can at least verify the logic before exec.Command
-	err := d.Start(tmpDir, 18080, "127.0.0.1", false, "")
+	err := d.Start(Options{Folder: tmpDir, Port: 18080, Bind: "127.0.0.1"})
 	// We expect an error because the test script isn't a valid server
 	// but the PID file operations should be attempted
 	_ = err
@@ -191,9 +218,9 @@ func TestStartAlreadyRunning(t *testing.T) {
 	}
 	d := New(pf, pm, "/bin/test")
 
-	err := d.Start("/tmp", 8080, "127.0.0.1", false, "")
-	if err != nil {
-		t.Errorf("Start() error = %v", err)
+	err := d.Start(Options{Folder: "/tmp", Port: 8080, Bind: "127.0.0.1"})
+	if !errors.Is(err, ErrAlreadyRunning) {
+		t.Errorf("Start() error = %v, want %v", err, ErrAlreadyRunning)
 	}
 	// Should not start a new process
 	if pf.Written != 0 {
@@ -201,6 +228,52 @@ func TestStartAlreadyRunning(t *testing.T) {
 	}
 }
 
+func TestStartLockHeld(t *testing.T) {
+	tmpDir := t.TempDir()
+	execPath := createTestExecutable(t, tmpDir)
+	path := filepath.Join(tmpDir, "gowebdavd.pid")
+
+	// Another start holding the lock, between its check and its write
+	other := pidfile.NewWithPath(path)
+	if err := other.Lock(); err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+
+	d := New(pidfile.NewWithPath(path), &process.MockManager{}, execPath)
+	err := d.Start(Options{Folder: tmpDir, Port: 18080, Bind: "127.0.0.1"})
+	if !errors.Is(err, pidfile.ErrLocked) {
+		t.Errorf("Start() error = %v, want %v", err, pidfile.ErrLocked)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Start() should not write the PID file while the lock is held")
+	}
+
+	other.Unlock()
+	if err := d.Start(Options{Folder: tmpDir, Port: 18080, Bind: "127.0.0.1"}); err != nil {
+		t.Fatalf("Start() after Unlock error = %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Start() should write the PID file once the lock is free: %v", err)
+	}
+	// Start releases its own lock
+	if err := other.Lock(); err != nil {
+		t.Errorf("Lock() after Start error = %v", err)
+	}
+	other.Unlock()
+}
+
+func TestStartLockError(t *testing.T) {
+	pf := &MockPIDFile{ReadErr: os.ErrNotExist, LockErr: errors.New("read-only file system")}
+	d := New(pf, &process.MockManager{}, "/bin/test")
+
+	if err := d.Start(Options{Folder: "/tmp", Port: 8080, Bind: "127.0.0.1"}); err == nil {
+		t.Error("Start() should fail when the PID file cannot be locked")
+	}
+	if pf.Written != 0 {
+		t.Error("Start() should not write a PID without the lock")
+	}
+}
+
 func TestStartRemovesStalePID(t *testing.T) {
 	tmpDir := t.TempDir()
 	execPath := createTestExecutable(t, tmpDir)
@@ -211,7 +284,7 @@ func TestStartRemovesStalePID(t *testing.T) {
 	}
 	d := New(pf, pm, execPath)
 
-	err := d.Start(tmpDir, 18080, "127.0.0.1", false, "")
+	err := d.Start(Options{Folder: tmpDir, Port: 18080, Bind: "127.0.0.1"})
 	_ = err
 
 	if !pf.Removed {
@@ -228,7 +301,7 @@ func TestStartWithLogging(t *testing.T) {
 	d := New(pf, pm, execPath)
 
 	// Test starting with logging enabled
-	err := d.Start(tmpDir, 18080, "127.0.0.1", true, "")
+	err := d.Start(Options{Folder: tmpDir, Port: 18080, Bind: "127.0.0.1", EnableLog: true})
 	// We expect an error because the test script isn't a valid server
 	// but we can at least verify the logic before exec.Command
 	_ = err
@@ -244,8 +317,105 @@ func TestStartWithLoggingAndCustomDir(t *testing.T) {
 	d := New(pf, pm, execPath)
 
 	// Test starting with logging enabled and custom log directory
-	err := d.Start(tmpDir, 18080, "127.0.0.1", true, customLogDir)
+	err := d.Start(Options{Folder: tmpDir, Port: 18080, Bind: "127.0.0.1", EnableLog: true, LogDir: customLogDir})
 	// We expect an error because the test script isn't a valid server
 	// but we can at least verify the logic before exec.Command
 	_ = err
 }
+
+func TestStartCapturesChildOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	execPath := createStderrExecutable(t, tmpDir, "bind-failed")
+	outFile := filepath.Join(tmpDir, "daemon.log")
+
+	pf := &MockPIDFile{ReadErr: os.ErrNotExist}
+	pm := &process.MockManager{}
+	d := New(pf, pm, execPath)
+
+	err := d.Start(Options{Folder: tmpDir, Port: 18080, Bind: "127.0.0.1", OutputFile: outFile})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	// The child runs detached, so poll until its output lands in the file
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, _ := os.ReadFile(outFile)
+		if strings.Contains(string(data), "bind-failed") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("daemon log file = %q, want child stderr output", data)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestStartPassesEnv(t *testing.T) {
+	tmpDir := t.TempDir()
+	script := writeTestScript(t, tmpDir, "envexec", "#!/bin/sh\necho \"$GOWEBDAVD_TEST_ENV\"\n")
+	if runtime.GOOS == "windows" {
+		script = writeTestScript(t, tmpDir, "envexec.bat", "@echo off\necho %GOWEBDAVD_TEST_ENV%")
+	}
+	outFile := filepath.Join(tmpDir, "daemon.log")
+
+	d := New(&MockPIDFile{ReadErr: os.ErrNotExist}, &process.MockManager{}, script)
+	err := d.Start(Options{Folder: tmpDir, Port: 18080, Bind: "127.0.0.1", OutputFile: outFile, Env: []string{"GOWEBDAVD_TEST_ENV=from-start"}})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, _ := os.ReadFile(outFile)
+		if strings.Contains(string(data), "from-start") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("daemon log file = %q, want the variable from Env", data)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestStartOutputFileError(t *testing.T) {
+	tmpDir := t.TempDir()
+	execPath := createTestExecutable(t, tmpDir)
+
+	pf := &MockPIDFile{ReadErr: os.ErrNotExist}
+	pm := &process.MockManager{}
+	d := New(pf, pm, execPath)
+
+	outFile := filepath.Join(tmpDir, "missing", "daemon.log")
+	err := d.Start(Options{Folder: tmpDir, Port: 18080, Bind: "127.0.0.1", OutputFile: outFile})
+	if err == nil {
+		t.Fatal("Start() should fail when the daemon log file cannot be opened")
+	}
+	if pf.Written != 0 {
+		t.Error("Start() should not write PID when the daemon log file cannot be opened")
+	}
+}
+
+func TestOptionsArgs(t *testing.T) {
+	opts := Options{
+		Folder:     "/srv",
+		Port:       9090,
+		Bind:       "0.0.0.0",
+		EnableLog:  true,
+		LogDir:     "/var/log",
+		ServerArgs: []string{"-single-instance-lock=true"},
+	}
+
+	got := strings.Join(opts.args(), " ")
+	want := "run -dir /srv -port 9090 -bind 0.0.0.0 -log -log-dir /var/log -single-instance-lock=true"
+	if got != want {
+		t.Errorf("args() = %q, want %q", got, want)
+	}
+
+	opts = Options{Port: 8080, Bind: "127.0.0.1", Mounts: []string{"photos=/srv/photos", "docs=/srv/docs"}}
+	got = strings.Join(opts.args(), " ")
+	want = "run -dir photos=/srv/photos -dir docs=/srv/docs -port 8080 -bind 127.0.0.1"
+	if got != want {
+		t.Errorf("args() with mounts = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package daemon
+
+import "errors"
+
+// Exit codes of the gowebdavd commands, so that scripts can tell the
+// outcomes apart. ExitNotRunning matches the LSB status code for a stopped
+// service.
+const (
+	// ExitOK reports success: the service started, stopped or is running
+	ExitOK = 0
+	// ExitError reports any other failure
+	ExitError = 1
+	// ExitUsage reports invalid command line flags
+	ExitUsage = 2
+	// ExitNotRunning reports that stop or status found no running service
+	ExitNotRunning = 3
+	// ExitAlreadyRunning reports that start found the service running
+	ExitAlreadyRunning = 4
+)
+
+var (
+	// ErrAlreadyRunning is returned by Start when the service is running
+	ErrAlreadyRunning = errors.New("service is already running")
+	// ErrNotRunning is returned by Stop and Status when no service is running
+	ErrNotRunning = errors.New("service is not running")
+)
+
+// ExitCode returns the exit code of a command that ended with err
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, ErrNotRunning):
+		return ExitNotRunning
+	case errors.Is(err, ErrAlreadyRunning):
+		return ExitAlreadyRunning
+	}
+	return ExitError
+}
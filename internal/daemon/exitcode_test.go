@@ -0,0 +1,71 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"gowebdavd/internal/process"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "success", err: nil, want: ExitOK},
+		{name: "not running", err: ErrNotRunning, want: ExitNotRunning},
+		{name: "already running", err: ErrAlreadyRunning, want: ExitAlreadyRunning},
+		{name: "wrapped", err: fmt.Errorf("stop: %w", ErrNotRunning), want: ExitNotRunning},
+		{name: "other error", err: errors.New("permission denied"), want: ExitError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCommandExitCodes runs each command in the state it reports on and
+// checks the exit code its result maps to
+func TestCommandExitCodes(t *testing.T) {
+	running := func() *process.MockManager {
+		return &process.MockManager{RunningPids: map[int]bool{1234: true}}
+	}
+	tests := []struct {
+		name string
+		run  func(d *Daemon) error
+		pf   *MockPIDFile
+		pm   *process.MockManager
+		want int
+	}{
+		{name: "status running", run: (*Daemon).Status, pf: &MockPIDFile{Pid: 1234}, pm: running(), want: ExitOK},
+		{name: "status not running", run: (*Daemon).Status, pf: &MockPIDFile{ReadErr: os.ErrNotExist}, pm: &process.MockManager{}, want: ExitNotRunning},
+		{name: "status stale PID", run: (*Daemon).Status, pf: &MockPIDFile{Pid: 1234}, pm: &process.MockManager{}, want: ExitNotRunning},
+		{name: "stop running", run: (*Daemon).Stop, pf: &MockPIDFile{Pid: 1234}, pm: running(), want: ExitOK},
+		{name: "stop not running", run: (*Daemon).Stop, pf: &MockPIDFile{ReadErr: os.ErrNotExist}, pm: &process.MockManager{}, want: ExitNotRunning},
+		{name: "stop fails", run: (*Daemon).Stop, pf: &MockPIDFile{Pid: 1234}, pm: &process.MockManager{
+			RunningPids:  map[int]bool{1234: true},
+			TerminateErr: errors.New("terminate failed"),
+			KillErr:      errors.New("kill failed"),
+		}, want: ExitError},
+		{name: "start already running", run: func(d *Daemon) error {
+			return d.Start(Options{Folder: "/tmp", Port: 8080, Bind: "127.0.0.1"})
+		}, pf: &MockPIDFile{Pid: 1234}, pm: running(), want: ExitAlreadyRunning},
+		{name: "start locked", run: func(d *Daemon) error {
+			return d.Start(Options{Folder: "/tmp", Port: 8080, Bind: "127.0.0.1"})
+		}, pf: &MockPIDFile{LockErr: errors.New("lock failed")}, pm: &process.MockManager{}, want: ExitError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(tt.pf, tt.pm, "/bin/test")
+			if got := ExitCode(tt.run(d)); got != tt.want {
+				t.Errorf("exit code = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package daemon
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gowebdavd/internal/server"
+)
+
+// DefaultStartTimeout is how long Start waits for a new service to answer
+const DefaultStartTimeout = 10 * time.Second
+
+// healthPollInterval is how often waitForService probes the service
+const healthPollInterval = 100 * time.Millisecond
+
+// checkAddrFree fails when the service could not listen on bind and port,
+// e.g. because another socket is bound there or the port is privileged.
+// Start checks before spawning, as the /livez endpoint of a server already
+// there would otherwise answer for the new one.
+func checkAddrFree(bind string, port int) error {
+	addr := net.JoinHostPort(bind, strconv.Itoa(port))
+	listener, err := net.Listen("tcp", addr)
+	if server.IsAddrInUse(err) {
+		return fmt.Errorf("%s is already in use, is another server running on this port?", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot listen on %s: %w", addr, err)
+	}
+	return listener.Close()
+}
+
+// livezURL returns the URL of the /livez endpoint of a service listening
+// on bind and port. Wildcard addresses are probed on the loopback interface.
+// Unlike /health, /livez answers 200 whatever -health-status is set to.
+func livezURL(bind string, port int) string {
+	switch bind {
+	case "", "0.0.0.0":
+		bind = "127.0.0.1"
+	case "::":
+		bind = "::1"
+	}
+	return "http://" + net.JoinHostPort(bind, strconv.Itoa(port)) + "/livez"
+}
+
+// waitForService polls url until the service answers, the process reports
+// its exit on exited, or timeout expires. Any answer other than 503 counts:
+// a server rejecting the probe with 403 or 400 for plain HTTP to an HTTPS
+// port is up all the same.
+func waitForService(url string, exited <-chan error, timeout time.Duration) error {
+	client := &http.Client{Timeout: time.Second}
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	tick := time.NewTicker(healthPollInterval)
+	defer tick.Stop()
+
+	for {
+		resp, err := client.Get(url)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusServiceUnavailable {
+				return nil
+			}
+		}
+		select {
+		case err := <-exited:
+			if err == nil {
+				return fmt.Errorf("service exited during startup")
+			}
+			return fmt.Errorf("service exited during startup: %w", err)
+		case <-deadline.C:
+			return fmt.Errorf("service did not answer %s within %s", url, timeout)
+		case <-tick.C:
+		}
+	}
+}
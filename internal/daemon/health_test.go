@@ -0,0 +1,135 @@
+package daemon
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gowebdavd/internal/process"
+)
+
+func TestLivezURL(t *testing.T) {
+	tests := []struct {
+		bind string
+		want string
+	}{
+		{"127.0.0.1", "http://127.0.0.1:8080/livez"},
+		{"0.0.0.0", "http://127.0.0.1:8080/livez"},
+		{"", "http://127.0.0.1:8080/livez"},
+		{"::", "http://[::1]:8080/livez"},
+		{"192.0.2.1", "http://192.0.2.1:8080/livez"},
+	}
+	for _, tt := range tests {
+		if got := livezURL(tt.bind, 8080); got != tt.want {
+			t.Errorf("livezURL(%q) = %q, want %q", tt.bind, got, tt.want)
+		}
+	}
+}
+
+func TestWaitForServiceReady(t *testing.T) {
+	var probes int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probes++
+		if probes < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	if err := waitForService(srv.URL+"/livez", nil, 5*time.Second); err != nil {
+		t.Fatalf("waitForService() error = %v", err)
+	}
+	if probes != 3 {
+		t.Errorf("probes = %d, want 3", probes)
+	}
+}
+
+func TestWaitForServiceExited(t *testing.T) {
+	exited := make(chan error, 1)
+	exited <- errors.New("exit status 1")
+
+	err := waitForService(closedURL(t), exited, 5*time.Second)
+	if err == nil || !strings.Contains(err.Error(), "exit status 1") {
+		t.Errorf("waitForService() error = %v, want the exit status", err)
+	}
+}
+
+func TestWaitForServiceTimeout(t *testing.T) {
+	if err := waitForService(closedURL(t), nil, 200*time.Millisecond); err == nil {
+		t.Error("waitForService() should fail when nothing answers")
+	}
+}
+
+func TestStartNeverReady(t *testing.T) {
+	tmpDir := t.TempDir()
+	execPath := createStderrExecutable(t, tmpDir, "address already in use")
+
+	pf := &MockPIDFile{ReadErr: os.ErrNotExist}
+	d := New(pf, &process.MockManager{}, execPath)
+
+	err := d.Start(Options{Folder: tmpDir, Port: 18080, Bind: "127.0.0.1", StartTimeout: 5 * time.Second})
+	if err == nil || !strings.Contains(err.Error(), "exited during startup") {
+		t.Fatalf("Start() error = %v, want a startup failure", err)
+	}
+	if !pf.Removed {
+		t.Error("Start() should remove the PID file of a service that never became ready")
+	}
+}
+
+func TestCheckAddrFree(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	if err := checkAddrFree("127.0.0.1", port); err == nil {
+		t.Error("checkAddrFree() should fail while the port is in use")
+	}
+	listener.Close()
+	if err := checkAddrFree("127.0.0.1", port); err != nil {
+		t.Errorf("checkAddrFree() on a free port error = %v", err)
+	}
+	if err := checkAddrFree("192.0.2.1", port); err == nil || strings.Contains(err.Error(), "already in use") {
+		t.Errorf("checkAddrFree() on a foreign address error = %v, want a plain listen error", err)
+	}
+}
+
+// closedURL returns a health URL on a port nothing listens on
+func closedURL(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+	return "http://" + addr + "/health"
+}
+
+func TestStartAddrInUse(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer listener.Close()
+	tmpDir := t.TempDir()
+
+	pf := &MockPIDFile{ReadErr: os.ErrNotExist}
+	d := New(pf, &process.MockManager{}, createTestExecutable(t, tmpDir))
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	err = d.Start(Options{Folder: tmpDir, Port: port, Bind: "127.0.0.1", StartTimeout: 5 * time.Second})
+	if err == nil || !strings.Contains(err.Error(), "already in use") {
+		t.Fatalf("Start() error = %v, want the port reported in use", err)
+	}
+	if pf.Written != 0 {
+		t.Error("Start() should not spawn a service when the port is in use")
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gowebdavd/internal/pidfile"
+)
+
+// Info describes a running server. The run process writes it to a sidecar
+// file next to the PID file so that Status can show what it serves.
+type Info struct {
+	PID     int       `json:"pid"`
+	Folder  string    `json:"folder,omitempty"`
+	Mounts  []string  `json:"mounts,omitempty"`
+	ZipFile string    `json:"zip,omitempty"`
+	Port    int       `json:"port"`
+	Bind    string    `json:"bind"`
+	Started time.Time `json:"started"`
+}
+
+// InfoPath returns the path of the sidecar file belonging to pf
+func InfoPath(pf pidfile.File) string {
+	return strings.TrimSuffix(pf.Path(), ".pid") + ".json"
+}
+
+// WriteInfo writes info to the sidecar file of pf
+func WriteInfo(pf pidfile.File, info Info) error {
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(InfoPath(pf), data, 0644); err != nil {
+		return fmt.Errorf("failed to write status file: %w", err)
+	}
+	return nil
+}
+
+// RemoveInfo removes the sidecar file of pf if it still describes pid, so
+// that an instance does not remove the file of another one
+func RemoveInfo(pf pidfile.File, pid int) {
+	if info, err := readInfo(pf); err == nil && info.PID == pid {
+		os.Remove(InfoPath(pf))
+	}
+}
+
+// readInfo reads the sidecar file of pf
+func readInfo(pf pidfile.File) (Info, error) {
+	data, err := os.ReadFile(InfoPath(pf))
+	if err != nil {
+		return Info{}, err
+	}
+	var info Info
+	if err := json.Unmarshal(data, &info); err != nil {
+		return Info{}, fmt.Errorf("invalid status file: %w", err)
+	}
+	return info, nil
+}
+
+// describe renders info as indented lines for the status command
+func (info Info) describe(now time.Time) string {
+	serving := info.Folder
+	switch {
+	case info.ZipFile != "":
+		serving = info.ZipFile + " (zip)"
+	case len(info.Mounts) > 0:
+		serving = strings.Join(info.Mounts, ", ")
+	}
+	uptime := now.Sub(info.Started).Truncate(time.Second)
+	return fmt.Sprintf("  Serving: %s\n  Address: %s:%d\n  Uptime:  %s (since %s)\n",
+		serving, info.Bind, info.Port, uptime, info.Started.Format(time.RFC3339))
+}
@@ -0,0 +1,86 @@
+package daemon
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gowebdavd/internal/process"
+)
+
+func TestInfoPath(t *testing.T) {
+	pf := &MockPIDFile{PathValue: "/tmp/gowebdavd.pid"}
+	if got := InfoPath(pf); got != "/tmp/gowebdavd.json" {
+		t.Errorf("InfoPath() = %q, want /tmp/gowebdavd.json", got)
+	}
+}
+
+func TestWriteInfoRoundTrip(t *testing.T) {
+	pf := &MockPIDFile{PathValue: filepath.Join(t.TempDir(), "gowebdavd.pid")}
+	want := Info{PID: 1234, Folder: "/data", Port: 8080, Bind: "127.0.0.1", Started: time.Date(2026, 2, 16, 10, 30, 0, 0, time.UTC)}
+
+	if err := WriteInfo(pf, want); err != nil {
+		t.Fatalf("WriteInfo() error = %v", err)
+	}
+	got, err := readInfo(pf)
+	if err != nil {
+		t.Fatalf("readInfo() error = %v", err)
+	}
+	if got.PID != want.PID || got.Folder != want.Folder || got.Port != want.Port || got.Bind != want.Bind || !got.Started.Equal(want.Started) {
+		t.Errorf("readInfo() = %+v, want %+v", got, want)
+	}
+
+	// Another instance's sidecar is left alone
+	RemoveInfo(pf, 999)
+	if _, err := os.Stat(InfoPath(pf)); err != nil {
+		t.Error("RemoveInfo() should keep the sidecar of another PID")
+	}
+	RemoveInfo(pf, 1234)
+	if _, err := os.Stat(InfoPath(pf)); !os.IsNotExist(err) {
+		t.Error("RemoveInfo() should remove the sidecar of its PID")
+	}
+}
+
+func TestInfoDescribe(t *testing.T) {
+	started := time.Date(2026, 2, 16, 10, 0, 0, 0, time.UTC)
+	now := started.Add(90*time.Minute + 1500*time.Millisecond)
+
+	tests := []struct {
+		name string
+		info Info
+		want string
+	}{
+		{name: "folder", info: Info{Folder: "/data"}, want: "Serving: /data\n"},
+		{name: "mounts", info: Info{Mounts: []string{"docs=/srv/docs", "media=/srv/media"}}, want: "Serving: docs=/srv/docs, media=/srv/media\n"},
+		{name: "zip", info: Info{ZipFile: "/srv/site.zip"}, want: "Serving: /srv/site.zip (zip)\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.info.Port, tt.info.Bind, tt.info.Started = 9090, "0.0.0.0", started
+			got := tt.info.describe(now)
+			for _, want := range []string{tt.want, "Address: 0.0.0.0:9090\n", "Uptime:  1h30m1s (since 2026-02-16T10:00:00Z)"} {
+				if !strings.Contains(got, want) {
+					t.Errorf("describe() = %q, want it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestStatusStalePIDRemovesInfo(t *testing.T) {
+	pf := &MockPIDFile{Pid: 1234, PathValue: filepath.Join(t.TempDir(), "gowebdavd.pid")}
+	if err := WriteInfo(pf, Info{PID: 1234}); err != nil {
+		t.Fatalf("WriteInfo() error = %v", err)
+	}
+	d := New(pf, &process.MockManager{RunningPids: map[int]bool{}}, "/bin/test")
+
+	if err := d.Status(); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Status() error = %v, want %v", err, ErrNotRunning)
+	}
+	if _, err := os.Stat(InfoPath(pf)); !os.IsNotExist(err) {
+		t.Error("Status() should remove the sidecar of a stale PID")
+	}
+}
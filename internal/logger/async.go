@@ -0,0 +1,91 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// asyncWriter hands log lines to a single writer goroutine through a bounded
+// channel. Lines that do not fit are dropped and counted instead of blocking
+// the request that produced them.
+type asyncWriter struct {
+	mu      sync.RWMutex
+	closed  bool
+	lines   chan string
+	done    chan struct{}
+	dropped atomic.Uint64
+}
+
+// StartAsync makes the logger write entries from a background goroutine,
+// buffering up to size entries. It has no effect on a disabled logger or when
+// size is not positive.
+func (l *Logger) StartAsync(size int) {
+	if !l.enabled || size <= 0 || l.async != nil {
+		return
+	}
+	a := &asyncWriter{
+		lines: make(chan string, size),
+		done:  make(chan struct{}),
+	}
+	go func() {
+		defer close(a.done)
+		for line := range a.lines {
+			l.logger.Print(line)
+		}
+	}()
+	l.async = a
+}
+
+// Async reports whether entries are written by the background goroutine of
+// StartAsync
+func (l *Logger) Async() bool {
+	return l != nil && l.async != nil
+}
+
+// Dropped returns the number of entries discarded because the async buffer
+// was full
+func (l *Logger) Dropped() uint64 {
+	if l == nil || l.async == nil {
+		return 0
+	}
+	return l.async.dropped.Load()
+}
+
+// output writes line directly or queues it for the async writer
+func (l *Logger) output(line string) {
+	if l.async == nil {
+		l.logger.Print(line)
+		return
+	}
+	a := l.async
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		a.dropped.Add(1)
+		return
+	}
+	select {
+	case a.lines <- line:
+	default:
+		a.dropped.Add(1)
+	}
+}
+
+// stopAsync flushes queued entries and stops the writer goroutine. Entries
+// logged afterwards, e.g. by requests outliving a forced shutdown, are dropped.
+func (l *Logger) stopAsync() {
+	a := l.async
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.lines)
+	}
+	a.mu.Unlock()
+	<-a.done
+}
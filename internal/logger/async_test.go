@@ -0,0 +1,112 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package logger
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingWriter blocks every write until release is closed
+type blockingWriter struct {
+	release chan struct{}
+	mu      sync.Mutex
+	buf     bytes.Buffer
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *blockingWriter) lines() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return strings.Count(w.buf.String(), "\n")
+}
+
+func TestAsync_DropsWhenFull(t *testing.T) {
+	const requests = 100
+
+	w := &blockingWriter{release: make(chan struct{})}
+	l := NewWithWriter(w, true)
+	l.StartAsync(1)
+
+	handler := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	finished := make(chan struct{})
+	go func() {
+		for i := 0; i < requests; i++ {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/flood", nil))
+		}
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		close(w.release)
+		t.Fatal("requests blocked on a stalled log writer")
+	}
+
+	dropped := l.Dropped()
+	if dropped == 0 {
+		t.Error("Dropped() = 0, want entries dropped on overflow")
+	}
+
+	close(w.release)
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := w.lines(); uint64(got) != requests-dropped {
+		t.Errorf("written entries = %d, want %d", got, requests-dropped)
+	}
+}
+
+func TestAsync_FlushesOnClose(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithWriter(&buf, true)
+	l.StartAsync(16)
+
+	handler := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for i := 0; i < 10; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/file", nil))
+	}
+
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := strings.Count(buf.String(), "GET /file 200"); got != 10 {
+		t.Errorf("flushed entries = %d, want 10", got)
+	}
+	if l.Dropped() != 0 {
+		t.Errorf("Dropped() = %d, want 0", l.Dropped())
+	}
+
+	// Entries after Close are counted, not written or panicking
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/late", nil))
+	if l.Dropped() != 1 {
+		t.Errorf("Dropped() after Close = %d, want 1", l.Dropped())
+	}
+}
+
+func TestAsync_DisabledLogger(t *testing.T) {
+	l := NewNopLogger()
+	l.StartAsync(8)
+	if l.Dropped() != 0 {
+		t.Errorf("Dropped() = %d, want 0", l.Dropped())
+	}
+	if err := l.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
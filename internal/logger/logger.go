@@ -5,31 +5,138 @@
 package logger
 
 import (
+	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// Format selects how log entries are written
+type Format int
+
+const (
+	// FormatText writes space-separated fields after a timestamp
+	FormatText Format = iota
+	// FormatJSON writes one JSON object per line
+	FormatJSON
+	// FormatCombined writes requests in the Apache combined log format and
+	// leaves out free-form entries, which its parsers would reject
+	FormatCombined
+)
+
+// ParseFormat returns the Format named name, "text", "json" or "combined"
+func ParseFormat(name string) (Format, error) {
+	switch name {
+	case "text":
+		return FormatText, nil
+	case "json":
+		return FormatJSON, nil
+	case "combined":
+		return FormatCombined, nil
+	}
+	return FormatText, fmt.Errorf("unknown log format %q (want text, json or combined)", name)
+}
+
 // Logger handles HTTP request logging
 type Logger struct {
 	enabled bool
-	file    *os.File
-	logger  *log.Logger
+	format  Format
+	loc     *time.Location
+	file    *rotatingFile
+	// sink is the destination other than a log file, closed with the logger
+	sink io.Closer
+	// stamped is set when the destination timestamps entries itself
+	stamped bool
+	// stdout is set when entries are written to standard output
+	stdout bool
+	logger *log.Logger
+	async  *asyncWriter
 }
 
 // New creates a new Logger instance
 // logDir: custom log directory path. If empty, uses default directory.
 // When custom directory is specified, it must exist (won't be created automatically).
 func New(enabled bool, logDir string) (*Logger, error) {
+	return NewWithMaxSize(enabled, logDir, 0)
+}
+
+// DefaultRetentionDays is how long log files are kept unless configured
+const DefaultRetentionDays = 30
+
+// DefaultSyslogFacility is the syslog facility used unless configured
+const DefaultSyslogFacility = "daemon"
+
+// syslogTag identifies the entries of gowebdavd in syslog
+const syslogTag = "gowebdavd"
+
+// Options configures the log files of a Logger
+type Options struct {
+	// MaxSize starts a new log file whenever the current one would grow
+	// beyond this many bytes. 0 keeps a single file for the whole run.
+	MaxSize int64
+	// RetentionDays removes log files older than this many days. 0 keeps
+	// every file.
+	RetentionDays int
+	// RotateDaily starts a new log file at midnight, named by its date
+	// instead of the time it was started
+	RotateDaily bool
+	// Location is the time zone of the daily rotation, nil uses local time
+	Location *time.Location
+	// Syslog sends entries to the local syslog daemon instead of a log
+	// file, which makes the other settings and the log directory unused
+	Syslog bool
+	// SyslogFacility is the facility of syslog entries, such as "daemon"
+	// or "local0". Empty uses DefaultSyslogFacility.
+	SyslogFacility string
+	// Stdout writes entries to standard output instead of a log file, for
+	// platforms that capture it such as container runtimes
+	Stdout bool
+}
+
+// NewWithMaxSize creates a Logger like New that starts a new log file whenever
+// the current one would grow beyond maxSize bytes. A maxSize of 0 keeps a
+// single file for the whole run.
+func NewWithMaxSize(enabled bool, logDir string, maxSize int64) (*Logger, error) {
+	return NewWithOptions(enabled, logDir, Options{MaxSize: maxSize, RetentionDays: DefaultRetentionDays})
+}
+
+// NewWithOptions creates a Logger like New with the log file settings of opts
+func NewWithOptions(enabled bool, logDir string, opts Options) (*Logger, error) {
+	if opts.MaxSize < 0 {
+		return nil, fmt.Errorf("log size limit must not be negative: %d", opts.MaxSize)
+	}
+	if opts.RetentionDays < 0 {
+		return nil, fmt.Errorf("log retention must not be negative: %d days", opts.RetentionDays)
+	}
 	if !enabled {
 		return &Logger{enabled: false}, nil
 	}
+	if opts.Syslog && opts.Stdout {
+		return nil, fmt.Errorf("cannot log to both syslog and standard output")
+	}
+	if opts.Stdout {
+		return &Logger{enabled: true, stdout: true, logger: log.New(os.Stdout, "", log.LstdFlags)}, nil
+	}
+	if opts.Syslog {
+		facility := opts.SyslogFacility
+		if facility == "" {
+			facility = DefaultSyslogFacility
+		}
+		w, err := openSyslog(facility, syslogTag)
+		if err != nil {
+			return nil, err
+		}
+		return &Logger{enabled: true, sink: w, stamped: true, logger: log.New(w, "", 0)}, nil
+	}
 
 	var err error
 	useDefaultDir := logDir == ""
@@ -58,17 +165,14 @@ func New(enabled bool, logDir string) (*Logger, error) {
 		}
 	}
 
-	if err := cleanupOldLogs(logDir); err != nil {
+	if err := cleanupOldLogs(logDir, opts.RetentionDays); err != nil {
 		// Log cleanup errors but don't fail
 		log.Printf("Warning: failed to cleanup old logs: %v", err)
 	}
 
-	timestamp := time.Now().Format("2006-01-02_15-04-05")
-	logFile := filepath.Join(logDir, fmt.Sprintf("gowebdavd_%s.log", timestamp))
-
-	file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	file, err := openRotatingFile(logDir, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create log file: %w", err)
+		return nil, err
 	}
 
 	return &Logger{
@@ -78,49 +182,274 @@ func New(enabled bool, logDir string) (*Logger, error) {
 	}, nil
 }
 
-// Close closes the log file
+// Close flushes pending asynchronous entries and closes the log file
 func (l *Logger) Close() error {
+	l.stopAsync()
 	if l.file != nil {
 		return l.file.Close()
 	}
+	if l.sink != nil {
+		return l.sink.Close()
+	}
 	return nil
 }
 
-// Middleware returns HTTP middleware that logs requests
-func (l *Logger) Middleware(next http.Handler) http.Handler {
+// Rotate closes the current log file and continues in a new timestamped one.
+// Loggers without a log file have nothing to rotate.
+func (l *Logger) Rotate() error {
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Rotate()
+}
+
+// SetFormat selects the format of subsequent entries. JSON and combined
+// entries carry their own timestamp, so the line prefix of the text format is
+// dropped, as it is for destinations such as syslog that add their own.
+func (l *Logger) SetFormat(format Format) {
 	if !l.enabled {
-		return next
+		return
+	}
+	l.format = format
+	if format == FormatText && !l.stamped {
+		l.logger.SetFlags(log.LstdFlags)
+	} else {
+		l.logger.SetFlags(0)
+	}
+}
+
+// SetLocation sets the timezone of JSON and combined entry timestamps. The
+// default is the local timezone.
+func (l *Logger) SetLocation(loc *time.Location) {
+	l.loc = loc
+}
+
+// timestamp formats t for a JSON entry
+func (l *Logger) timestamp(t time.Time) string {
+	return l.in(t).Format(jsonTimeFormat)
+}
+
+// in converts t to the timezone of entry timestamps
+func (l *Logger) in(t time.Time) time.Time {
+	if l.loc != nil {
+		return t.In(l.loc)
 	}
+	return t
+}
 
+// accessEntry is a request log entry in the JSON format
+type accessEntry struct {
+	Time         string  `json:"time"`
+	RemoteAddr   string  `json:"remote_addr"`
+	Method       string  `json:"method"`
+	Path         string  `json:"path"`
+	Status       int     `json:"status"`
+	DurationMS   float64 `json:"duration_ms"`
+	BytesWritten int64   `json:"bytes_written"`
+	TLSVersion   string  `json:"tls_version,omitempty"`
+	TLSCipher    string  `json:"tls_cipher,omitempty"`
+	UserAgent    string  `json:"user_agent"`
+}
+
+// messageEntry is a free-form log entry in the JSON format
+type messageEntry struct {
+	Time    string `json:"time"`
+	Message string `json:"msg"`
+}
+
+// jsonTimeFormat is RFC 3339 with millisecond precision
+const jsonTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// combinedTimeFormat is the timestamp of the Apache log formats
+const combinedTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// Response describes how a request was answered
+type Response struct {
+	Status       int
+	Start        time.Time
+	Duration     time.Duration
+	BytesRead    int64
+	BytesWritten int64
+}
+
+// Observe returns middleware that measures each request and passes the
+// result to observe once next has answered it. It is the instrumentation of
+// the request log, usable without logging.
+func Observe(next http.Handler, observe func(r *http.Request, resp Response)) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
-		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK, head: r.Method == http.MethodHead}
+		body := &countingReader{ReadCloser: r.Body}
+		if r.Body != nil {
+			r.Body = body
+		}
 
 		next.ServeHTTP(wrapped, r)
 
-		duration := time.Since(start)
-
-		l.logger.Printf("%s %s %s %d %s %s",
-			r.RemoteAddr,
-			r.Method,
-			r.URL.Path,
-			wrapped.statusCode,
-			duration,
-			r.UserAgent(),
-		)
+		observe(r, Response{
+			Status:       wrapped.statusCode,
+			Start:        start,
+			Duration:     time.Since(start),
+			BytesRead:    body.n,
+			BytesWritten: wrapped.bytesWritten,
+		})
 	})
 }
 
+// Middleware returns HTTP middleware that logs requests
+func (l *Logger) Middleware(next http.Handler) http.Handler {
+	if !l.enabled {
+		return next
+	}
+	return Observe(next, l.logRequest)
+}
+
+// logRequest writes the entry of a completed request
+func (l *Logger) logRequest(r *http.Request, resp Response) {
+	if l.format == FormatJSON {
+		entry := accessEntry{
+			Time:         l.timestamp(resp.Start),
+			RemoteAddr:   r.RemoteAddr,
+			Method:       r.Method,
+			Path:         r.URL.Path,
+			Status:       resp.Status,
+			DurationMS:   float64(resp.Duration.Microseconds()) / 1000,
+			BytesWritten: resp.BytesWritten,
+			UserAgent:    r.UserAgent(),
+		}
+		if r.TLS != nil {
+			entry.TLSVersion = tls.VersionName(r.TLS.Version)
+			entry.TLSCipher = tls.CipherSuiteName(r.TLS.CipherSuite)
+		}
+		l.outputJSON(entry)
+		return
+	}
+	if l.format == FormatCombined {
+		l.output(l.combinedEntry(r, resp))
+		return
+	}
+
+	// Fields added over time are appended, so parsers of older lines keep
+	// working
+	l.output(fmt.Sprintf("%s %s %s %d %s %s%s %d",
+		r.RemoteAddr,
+		r.Method,
+		r.URL.Path,
+		resp.Status,
+		resp.Duration,
+		r.UserAgent(),
+		tlsInfo(r.TLS),
+		resp.BytesWritten,
+	))
+}
+
+// PrintsToStdout reports whether Printf writes messages to standard output
+func (l *Logger) PrintsToStdout() bool {
+	return l != nil && l.enabled && l.stdout && l.format != FormatCombined
+}
+
+// Printf writes a free-form entry when logging is enabled
+func (l *Logger) Printf(format string, args ...any) {
+	if !l.enabled {
+		return
+	}
+	if l.format == FormatCombined {
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	if l.format == FormatJSON {
+		l.outputJSON(messageEntry{Time: l.timestamp(time.Now()), Message: msg})
+		return
+	}
+	l.output(msg)
+}
+
+// combinedEntry formats a request in the Apache combined log format:
+// host ident user [time] "request line" status bytes "referer" "user agent"
+func (l *Logger) combinedEntry(r *http.Request, resp Response) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	uri := r.RequestURI
+	if uri == "" {
+		uri = r.URL.RequestURI()
+	}
+	bytes := "-"
+	if resp.BytesWritten > 0 {
+		bytes = strconv.FormatInt(resp.BytesWritten, 10)
+	}
+	return fmt.Sprintf(`%s - - [%s] "%s %s %s" %d %s "%s" "%s"`,
+		host,
+		l.in(resp.Start).Format(combinedTimeFormat),
+		escapeField(r.Method),
+		escapeField(uri),
+		escapeField(r.Proto),
+		resp.Status,
+		bytes,
+		orDash(escapeField(r.Referer())),
+		orDash(escapeField(r.UserAgent())),
+	)
+}
+
+// escapeField escapes quotes, backslashes and control characters of a
+// quoted log field as Apache does, so a field cannot end early or forge a line
+func escapeField(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// orDash returns s, or "-" for an empty s
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// outputJSON writes entry as a single line of JSON
+func (l *Logger) outputJSON(entry any) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	l.output(string(data))
+}
+
+// tlsInfo formats the negotiated TLS version and cipher suite as extra log
+// fields. It returns an empty string for plain HTTP connections.
+func tlsInfo(state *tls.ConnectionState) string {
+	if state == nil {
+		return ""
+	}
+	return " " + tls.VersionName(state.Version) + " " + tls.CipherSuiteName(state.CipherSuite)
+}
+
 // Enabled returns whether logging is enabled
 func (l *Logger) Enabled() bool {
 	return l.enabled
 }
 
-// responseWriter wraps http.ResponseWriter to capture status code
+// responseWriter wraps http.ResponseWriter to capture status code and the
+// number of body bytes sent. Responses without a body, to HEAD or with 1xx,
+// 204 or 304, count zero bytes even when the handler writes to them.
 type responseWriter struct {
 	http.ResponseWriter
-	statusCode int
+	statusCode   int
+	head         bool
+	bytesWritten int64
 }
 
 func (rw *responseWriter) WriteHeader(code int) {
@@ -129,7 +458,29 @@ func (rw *responseWriter) WriteHeader(code int) {
 }
 
 func (rw *responseWriter) Write(b []byte) (int, error) {
-	return rw.ResponseWriter.Write(b)
+	n, err := rw.ResponseWriter.Write(b)
+	if rw.bodyAllowed() {
+		rw.bytesWritten += int64(n)
+	}
+	return n, err
+}
+
+// countingReader counts the request body bytes read by the handler
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// bodyAllowed reports whether the response carries a body
+func (rw *responseWriter) bodyAllowed() bool {
+	code := rw.statusCode
+	return !rw.head && code >= 200 && code != http.StatusNoContent && code != http.StatusNotModified
 }
 
 // getLogDir returns the log directory path based on OS
@@ -152,8 +503,12 @@ func getLogDir() (string, error) {
 	return filepath.Join(homeDir, ".local", "share", "gowebdavd", "logs"), nil
 }
 
-// cleanupOldLogs removes log files older than 1 month
-func cleanupOldLogs(logDir string) error {
+// cleanupOldLogs removes log files older than retentionDays days. A
+// retentionDays of 0 keeps every file.
+func cleanupOldLogs(logDir string, retentionDays int) error {
+	if retentionDays == 0 {
+		return nil
+	}
 	entries, err := os.ReadDir(logDir)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -162,7 +517,7 @@ func cleanupOldLogs(logDir string) error {
 		return err
 	}
 
-	cutoff := time.Now().AddDate(0, -1, 0)
+	cutoff := time.Now().AddDate(0, 0, -retentionDays)
 
 	for _, entry := range entries {
 		if entry.IsDir() {
@@ -5,10 +5,13 @@ package logger
 
 import (
 	"bytes"
+	"crypto/tls"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 	"testing"
@@ -170,6 +173,190 @@ func TestMiddleware_Enabled(t *testing.T) {
 	}
 }
 
+func TestMiddleware_TLSFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewWithWriter(&buf, true)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	wrapped := logger.Middleware(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/secure", nil)
+	req.Header.Set("User-Agent", "davfs2/1.7")
+	req.TLS = &tls.ConnectionState{
+		Version:     tls.VersionTLS13,
+		CipherSuite: tls.TLS_AES_128_GCM_SHA256,
+	}
+	wrapped.ServeHTTP(httptest.NewRecorder(), req)
+
+	logOutput := buf.String()
+	if !strings.HasSuffix(logOutput, " davfs2/1.7 TLS 1.3 TLS_AES_128_GCM_SHA256 0\n") {
+		t.Errorf("Expected TLS version and cipher after the user agent, got: %s", logOutput)
+	}
+
+	buf.Reset()
+	req = httptest.NewRequest(http.MethodGet, "/plain", nil)
+	wrapped.ServeHTTP(httptest.NewRecorder(), req)
+
+	if strings.Contains(buf.String(), "TLS") {
+		t.Errorf("Expected no TLS fields for plain HTTP, got: %s", buf.String())
+	}
+}
+
+func TestMiddleware_RedactsCredentials(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewWithWriter(&buf, true)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	wrapped := logger.Middleware(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/private", nil)
+	req.SetBasicAuth("alice", "s3cr3t-password")
+	wrapped.ServeHTTP(httptest.NewRecorder(), req)
+
+	logOutput := buf.String()
+	if logOutput == "" {
+		t.Fatal("Expected log output, got empty string")
+	}
+	if strings.Contains(logOutput, req.Header.Get("Authorization")) || strings.Contains(logOutput, "s3cr3t-password") {
+		t.Errorf("Expected credentials to be redacted, got: %s", logOutput)
+	}
+}
+
+func TestMiddleware_JSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewWithWriter(&buf, true)
+	logger.SetFormat(FormatJSON)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+		w.Write([]byte(" world"))
+	})
+	wrapped := logger.Middleware(handler)
+
+	req := httptest.NewRequest(http.MethodPut, "/docs/a.txt", nil)
+	req.RemoteAddr = "127.0.0.1:1234"
+	req.Header.Set("User-Agent", "davfs2/1.7")
+	wrapped.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Expected one JSON object per line, got %q: %v", buf.String(), err)
+	}
+	want := map[string]any{
+		"remote_addr":   "127.0.0.1:1234",
+		"method":        "PUT",
+		"path":          "/docs/a.txt",
+		"status":        float64(http.StatusCreated),
+		"bytes_written": float64(len("hello world")),
+		"user_agent":    "davfs2/1.7",
+	}
+	for k, v := range want {
+		if entry[k] != v {
+			t.Errorf("%s = %v, want %v", k, entry[k], v)
+		}
+	}
+	if _, ok := entry["duration_ms"].(float64); !ok {
+		t.Errorf("duration_ms = %v, want a number", entry["duration_ms"])
+	}
+	if _, err := time.Parse(time.RFC3339, entry["time"].(string)); err != nil {
+		t.Errorf("time = %v, want RFC 3339: %v", entry["time"], err)
+	}
+	if _, ok := entry["tls_version"]; ok {
+		t.Error("Expected no TLS fields for plain HTTP")
+	}
+
+	buf.Reset()
+	logger.Printf("client %s denied", "10.0.0.9")
+	var msg map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &msg); err != nil || msg["msg"] != "client 10.0.0.9 denied" {
+		t.Errorf("Printf() output = %q, want a JSON entry with the message", buf.String())
+	}
+}
+
+func TestMiddleware_JSONTimezone(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Skipf("Timezone database not available: %v", err)
+	}
+	var buf bytes.Buffer
+	logger := NewWithWriter(&buf, true)
+	logger.SetFormat(FormatJSON)
+	logger.SetLocation(loc)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	logger.Middleware(handler).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var entry struct {
+		Time string `json:"time"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Invalid JSON entry %q: %v", buf.String(), err)
+	}
+	if !strings.HasSuffix(entry.Time, "+05:30") {
+		t.Errorf("time = %q, want the +05:30 offset of Asia/Kolkata", entry.Time)
+	}
+}
+
+func TestMiddleware_CombinedFormat(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Skipf("Timezone database not available: %v", err)
+	}
+	var buf bytes.Buffer
+	logger := NewWithWriter(&buf, true)
+	logger.SetFormat(FormatCombined)
+	logger.SetLocation(loc)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+	})
+	wrapped := logger.Middleware(handler)
+
+	req := httptest.NewRequest(http.MethodPut, "/docs/a.txt?v=1", nil)
+	req.RemoteAddr = "192.0.2.7:1234"
+	req.Header.Set("Referer", "https://example.com/")
+	req.Header.Set("User-Agent", `evil "agent"`+"\n")
+	wrapped.ServeHTTP(httptest.NewRecorder(), req)
+
+	line := strings.TrimSuffix(buf.String(), "\n")
+	pattern := regexp.MustCompile(`^192\.0\.2\.7 - - \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} \+0530\] "PUT /docs/a\.txt\?v=1 HTTP/1\.1" 201 5 "https://example\.com/" "evil \\"agent\\"\\x0a"$`)
+	if !pattern.MatchString(line) {
+		t.Errorf("combined entry = %q, want %s", line, pattern)
+	}
+
+	buf.Reset()
+	req = httptest.NewRequest(http.MethodGet, "/empty", nil)
+	logger.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(httptest.NewRecorder(), req)
+	if !strings.HasSuffix(buf.String(), `"GET /empty HTTP/1.1" 200 - "-" "-"`+"\n") {
+		t.Errorf("combined entry = %q, want dashes for no body, referer and user agent", buf.String())
+	}
+
+	// Free-form messages would break combined log parsers
+	buf.Reset()
+	logger.Printf("Configuration reloaded")
+	if buf.Len() != 0 {
+		t.Errorf("Printf() wrote %q in combined format, want nothing", buf.String())
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for name, want := range map[string]Format{"text": FormatText, "json": FormatJSON, "combined": FormatCombined} {
+		got, err := ParseFormat(name)
+		if err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %v, %v, want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("ParseFormat(\"xml\") should fail")
+	}
+}
+
 func TestResponseWriter(t *testing.T) {
 	rec := httptest.NewRecorder()
 	rw := &responseWriter{ResponseWriter: rec, statusCode: http.StatusOK}
@@ -183,6 +370,41 @@ func TestResponseWriter(t *testing.T) {
 	if rec.Code != http.StatusNotFound {
 		t.Errorf("Expected recorder code %d, got %d", http.StatusNotFound, rec.Code)
 	}
+
+	rw.Write([]byte("not "))
+	rw.Write([]byte("found"))
+	if rw.bytesWritten != 9 {
+		t.Errorf("Expected 9 bytes written, got %d", rw.bytesWritten)
+	}
+}
+
+func TestMiddleware_BytesWritten(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		status int
+		want   string
+	}{
+		{name: "GET", method: http.MethodGet, status: http.StatusOK, want: " 5\n"},
+		{name: "HEAD", method: http.MethodHead, status: http.StatusOK, want: " 0\n"},
+		{name: "no content", method: http.MethodDelete, status: http.StatusNoContent, want: " 0\n"},
+		{name: "not modified", method: http.MethodGet, status: http.StatusNotModified, want: " 0\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := NewWithWriter(&buf, true)
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte("hello"))
+			})
+			logger.Middleware(handler).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, "/file.txt", nil))
+
+			if !strings.HasSuffix(buf.String(), tt.want) {
+				t.Errorf("Expected log to end with %q, got: %s", tt.want, buf.String())
+			}
+		})
+	}
 }
 
 func TestCleanupOldLogs(t *testing.T) {
@@ -207,7 +429,7 @@ func TestCleanupOldLogs(t *testing.T) {
 	}
 
 	// Run cleanup
-	if err := cleanupOldLogs(tempDir); err != nil {
+	if err := cleanupOldLogs(tempDir, DefaultRetentionDays); err != nil {
 		t.Fatalf("cleanupOldLogs error = %v", err)
 	}
 
@@ -222,6 +444,45 @@ func TestCleanupOldLogs(t *testing.T) {
 	}
 }
 
+func TestCleanupOldLogs_RetentionDays(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// Create log files just inside and just outside a 90 day retention
+	keptFile := filepath.Join(tempDir, "gowebdavd_kept.log")
+	expiredFile := filepath.Join(tempDir, "gowebdavd_expired.log")
+	for file, age := range map[string]int{keptFile: 89, expiredFile: 91} {
+		if err := os.WriteFile(file, []byte("log"), 0644); err != nil {
+			t.Fatalf("Failed to create log file: %v", err)
+		}
+		modTime := time.Now().AddDate(0, 0, -age)
+		if err := os.Chtimes(file, modTime, modTime); err != nil {
+			t.Fatalf("Failed to set file time: %v", err)
+		}
+	}
+
+	if err := cleanupOldLogs(tempDir, 90); err != nil {
+		t.Fatalf("cleanupOldLogs error = %v", err)
+	}
+	if _, err := os.Stat(keptFile); err != nil {
+		t.Error("Expected log file within retention to exist")
+	}
+	if _, err := os.Stat(expiredFile); !os.IsNotExist(err) {
+		t.Error("Expected log file beyond retention to be removed")
+	}
+
+	// A retention of 0 disables cleanup
+	ancient := time.Now().AddDate(-5, 0, 0)
+	if err := os.Chtimes(keptFile, ancient, ancient); err != nil {
+		t.Fatalf("Failed to set file time: %v", err)
+	}
+	if err := cleanupOldLogs(tempDir, 0); err != nil {
+		t.Fatalf("cleanupOldLogs error = %v", err)
+	}
+	if _, err := os.Stat(keptFile); err != nil {
+		t.Error("Expected cleanup to be disabled with a retention of 0")
+	}
+}
+
 func TestCleanupOldLogs_SkipNonLogFiles(t *testing.T) {
 	tempDir := t.TempDir()
 
@@ -238,7 +499,7 @@ func TestCleanupOldLogs_SkipNonLogFiles(t *testing.T) {
 	}
 
 	// Run cleanup
-	if err := cleanupOldLogs(tempDir); err != nil {
+	if err := cleanupOldLogs(tempDir, DefaultRetentionDays); err != nil {
 		t.Fatalf("cleanupOldLogs error = %v", err)
 	}
 
@@ -252,7 +513,7 @@ func TestCleanupOldLogs_NonExistentDir(t *testing.T) {
 	nonExistentDir := filepath.Join(t.TempDir(), "nonexistent")
 
 	// Should not error for non-existent directory
-	if err := cleanupOldLogs(nonExistentDir); err != nil {
+	if err := cleanupOldLogs(nonExistentDir, DefaultRetentionDays); err != nil {
 		t.Errorf("cleanupOldLogs error = %v", err)
 	}
 }
@@ -301,3 +562,79 @@ func TestGetLogDir(t *testing.T) {
 		}
 	}
 }
+
+func TestPrintf(t *testing.T) {
+	var buf bytes.Buffer
+	NewWithWriter(&buf, true).Printf("client %s denied", "10.0.0.9")
+	if !strings.Contains(buf.String(), "client 10.0.0.9 denied") {
+		t.Errorf("Printf() output = %q, want the formatted entry", buf.String())
+	}
+
+	NewNopLogger().Printf("ignored")
+}
+
+func TestNewWithOptions_Stdout(t *testing.T) {
+	out, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	stdout := os.Stdout
+	os.Stdout = out
+	defer func() { os.Stdout = stdout }()
+
+	logDir := filepath.Join(t.TempDir(), "logs")
+	l, err := NewWithOptions(true, logDir, Options{Stdout: true})
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	l.Printf("hello stdout")
+	if err := l.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if _, err := out.Write(nil); err != nil {
+		t.Errorf("Close() closed standard output: %v", err)
+	}
+
+	data, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "hello stdout") {
+		t.Errorf("stdout = %q, want the entry", data)
+	}
+	if _, err := os.Stat(logDir); !os.IsNotExist(err) {
+		t.Errorf("log directory created with -log-stdout: %v", err)
+	}
+
+	if _, err := NewWithOptions(true, "", Options{Stdout: true, Syslog: true}); err == nil {
+		t.Error("NewWithOptions() accepted both Stdout and Syslog")
+	}
+}
+
+func TestPrintsToStdout(t *testing.T) {
+	l, err := NewWithOptions(true, "", Options{Stdout: true})
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	if !l.PrintsToStdout() {
+		t.Error("PrintsToStdout() = false with Stdout")
+	}
+	// The combined format leaves out free-form entries
+	l.SetFormat(FormatCombined)
+	if l.PrintsToStdout() {
+		t.Error("PrintsToStdout() = true with the combined format")
+	}
+
+	file, err := New(true, t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer file.Close()
+	var nilLogger *Logger
+	for _, l := range []*Logger{file, NewNopLogger(), nilLogger} {
+		if l.PrintsToStdout() {
+			t.Errorf("PrintsToStdout() = true for %v", l)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package logger
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// rotatingFile writes to a timestamped log file in dir and switches to a new
+// one before a write would grow the current file beyond maxSize bytes. A
+// maxSize of 0 never rotates. With daily set, files are named by their date
+// and a new one is also started at midnight in loc. Files older than
+// retentionDays are removed on rotation. Writes are serialized, so concurrent
+// request logging never interleaves with a rotation.
+type rotatingFile struct {
+	mu            sync.Mutex
+	dir           string
+	maxSize       int64
+	retentionDays int
+	daily         bool
+	loc           *time.Location
+	day           string
+	timer         *time.Timer
+	file          *os.File
+	size          int64
+	now           func() time.Time
+}
+
+// openRotatingFile opens the first log file in dir
+func openRotatingFile(dir string, opts Options) (*rotatingFile, error) {
+	f := &rotatingFile{
+		dir:           dir,
+		maxSize:       opts.MaxSize,
+		retentionDays: opts.RetentionDays,
+		daily:         opts.RotateDaily,
+		loc:           opts.Location,
+		now:           time.Now,
+	}
+	if f.loc == nil {
+		f.loc = time.Local
+	}
+	// Without size rotation, a restart within the same second, or the same
+	// day for daily files, appends
+	if err := f.open(opts.MaxSize == 0); err != nil {
+		return nil, err
+	}
+	if f.daily {
+		f.timer = time.AfterFunc(f.untilMidnight(), f.midnight)
+	}
+	return f, nil
+}
+
+// open creates a new log file named after the current time, or date for
+// daily files. Files started within the same second or day get a numeric
+// suffix, unless appendExisting reuses the existing one.
+func (f *rotatingFile) open(appendExisting bool) error {
+	now := f.now().In(f.loc)
+	timestamp := now.Format("2006-01-02_15-04-05")
+	if f.daily {
+		timestamp = now.Format(dayFormat)
+		f.day = timestamp
+	}
+	name := filepath.Join(f.dir, fmt.Sprintf("gowebdavd_%s.log", timestamp))
+	for i := 1; ; i++ {
+		if _, err := os.Stat(name); os.IsNotExist(err) {
+			break
+		}
+		if appendExisting {
+			break
+		}
+		name = filepath.Join(f.dir, fmt.Sprintf("gowebdavd_%s_%d.log", timestamp, i))
+	}
+
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create log file: %w", err)
+	}
+	fi, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to create log file: %w", err)
+	}
+	f.file = file
+	f.size = fi.Size()
+	return nil
+}
+
+// Write appends p to the current file, rotating first when p does not fit.
+// An entry larger than maxSize still goes to a file of its own.
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	newDay := f.daily && f.now().In(f.loc).Format(dayFormat) != f.day
+	if newDay || (f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize) {
+		if err := f.rotate(); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate switches to a new log file now, regardless of its size
+func (f *rotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rotate()
+}
+
+// rotate closes the current file, applies the age retention and starts a new
+// file. When the new file cannot be created, writing continues in the old one.
+func (f *rotatingFile) rotate() error {
+	old := f.file
+	oldSize := f.size
+	if err := f.open(false); err != nil {
+		f.file, f.size = old, oldSize
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	old.Close()
+
+	if err := cleanupOldLogs(f.dir, f.retentionDays); err != nil {
+		log.Printf("Warning: failed to cleanup old logs: %v", err)
+	}
+	return nil
+}
+
+// dayFormat names daily log files
+const dayFormat = "2006-01-02"
+
+// untilMidnight returns the time left until the next midnight in loc
+func (f *rotatingFile) untilMidnight() time.Duration {
+	now := f.now().In(f.loc)
+	next := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, f.loc)
+	return next.Sub(now)
+}
+
+// midnight starts the file of the new day, so that it exists even before the
+// first entry of the day, and schedules the next rotation
+func (f *rotatingFile) midnight() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.timer == nil {
+		// Closed
+		return
+	}
+	if f.now().In(f.loc).Format(dayFormat) != f.day {
+		if err := f.rotate(); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+	f.timer.Reset(f.untilMidnight())
+}
+
+// Close closes the current log file
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.timer != nil {
+		f.timer.Stop()
+		f.timer = nil
+	}
+	return f.file.Close()
+}
@@ -0,0 +1,228 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// logFiles returns the contents of the log files in dir
+func logFiles(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	matches, err := filepath.Glob(filepath.Join(dir, "gowebdavd_*.log"))
+	if err != nil {
+		t.Fatalf("Glob() error = %v", err)
+	}
+	files := make(map[string]string, len(matches))
+	for _, name := range matches {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		files[filepath.Base(name)] = string(data)
+	}
+	return files
+}
+
+func TestRotatingFile_RotatesAtMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	f, err := openRotatingFile(dir, Options{MaxSize: 25, RetentionDays: DefaultRetentionDays})
+	if err != nil {
+		t.Fatalf("openRotatingFile() error = %v", err)
+	}
+	defer f.Close()
+	f.now = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	for _, line := range []string{"first entry\n", "second entry\n", "third entry\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	files := logFiles(t, dir)
+	if len(files) != 2 {
+		t.Fatalf("got %d log files, want 2: %v", len(files), files)
+	}
+	if got := files["gowebdavd_2026-03-01_12-00-00.log"]; got != "third entry\n" {
+		t.Errorf("rotated file = %q, want the third entry", got)
+	}
+	for name, content := range files {
+		if len(content) > 25 {
+			t.Errorf("%s has %d bytes, want at most 25", name, len(content))
+		}
+	}
+
+	// A second rotation within the same second gets a suffix
+	f.Write([]byte("fourth entry, long enough\n"))
+	if got := logFiles(t, dir)["gowebdavd_2026-03-01_12-00-00_1.log"]; got != "fourth entry, long enough\n" {
+		t.Errorf("suffixed file = %q, want the fourth entry", got)
+	}
+}
+
+func TestRotatingFile_NoLimit(t *testing.T) {
+	dir := t.TempDir()
+	f, err := openRotatingFile(dir, Options{RetentionDays: DefaultRetentionDays})
+	if err != nil {
+		t.Fatalf("openRotatingFile() error = %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		f.Write([]byte("entry\n"))
+	}
+	f.Close()
+
+	if files := logFiles(t, dir); len(files) != 1 {
+		t.Errorf("got %d log files without a limit, want 1", len(files))
+	}
+}
+
+func TestRotatingFile_Daily(t *testing.T) {
+	dir := t.TempDir()
+	f, err := openRotatingFile(dir, Options{MaxSize: 40, RetentionDays: DefaultRetentionDays, RotateDaily: true, Location: time.UTC})
+	if err != nil {
+		t.Fatalf("openRotatingFile() error = %v", err)
+	}
+	defer f.Close()
+	now := time.Date(2026, 3, 1, 23, 59, 0, 0, time.UTC)
+	f.now = func() time.Time { return now }
+
+	if d := f.untilMidnight(); d != time.Minute {
+		t.Errorf("untilMidnight() = %s, want 1m", d)
+	}
+
+	f.Write([]byte("before midnight\n"))
+	now = now.Add(2 * time.Minute)
+	f.Write([]byte("after midnight\n"))
+	// Size rotation still applies within a day
+	f.Write([]byte("later that day, a long entry\n"))
+
+	files := logFiles(t, dir)
+	if got := files["gowebdavd_2026-03-01.log"]; got != "before midnight\n" {
+		t.Errorf("file of March 1st = %q, want the entry before midnight", got)
+	}
+	if got := files["gowebdavd_2026-03-02.log"]; got != "after midnight\n" {
+		t.Errorf("file of March 2nd = %q, want the entry after midnight", got)
+	}
+	if got := files["gowebdavd_2026-03-02_1.log"]; got != "later that day, a long entry\n" {
+		t.Errorf("suffixed file = %q, want the entry exceeding the size limit", got)
+	}
+
+	// The midnight timer starts the next file without waiting for an entry
+	now = time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)
+	f.midnight()
+	if _, ok := logFiles(t, dir)["gowebdavd_2026-03-03.log"]; !ok {
+		t.Error("midnight() did not start the file of March 3rd")
+	}
+}
+
+func TestRotatingFile_AppliesRetention(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "gowebdavd_2020-01-01_00-00-00.log")
+	if err := os.WriteFile(old, []byte("old\n"), 0644); err != nil {
+		t.Fatalf("Failed to create old log: %v", err)
+	}
+	past := time.Now().AddDate(0, -2, 0)
+	os.Chtimes(old, past, past)
+
+	f, err := openRotatingFile(dir, Options{MaxSize: 10, RetentionDays: DefaultRetentionDays})
+	if err != nil {
+		t.Fatalf("openRotatingFile() error = %v", err)
+	}
+	defer f.Close()
+	f.Write([]byte("entry one\n"))
+	f.Write([]byte("entry two\n"))
+
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Error("rotation did not remove the expired log file")
+	}
+}
+
+func TestNewWithMaxSize_ConcurrentLogging(t *testing.T) {
+	dir := t.TempDir()
+	l, err := NewWithMaxSize(true, dir, 512)
+	if err != nil {
+		t.Fatalf("NewWithMaxSize() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				l.Printf("worker %d entry %d", i, j)
+			}
+		}()
+	}
+	wg.Wait()
+	l.Close()
+
+	entries := 0
+	for name, content := range logFiles(t, dir) {
+		if len(content) > 512 {
+			t.Errorf("%s has %d bytes, want at most 512", name, len(content))
+		}
+		for _, line := range strings.Split(strings.TrimSpace(content), "\n") {
+			if !strings.Contains(line, "worker") {
+				t.Errorf("%s has a garbled line %q", name, line)
+			}
+			entries++
+		}
+	}
+	if entries != 200 {
+		t.Errorf("logged %d entries, want 200", entries)
+	}
+}
+
+func TestNewWithOptions_NegativeRetention(t *testing.T) {
+	if _, err := NewWithOptions(true, t.TempDir(), Options{RetentionDays: -1}); err == nil {
+		t.Error("NewWithOptions() should reject a negative retention")
+	}
+}
+
+func TestNewWithMaxSize_Negative(t *testing.T) {
+	if _, err := NewWithMaxSize(true, t.TempDir(), -1); err == nil {
+		t.Error("NewWithMaxSize() should reject a negative size")
+	}
+}
+
+func TestLogger_Rotate(t *testing.T) {
+	dir := t.TempDir()
+	l, err := New(true, dir)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer l.Close()
+
+	l.Printf("before rotation")
+	old := l.file.file
+	if err := l.Rotate(); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	l.Printf("after rotation")
+
+	if _, err := old.Write([]byte("x")); err == nil {
+		t.Error("Rotate() should close the previous log file")
+	}
+	files := logFiles(t, dir)
+	if len(files) != 2 {
+		t.Fatalf("got %d log files, want 2: %v", len(files), files)
+	}
+	for name, content := range files {
+		before := strings.Contains(content, "before rotation")
+		after := strings.Contains(content, "after rotation")
+		if before == after {
+			t.Errorf("%s = %q, want exactly one of the entries", name, content)
+		}
+	}
+
+	if err := NewNopLogger().Rotate(); err != nil {
+		t.Errorf("Rotate() on a disabled logger error = %v", err)
+	}
+}
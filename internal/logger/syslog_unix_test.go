@@ -0,0 +1,32 @@
+//go:build !windows
+
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewWithOptions_Syslog(t *testing.T) {
+	if _, err := NewWithOptions(true, "", Options{Syslog: true, SyslogFacility: "bogus"}); err == nil {
+		t.Error("NewWithOptions() should reject an unknown syslog facility")
+	}
+
+	logDir := t.TempDir()
+	l, err := NewWithOptions(true, logDir, Options{Syslog: true, SyslogFacility: "local0"})
+	if err != nil {
+		t.Skipf("No syslog daemon available: %v", err)
+	}
+	if l.file != nil {
+		t.Error("Expected no log file with syslog")
+	}
+	l.SetFormat(FormatText)
+	if l.logger.Flags() != 0 {
+		t.Error("Expected no timestamp prefix, syslog adds its own")
+	}
+	l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if err := l.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
@@ -0,0 +1,16 @@
+//go:build windows
+
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package logger
+
+import (
+	"errors"
+	"io"
+)
+
+// openSyslog is unavailable on Windows, which has no syslog daemon
+func openSyslog(facility, tag string) (io.WriteCloser, error) {
+	return nil, errors.New("syslog is not available on Windows, log to a file instead")
+}
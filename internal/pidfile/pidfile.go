@@ -5,23 +5,30 @@
 package pidfile
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
 )
 
+// ErrLocked is returned by Lock when another process holds the lock
+var ErrLocked = errors.New("PID file is locked by another process")
+
 // File defines the interface for PID file operations
 type File interface {
 	Read() (int, error)
 	Write(pid int) error
 	Remove() error
 	Path() string
+	Lock() error
+	Unlock() error
 }
 
 // file implements File interface
 type file struct {
 	path string
+	lock *os.File
 }
 
 // New creates a new File instance with default path
@@ -63,3 +70,39 @@ func (p *file) Remove() error {
 func (p *file) Path() string {
 	return p.path
 }
+
+// Lock acquires an exclusive advisory lock guarding the PID file.
+// It does not block and returns ErrLocked when the lock is held elsewhere.
+// The lock lives in a separate ".lock" file so the PID file itself can still
+// be rewritten and removed while the lock is held.
+func (p *file) Lock() error {
+	if p.lock != nil {
+		return nil
+	}
+	f, err := os.OpenFile(p.lockPath(), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open lock file: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return err
+	}
+	p.lock = f
+	return nil
+}
+
+// Unlock releases the lock acquired by Lock
+func (p *file) Unlock() error {
+	if p.lock == nil {
+		return nil
+	}
+	err := unlockFile(p.lock)
+	p.lock.Close()
+	p.lock = nil
+	return err
+}
+
+// lockPath returns the path of the file holding the advisory lock
+func (p *file) lockPath() string {
+	return p.path + ".lock"
+}
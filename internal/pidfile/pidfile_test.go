@@ -1,6 +1,7 @@
 package pidfile
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -129,3 +130,40 @@ func TestFilePath(t *testing.T) {
 		t.Errorf("Path() = %s, want %s", path, expectedPath)
 	}
 }
+
+func TestFileLock(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "test.pid")
+	first := NewWithPath(path)
+	second := NewWithPath(path)
+
+	if err := first.Lock(); err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+
+	if err := second.Lock(); !errors.Is(err, ErrLocked) {
+		t.Errorf("second Lock() error = %v, want ErrLocked", err)
+	}
+
+	// The PID file stays writable while locked
+	if err := first.Write(12345); err != nil {
+		t.Errorf("Write() while locked error = %v", err)
+	}
+
+	if err := first.Unlock(); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+
+	if err := second.Lock(); err != nil {
+		t.Errorf("Lock() after Unlock error = %v", err)
+	}
+	second.Unlock()
+}
+
+func TestFileUnlockNotLocked(t *testing.T) {
+	pf := NewWithPath(filepath.Join(t.TempDir(), "test.pid"))
+
+	if err := pf.Unlock(); err != nil {
+		t.Errorf("Unlock() without Lock error = %v", err)
+	}
+}
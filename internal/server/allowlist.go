@@ -0,0 +1,80 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"gowebdavd/internal/logger"
+)
+
+// parseCIDRs parses CIDR ranges such as 192.168.1.0/24
+func parseCIDRs(list []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(list))
+	for _, s := range list {
+		_, n, err := net.ParseCIDR(strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR range %q: %w", s, err)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// containsIP reports whether ip is in any of nets
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the address of the client behind r. X-Forwarded-For is
+// only honoured when the connection comes from a trusted proxy; it is then
+// read right to left, skipping further trusted proxies, because only the
+// entries appended by trusted hops cannot be forged by the client.
+func clientIP(r *http.Request, proxies []*net.IPNet) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !containsIP(proxies, ip) {
+		return ip
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			break
+		}
+		ip = hop
+		if !containsIP(proxies, hop) {
+			break
+		}
+	}
+	return ip
+}
+
+// allowClients rejects with 403 requests from clients outside the allowed
+// ranges and logs each rejection
+func allowClients(next http.Handler, allowed, proxies []*net.IPNet, log *logger.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := clientIP(r, proxies)
+		if ip == nil || !containsIP(allowed, ip) {
+			if log != nil {
+				log.Printf("%s %s %s denied: client %s not in allowlist", r.RemoteAddr, r.Method, r.URL.Path, ip)
+			}
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
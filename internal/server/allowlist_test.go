@@ -0,0 +1,122 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gowebdavd/internal/logger"
+)
+
+func TestClientIP(t *testing.T) {
+	proxies, err := parseCIDRs([]string{"10.0.0.1/32", "10.0.0.2/32"})
+	if err != nil {
+		t.Fatalf("parseCIDRs() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		remote string
+		xff    string
+		want   string
+	}{
+		{name: "direct", remote: "192.168.1.7:4000", want: "192.168.1.7"},
+		{name: "untrusted forwarder ignored", remote: "192.168.1.7:4000", xff: "8.8.8.8", want: "192.168.1.7"},
+		{name: "trusted proxy", remote: "10.0.0.1:4000", xff: "192.168.1.7", want: "192.168.1.7"},
+		{name: "spoofed leftmost entry", remote: "10.0.0.1:4000", xff: "192.168.1.99, 8.8.8.8", want: "8.8.8.8"},
+		{name: "proxy chain", remote: "10.0.0.1:4000", xff: "192.168.1.7, 10.0.0.2", want: "192.168.1.7"},
+		{name: "proxy without header", remote: "10.0.0.1:4000", want: "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remote
+			if tt.xff != "" {
+				r.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			if got := clientIP(r, proxies); got.String() != tt.want {
+				t.Errorf("clientIP() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowlist(t *testing.T) {
+	var buf bytes.Buffer
+	opts := Options{
+		Folder:         t.TempDir(),
+		Port:           18080,
+		Bind:           "0.0.0.0",
+		AllowedNets:    []string{"192.168.1.0/24", "10.0.0.5/32"},
+		TrustedProxies: []string{"10.0.0.1/32"},
+	}
+	srv, err := NewWithOptions(opts, logger.NewWithWriter(&buf, true))
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+
+	tests := []struct {
+		remote string
+		xff    string
+		want   int
+	}{
+		{remote: "192.168.1.20:5000", want: http.StatusMultiStatus},
+		{remote: "10.0.0.5:5000", want: http.StatusMultiStatus},
+		{remote: "172.16.0.1:5000", want: http.StatusForbidden},
+		{remote: "10.0.0.1:5000", xff: "192.168.1.20", want: http.StatusMultiStatus},
+		{remote: "10.0.0.1:5000", xff: "172.16.0.1", want: http.StatusForbidden},
+		{remote: "172.16.0.1:5000", xff: "192.168.1.20", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("PROPFIND", "/", nil)
+		r.RemoteAddr = tt.remote
+		r.Header.Set("Depth", "0")
+		if tt.xff != "" {
+			r.Header.Set("X-Forwarded-For", tt.xff)
+		}
+		rec := httptest.NewRecorder()
+		srv.server.Handler.ServeHTTP(rec, r)
+		if rec.Code != tt.want {
+			t.Errorf("PROPFIND from %s (X-Forwarded-For %q) status = %d, want %d", tt.remote, tt.xff, rec.Code, tt.want)
+		}
+	}
+
+	if !strings.Contains(buf.String(), "denied: client 172.16.0.1 not in allowlist") {
+		t.Errorf("denial not logged:\n%s", buf.String())
+	}
+
+	// The allowlist also guards the server's own endpoints
+	r := httptest.NewRequest(http.MethodGet, "/health", nil)
+	r.RemoteAddr = "172.16.0.1:5000"
+	rec := httptest.NewRecorder()
+	srv.server.Handler.ServeHTTP(rec, r)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("GET /health from outside the allowlist status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestAllowlistEmptyAllowsAll(t *testing.T) {
+	srv := New(t.TempDir(), 18080, "0.0.0.0", nil)
+	srv.ready.Store(true)
+
+	r := httptest.NewRequest(http.MethodGet, "/health", nil)
+	r.RemoteAddr = "203.0.113.9:5000"
+	rec := httptest.NewRecorder()
+	srv.server.Handler.ServeHTTP(rec, r)
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET /health without allowlist status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestAllowlistInvalidCIDR(t *testing.T) {
+	for _, opts := range []Options{
+		{Folder: t.TempDir(), AllowedNets: []string{"192.168.1.0"}},
+		{Folder: t.TempDir(), AllowedNets: []string{"192.168.1.0/24"}, TrustedProxies: []string{"proxy"}},
+	} {
+		if _, err := NewWithOptions(opts, nil); err == nil {
+			t.Errorf("NewWithOptions(%v) should reject the invalid range", opts.AllowedNets)
+		}
+	}
+}
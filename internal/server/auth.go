@@ -0,0 +1,81 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// authRealm is the protection space announced in authentication challenges
+const authRealm = "gowebdavd"
+
+// Credentials maps user names to passwords
+type Credentials map[string]string
+
+// ParseCredentials parses "user:pass" entries into Credentials
+func ParseCredentials(entries []string) (Credentials, error) {
+	creds := make(Credentials, len(entries))
+	for _, entry := range entries {
+		user, pass, ok := strings.Cut(entry, ":")
+		if !ok || user == "" {
+			return nil, fmt.Errorf("invalid credentials %q: expected user:pass", entry)
+		}
+		creds[user] = pass
+	}
+	return creds, nil
+}
+
+// LoadCredentials reads "user:pass" lines from a credentials file. Blank lines
+// and lines starting with # are ignored.
+func LoadCredentials(path string) (Credentials, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open credentials file: %w", err)
+	}
+	defer f.Close()
+
+	var entries []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+	return ParseCredentials(entries)
+}
+
+// verify reports whether pass is the password of user, in constant time with
+// respect to the password and to whether the user exists
+func (c Credentials) verify(user, pass string) bool {
+	want, ok := c[user]
+	wantSum := sha256.Sum256([]byte(want))
+	gotSum := sha256.Sum256([]byte(pass))
+	match := subtle.ConstantTimeCompare(wantSum[:], gotSum[:]) == 1
+	return ok && match
+}
+
+// basicAuth requires HTTP Basic credentials matching creds and challenges
+// unauthenticated requests with 401
+func basicAuth(next http.Handler, creds Credentials) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || !creds.verify(user, pass) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="`+authRealm+`", charset="UTF-8"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
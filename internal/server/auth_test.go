@@ -0,0 +1,109 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newAuthServer(t *testing.T) *WebDAV {
+	t.Helper()
+
+	creds, err := ParseCredentials([]string{"alice:secret", "bob:pa:ss"})
+	if err != nil {
+		t.Fatalf("ParseCredentials() error = %v", err)
+	}
+	srv, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18080, Bind: "127.0.0.1", Credentials: creds}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	return srv
+}
+
+func TestParseCredentials(t *testing.T) {
+	creds, err := ParseCredentials([]string{"alice:secret", "bob:pa:ss"})
+	if err != nil {
+		t.Fatalf("ParseCredentials() error = %v", err)
+	}
+	if creds["alice"] != "secret" || creds["bob"] != "pa:ss" {
+		t.Errorf("ParseCredentials() = %v", creds)
+	}
+
+	for _, invalid := range []string{"nopassword", ":secret"} {
+		if _, err := ParseCredentials([]string{invalid}); err == nil {
+			t.Errorf("ParseCredentials(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestLoadCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users")
+	content := "# users\nalice:secret\n\nbob:pa:ss\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write credentials file: %v", err)
+	}
+
+	creds, err := LoadCredentials(path)
+	if err != nil {
+		t.Fatalf("LoadCredentials() error = %v", err)
+	}
+	if len(creds) != 2 || creds["alice"] != "secret" || creds["bob"] != "pa:ss" {
+		t.Errorf("LoadCredentials() = %v", creds)
+	}
+
+	if _, err := LoadCredentials(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("LoadCredentials() should fail for a missing file")
+	}
+}
+
+func TestBasicAuth(t *testing.T) {
+	h := newAuthServer(t).Handler()
+
+	tests := []struct {
+		name string
+		user string
+		pass string
+		auth bool
+		want int
+	}{
+		{name: "no credentials", want: http.StatusUnauthorized},
+		{name: "wrong password", user: "alice", pass: "wrong", auth: true, want: http.StatusUnauthorized},
+		{name: "unknown user", user: "mallory", pass: "secret", auth: true, want: http.StatusUnauthorized},
+		{name: "valid", user: "alice", pass: "secret", auth: true, want: http.StatusMultiStatus},
+		{name: "password with colon", user: "bob", pass: "pa:ss", auth: true, want: http.StatusMultiStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PROPFIND", "/", nil)
+			req.Header.Set("Depth", "0")
+			if tt.auth {
+				req.SetBasicAuth(tt.user, tt.pass)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusUnauthorized {
+				want := `Basic realm="gowebdavd", charset="UTF-8"`
+				if got := rec.Header().Get("WWW-Authenticate"); got != want {
+					t.Errorf("WWW-Authenticate = %q, want %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBasicAuthHealthUnauthenticated(t *testing.T) {
+	srv := newAuthServer(t)
+	srv.ready.Store(true)
+
+	rec := doRequest(srv.routes(), http.MethodGet, "/health", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET /health status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
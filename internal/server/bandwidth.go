@@ -0,0 +1,236 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// bandwidthChunk is the largest write passed on at once, so that concurrent
+// downloads take turns instead of one stalling behind another's large write
+const bandwidthChunk = 16 << 10
+
+// bandwidthBucket is a token bucket of bytes shared by every response. It
+// refills at rate bytes per second and holds at most one second of tokens.
+// Writers reserve tokens before sending and sleep off any debt, so waiting
+// writers are served in the order they arrived.
+type bandwidthBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+func newBandwidthBucket(rate int64) *bandwidthBucket {
+	return &bandwidthBucket{
+		rate:   float64(rate),
+		tokens: float64(rate),
+		last:   time.Now(),
+		now:    time.Now,
+	}
+}
+
+// reserve takes n tokens and returns how long the caller has to wait before
+// sending them
+func (b *bandwidthBucket) reserve(n int) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.tokens = math.Min(b.rate, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// wait blocks until n bytes may be sent or ctx is done
+func (b *bandwidthBucket) wait(ctx context.Context, n int) error {
+	if d := b.reserve(n); d > 0 {
+		return sleepContext(ctx, d)
+	}
+	return nil
+}
+
+// sleepContext sleeps for d unless ctx is done first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// waitAll blocks until n bytes may pass every bucket or ctx is done
+func waitAll(ctx context.Context, buckets []*bandwidthBucket, n int) error {
+	for _, b := range buckets {
+		if err := b.wait(ctx, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// minRate returns the lower of two rates, where 0 stands for no limit
+func minRate(a, b int64) int64 {
+	if a == 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// ParseRate parses a transfer rate such as "2MB/s" into bytes per second.
+// The "/s" suffix is optional; sizes are read as by ParseSize.
+func ParseRate(s string) (int64, error) {
+	value := strings.TrimSpace(s)
+	if lower := strings.ToLower(value); strings.HasSuffix(lower, "/s") {
+		value = value[:len(value)-len("/s")]
+	}
+	n, err := ParseSize(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rate: %q", s)
+	}
+	return n, nil
+}
+
+// rateBuckets throttle the two directions of a transfer separately, so that
+// uploads and downloads do not take each other's share
+type rateBuckets struct {
+	in, out *bandwidthBucket
+}
+
+func newRateBuckets(rate int64) rateBuckets {
+	return rateBuckets{in: newBandwidthBucket(rate), out: newBandwidthBucket(rate)}
+}
+
+// connRateKey stores the *connRate of a connection in its context
+type connRateKey struct{}
+
+// connRate holds the buckets of one connection. They are created by its
+// first throttled request and replaced when a reload changes the rate.
+type connRate struct {
+	mu      sync.Mutex
+	rate    int64
+	buckets rateBuckets
+}
+
+// connContext is the http.Server ConnContext hook giving every connection
+// its own connRate
+func connContext(ctx context.Context, _ net.Conn) context.Context {
+	return context.WithValue(ctx, connRateKey{}, &connRate{})
+}
+
+// get returns the buckets of the connection for rate
+func (c *connRate) get(rate int64) rateBuckets {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.rate != rate {
+		c.rate, c.buckets = rate, newRateBuckets(rate)
+	}
+	return c.buckets
+}
+
+// limitRate throttles request bodies to inRate and response bodies to
+// outRate bytes per second across all connections, and both to perConn bytes
+// per second for each connection; 0 disables a limit. Only the bodies are
+// paced, so Range responses and chunked transfers pass unchanged. Waits end
+// when the request context is done, which also happens when shutdown gives
+// up on a slow transfer and closes its connection.
+func limitRate(next http.Handler, inRate, outRate, perConn int64) http.Handler {
+	var shared rateBuckets
+	if inRate > 0 {
+		shared.in = newBandwidthBucket(inRate)
+	}
+	if outRate > 0 {
+		shared.out = newBandwidthBucket(outRate)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var in, out []*bandwidthBucket
+		if shared.in != nil {
+			in = append(in, shared.in)
+		}
+		if shared.out != nil {
+			out = append(out, shared.out)
+		}
+		if perConn > 0 {
+			c, ok := r.Context().Value(connRateKey{}).(*connRate)
+			if !ok {
+				// Served without a connection, e.g. by a test recorder
+				c = &connRate{}
+			}
+			b := c.get(perConn)
+			in, out = append(in, b.in), append(out, b.out)
+		}
+
+		if r.Body != nil && r.Body != http.NoBody {
+			r.Body = &bandwidthReader{ReadCloser: r.Body, buckets: in, ctx: r.Context()}
+		}
+		next.ServeHTTP(&bandwidthWriter{ResponseWriter: w, buckets: out, ctx: r.Context()}, r)
+	})
+}
+
+// bandwidthReader reads the request body in chunks, waiting for tokens after
+// each one
+type bandwidthReader struct {
+	io.ReadCloser
+	buckets []*bandwidthBucket
+	ctx     context.Context
+}
+
+func (r *bandwidthReader) Read(p []byte) (int, error) {
+	if len(p) > bandwidthChunk {
+		p = p[:bandwidthChunk]
+	}
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		if werr := waitAll(r.ctx, r.buckets, n); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+// bandwidthWriter writes the response body in chunks, waiting for tokens
+// before each one
+type bandwidthWriter struct {
+	http.ResponseWriter
+	buckets []*bandwidthBucket
+	ctx     context.Context
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer
+func (w *bandwidthWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *bandwidthWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p[:min(len(p), bandwidthChunk)]
+		if err := waitAll(w.ctx, w.buckets, len(chunk)); err != nil {
+			return written, err
+		}
+		n, err := w.ResponseWriter.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
@@ -0,0 +1,230 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBandwidthBucketReserve(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newBandwidthBucket(1000)
+	b.now = func() time.Time { return now }
+	b.last = now
+
+	if d := b.reserve(1000); d != 0 {
+		t.Errorf("reserve() within the burst waits %s, want 0", d)
+	}
+	if d := b.reserve(500); d != 500*time.Millisecond {
+		t.Errorf("reserve() beyond the burst waits %s, want 500ms", d)
+	}
+	// The second writer queues behind the first one's debt
+	if d := b.reserve(500); d != time.Second {
+		t.Errorf("queued reserve() waits %s, want 1s", d)
+	}
+
+	now = now.Add(10 * time.Second)
+	if d := b.reserve(1000); d != 0 {
+		t.Errorf("reserve() after refilling waits %s, want 0", d)
+	}
+	if b.tokens != 0 {
+		t.Errorf("tokens = %v, want refill capped at one second", b.tokens)
+	}
+}
+
+func TestBandwidthWaitCanceled(t *testing.T) {
+	b := newBandwidthBucket(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := b.wait(ctx, 100); err == nil {
+		t.Error("wait() should return when the context is done")
+	}
+}
+
+func TestBandwidthTotalConcurrentDownloads(t *testing.T) {
+	const rate = 512 << 10
+	const size = 512 << 10
+
+	dir := t.TempDir()
+	content := bytes.Repeat([]byte("x"), size)
+	for _, name := range []string{"a.bin", "b.bin"} {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+	srv, err := NewWithOptions(Options{Folder: dir, Port: 18080, Bind: "127.0.0.1", BandwidthTotal: rate}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	h := srv.Handler()
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for _, name := range []string{"/a.bin", "/b.bin"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := doRequest(h, http.MethodGet, name, "", nil)
+			if rec.Code != http.StatusOK || rec.Body.Len() != size {
+				t.Errorf("GET %s = %d with %d bytes, want 200 with %d", name, rec.Code, rec.Body.Len(), size)
+			}
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	// The bucket starts with one second of tokens, the remainder of both
+	// downloads has to share the rate
+	want := time.Duration(float64(2*size-rate) / rate * float64(time.Second))
+	if elapsed < want*9/10 {
+		t.Errorf("downloads took %s, combined throughput above the %d B/s limit (want at least %s)", elapsed, rate, want)
+	}
+	if elapsed > want*3 {
+		t.Errorf("downloads took %s, want about %s", elapsed, want)
+	}
+}
+
+func TestBandwidthTotalLeavesUploads(t *testing.T) {
+	const rate = 64 << 10
+
+	dir := t.TempDir()
+	srv, err := NewWithOptions(Options{Folder: dir, Port: 18080, Bind: "127.0.0.1", BandwidthTotal: rate}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+
+	start := time.Now()
+	rec := doRequest(srv.Handler(), http.MethodPut, "/upload.bin", string(bytes.Repeat([]byte("x"), 4*rate)), nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("PUT status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("upload took %s, want it unthrottled", elapsed)
+	}
+}
+
+func TestMinRate(t *testing.T) {
+	tests := []struct{ a, b, want int64 }{
+		{0, 0, 0},
+		{100, 0, 100},
+		{0, 100, 100},
+		{100, 50, 50},
+		{50, 100, 50},
+	}
+	for _, tt := range tests {
+		if got := minRate(tt.a, tt.b); got != tt.want {
+			t.Errorf("minRate(%d, %d) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"2MB/s", 2 << 20},
+		{"512kb/S", 512 << 10},
+		{"1000", 1000},
+		{"0", 0},
+	}
+	for _, tt := range tests {
+		got, err := ParseRate(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseRate(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", "/s", "fast", "-1MB/s"} {
+		if _, err := ParseRate(in); err == nil {
+			t.Errorf("ParseRate(%q) should fail", in)
+		}
+	}
+}
+
+func TestMaxRateUpload(t *testing.T) {
+	const rate = 256 << 10
+	const size = rate * 3 / 2
+
+	dir := t.TempDir()
+	srv, err := NewWithOptions(Options{Folder: dir, Port: 18080, Bind: "127.0.0.1", MaxRate: rate}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+
+	start := time.Now()
+	rec := doRequest(srv.Handler(), http.MethodPut, "/upload.bin", string(bytes.Repeat([]byte("x"), size)), nil)
+	elapsed := time.Since(start)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("PUT status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if fi, err := os.Stat(filepath.Join(dir, "upload.bin")); err != nil || fi.Size() != size {
+		t.Fatalf("uploaded file = %v, %v, want %d bytes", fi, err, size)
+	}
+	// One second of burst, the remaining half second is throttled
+	if want := 450 * time.Millisecond; elapsed < want {
+		t.Errorf("upload took %s, want at least %s", elapsed, want)
+	}
+}
+
+func TestMaxRatePerConnRange(t *testing.T) {
+	const rate = 64 << 10
+
+	dir := t.TempDir()
+	content := bytes.Repeat([]byte("0123456789"), 20000)
+	if err := os.WriteFile(filepath.Join(dir, "file.bin"), content, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	srv, err := NewWithOptions(Options{Folder: dir, Port: 18080, Bind: "127.0.0.1", MaxRatePerConn: rate}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+
+	start := time.Now()
+	rec := doRequest(srv.Handler(), http.MethodGet, "/file.bin", "", map[string]string{"Range": "bytes=1000-100999"})
+	elapsed := time.Since(start)
+	if rec.Code != http.StatusPartialContent {
+		t.Fatalf("GET status = %d, want %d", rec.Code, http.StatusPartialContent)
+	}
+	if !bytes.Equal(rec.Body.Bytes(), content[1000:101000]) {
+		t.Errorf("GET returned %d bytes, want the requested range", rec.Body.Len())
+	}
+	if want := 400 * time.Millisecond; elapsed < want {
+		t.Errorf("download took %s, want at least %s", elapsed, want)
+	}
+}
+
+func TestMaxRateShutdown(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "big.bin"), bytes.Repeat([]byte("x"), 1<<20), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	srv, err := NewWithOptions(Options{Folder: dir, Bind: "127.0.0.1", MaxRate: 16 << 10, ShutdownTimeout: 100 * time.Millisecond}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	url, done := startTestServer(t, srv)
+
+	resp, err := http.Get(url + "/big.bin")
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	start := time.Now()
+	srv.shutdown()
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("shutdown() took %s with a throttled download", elapsed)
+	}
+	waitServe(t, done)
+}
+
+func TestNegativeBandwidthTotal(t *testing.T) {
+	if _, err := NewWithOptions(Options{Folder: t.TempDir(), BandwidthTotal: -1}, nil); err == nil {
+		t.Error("NewWithOptions() should reject a negative bandwidth limit")
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxPropfindBody bounds the PROPFIND bodies read for batch requests
+const maxPropfindBody = 1 << 20
+
+// batchPropfind answers a PROPFIND whose propfind element lists the wanted
+// resources in DAV:href children with one multistatus covering exactly those
+// resources. Each href is queried with Depth 0 through next, so every other
+// option applies to it as to a single PROPFIND. Requests without hrefs pass
+// unchanged.
+func batchPropfind(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PROPFIND" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxPropfindBody))
+		if err != nil {
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		hrefs, rest := splitHrefs(body)
+		if len(hrefs) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var b bytes.Buffer
+		b.WriteString(xml.Header)
+		b.WriteString(`<D:multistatus xmlns:D="DAV:">` + "\n")
+		for _, href := range hrefs {
+			target, err := r.URL.Parse(href)
+			if err != nil || (target.Host != "" && target.Host != r.Host) {
+				writeHrefStatus(&b, href, http.StatusBadRequest)
+				continue
+			}
+
+			sub := r.Clone(r.Context())
+			sub.URL.Path, sub.URL.RawPath = target.Path, target.RawPath
+			sub.RequestURI = target.RequestURI()
+			sub.Header.Set("Depth", "0")
+			sub.Body = io.NopCloser(bytes.NewReader(rest))
+			sub.ContentLength = int64(len(rest))
+			rec := newBufferedResponse()
+			next.ServeHTTP(rec, sub)
+			if rec.status != http.StatusMultiStatus {
+				writeHrefStatus(&b, target.EscapedPath(), rec.status)
+				continue
+			}
+			for _, resp := range responseElements(rec.body.Bytes()) {
+				b.Write(resp)
+				b.WriteByte('\n')
+			}
+		}
+		b.WriteString("</D:multistatus>\n")
+
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.WriteHeader(http.StatusMultiStatus)
+		w.Write(b.Bytes())
+	})
+}
+
+// writeHrefStatus writes a response element giving href the status code
+func writeHrefStatus(b *bytes.Buffer, href string, code int) {
+	b.WriteString("<D:response><D:href>")
+	xml.EscapeText(b, []byte(href))
+	fmt.Fprintf(b, "</D:href><D:status>HTTP/1.1 %d %s</D:status></D:response>\n", code, http.StatusText(code))
+}
+
+// splitHrefs returns the DAV:href children of the propfind element in doc,
+// and doc with them cut out. A propfind holding nothing but hrefs asks for
+// all properties and leaves an empty body. It returns no hrefs if doc cannot
+// be parsed, so the WebDAV handler rejects it itself.
+func splitHrefs(doc []byte) ([]string, []byte) {
+	d := xml.NewDecoder(bytes.NewReader(doc))
+	var hrefs []string
+	var out bytes.Buffer
+	var copied, start int64
+	var text strings.Builder
+	depth := 0
+	inHref, others := false, false
+
+	for {
+		offset := d.InputOffset()
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, doc
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 2 && t.Name.Space == "DAV:" && t.Name.Local == "href" {
+				inHref = true
+				start = offset
+				text.Reset()
+			} else if depth == 2 {
+				others = true
+			}
+		case xml.CharData:
+			if inHref {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			if inHref && depth == 2 {
+				inHref = false
+				hrefs = append(hrefs, strings.TrimSpace(text.String()))
+				out.Write(doc[copied:start])
+				copied = d.InputOffset()
+			}
+			depth--
+		}
+	}
+
+	if !others {
+		return hrefs, nil
+	}
+	out.Write(doc[copied:])
+	return hrefs, out.Bytes()
+}
+
+// responseElements returns the DAV:response elements of a multistatus
+// document as they appear in it
+func responseElements(doc []byte) [][]byte {
+	d := xml.NewDecoder(bytes.NewReader(doc))
+	var elems [][]byte
+	var start int64
+	depth := 0
+
+	for {
+		offset := d.InputOffset()
+		tok, err := d.Token()
+		if err != nil {
+			return elems
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 2 && t.Name.Space == "DAV:" && t.Name.Local == "response" {
+				start = offset
+			}
+		case xml.EndElement:
+			if depth == 2 && t.Name.Space == "DAV:" && t.Name.Local == "response" {
+				elems = append(elems, doc[start:d.InputOffset()])
+			}
+			depth--
+		}
+	}
+}
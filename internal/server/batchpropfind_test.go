@@ -0,0 +1,79 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBatchPropfind(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "c.txt", "other.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv, err := NewWithOptions(Options{Folder: dir, Port: 18080, Bind: "127.0.0.1", BatchPropfind: true}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	h := srv.Handler()
+
+	body := `<?xml version="1.0" encoding="utf-8"?>
+<D:propfind xmlns:D="DAV:">
+  <D:prop><D:getcontentlength/></D:prop>
+  <D:href>/a.txt</D:href>
+  <D:href>b.txt</D:href>
+  <D:href>http://example.com/c.txt</D:href>
+</D:propfind>`
+	rec := doRequest(h, "PROPFIND", "/", body, map[string]string{"Depth": "1"})
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("PROPFIND status = %d, want %d: %s", rec.Code, http.StatusMultiStatus, rec.Body.String())
+	}
+	got := rec.Body.String()
+	if n := strings.Count(got, "<D:response>"); n != 3 {
+		t.Errorf("multistatus has %d responses, want 3: %s", n, got)
+	}
+	for _, href := range []string{"/a.txt", "/b.txt", "/c.txt"} {
+		if !strings.Contains(got, "<D:href>"+href+"</D:href>") {
+			t.Errorf("multistatus lacks %s: %s", href, got)
+		}
+	}
+	if strings.Contains(got, "other.txt") || strings.Contains(got, "<D:href>/</D:href>") {
+		t.Errorf("multistatus covers resources that were not asked for: %s", got)
+	}
+	if !strings.Contains(got, "<D:getcontentlength>5</D:getcontentlength>") {
+		t.Errorf("multistatus lacks the requested property: %s", got)
+	}
+}
+
+func TestBatchPropfindMissing(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644)
+	srv, err := NewWithOptions(Options{Folder: dir, Port: 18080, Bind: "127.0.0.1", BatchPropfind: true}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+
+	// A propfind holding only hrefs asks for all properties
+	body := `<D:propfind xmlns:D="DAV:"><D:href>/a.txt</D:href><D:href>/missing.txt</D:href></D:propfind>`
+	rec := doRequest(srv.Handler(), "PROPFIND", "/", body, nil)
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("PROPFIND status = %d, want %d", rec.Code, http.StatusMultiStatus)
+	}
+	got := rec.Body.String()
+	if !strings.Contains(got, "<D:getlastmodified>") {
+		t.Errorf("multistatus lacks all properties of /a.txt: %s", got)
+	}
+	if !strings.Contains(got, "<D:href>/missing.txt</D:href><D:status>HTTP/1.1 404 Not Found</D:status>") {
+		t.Errorf("multistatus lacks 404 for /missing.txt: %s", got)
+	}
+
+	// Without hrefs the request is an ordinary PROPFIND
+	rec = doRequest(srv.Handler(), "PROPFIND", "/", "", map[string]string{"Depth": "1"})
+	if rec.Code != http.StatusMultiStatus || !strings.Contains(rec.Body.String(), "a.txt") {
+		t.Errorf("plain PROPFIND status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+
+	"golang.org/x/net/webdav"
+)
+
+// sniffLen is the number of bytes used to detect the content type
+const sniffLen = 512
+
+// bufferedGet serves plain GET requests for regular files itself, streaming the
+// body with a buffer of the given size instead of the handler's default.
+// Range and conditional requests are left to the WebDAV handler, which
+// implements them through http.ServeContent.
+func bufferedGet(next http.Handler, fs webdav.FileSystem, size int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || hasRangeOrCondition(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		f, err := fs.OpenFile(r.Context(), r.URL.Path, 0, 0)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		defer f.Close()
+
+		fi, err := f.Stat()
+		if err != nil || !fi.Mode().IsRegular() {
+			next.ServeHTTP(w, r)
+			return
+		}
+		etag, err := fileETag(r.Context(), fi)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctype := mime.TypeByExtension(path.Ext(fi.Name()))
+		if ctype == "" {
+			head := make([]byte, sniffLen)
+			n, _ := io.ReadFull(f, head)
+			ctype = http.DetectContentType(head[:n])
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				http.Error(w, "failed to read file", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		h := w.Header()
+		h.Set("Content-Type", ctype)
+		h.Set("Content-Length", strconv.FormatInt(fi.Size(), 10))
+		// As http.ServeContent, which answers the range requests
+		h.Set("Accept-Ranges", "bytes")
+		h.Set("ETag", etag)
+		h.Set("Last-Modified", fi.ModTime().UTC().Format(http.TimeFormat))
+		w.WriteHeader(http.StatusOK)
+
+		io.CopyBuffer(w, readerOnly{f}, make([]byte, size))
+	})
+}
+
+// hasRangeOrCondition reports whether r needs http.ServeContent semantics
+func hasRangeOrCondition(r *http.Request) bool {
+	for _, h := range []string{"Range", "If-Match", "If-None-Match", "If-Modified-Since", "If-Unmodified-Since", "If-Range"} {
+		if r.Header.Get(h) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// readerOnly hides WriterTo on the file so io.CopyBuffer uses the buffer
+type readerOnly struct {
+	io.Reader
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"bytes"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// newLargeFileServer serves a directory containing a random file of size bytes
+func newLargeFileServer(tb testing.TB, size, bufferSize int) (http.Handler, []byte) {
+	tb.Helper()
+
+	tmpDir := tb.TempDir()
+	content := make([]byte, size)
+	rand.New(rand.NewSource(1)).Read(content)
+	if err := os.WriteFile(filepath.Join(tmpDir, "large.bin"), content, 0644); err != nil {
+		tb.Fatalf("Failed to create test file: %v", err)
+	}
+
+	srv, err := NewWithOptions(Options{Folder: tmpDir, Port: 18080, Bind: "127.0.0.1", ResponseBufferSize: bufferSize}, nil)
+	if err != nil {
+		tb.Fatalf("NewWithOptions() error = %v", err)
+	}
+	return srv.Handler(), content
+}
+
+func TestBufferedGet(t *testing.T) {
+	for _, bufferSize := range []int{4 << 10, 1 << 20} {
+		t.Run(strconv.Itoa(bufferSize), func(t *testing.T) {
+			h, content := newLargeFileServer(t, 4<<20, bufferSize)
+
+			rec := doRequest(h, http.MethodGet, "/large.bin", "", nil)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("GET status = %d, want %d", rec.Code, http.StatusOK)
+			}
+			if !bytes.Equal(rec.Body.Bytes(), content) {
+				t.Error("GET body differs from file content")
+			}
+			if got := rec.Header().Get("Content-Length"); got != strconv.Itoa(len(content)) {
+				t.Errorf("Content-Length = %s, want %d", got, len(content))
+			}
+			if rec.Header().Get("ETag") == "" {
+				t.Error("GET should set ETag")
+			}
+		})
+	}
+}
+
+func TestBufferedGetFallsThrough(t *testing.T) {
+	h, content := newLargeFileServer(t, 1<<10, 4<<10)
+
+	rec := doRequest(h, http.MethodGet, "/large.bin", "", map[string]string{"Range": "bytes=0-9"})
+	if rec.Code != http.StatusPartialContent {
+		t.Fatalf("Range GET status = %d, want %d", rec.Code, http.StatusPartialContent)
+	}
+	if !bytes.Equal(rec.Body.Bytes(), content[:10]) {
+		t.Error("Range GET body differs from file content")
+	}
+
+	rec = doRequest(h, http.MethodGet, "/missing.bin", "", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET missing status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestNewWithOptions_NegativeBufferSize(t *testing.T) {
+	_, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18080, Bind: "127.0.0.1", ResponseBufferSize: -1}, nil)
+	if err == nil {
+		t.Error("NewWithOptions() should reject a negative buffer size")
+	}
+}
+
+func BenchmarkBufferedGet(b *testing.B) {
+	for _, bufferSize := range []int{4 << 10, 1 << 20} {
+		b.Run(strconv.Itoa(bufferSize), func(b *testing.B) {
+			h, content := newLargeFileServer(b, 16<<20, bufferSize)
+			b.SetBytes(int64(len(content)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				req := httptest.NewRequest(http.MethodGet, "/large.bin", nil)
+				h.ServeHTTP(discardRecorder{httptest.NewRecorder()}, req)
+			}
+		})
+	}
+}
+
+// discardRecorder records headers but drops the body
+type discardRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (d discardRecorder) Write(b []byte) (int, error) {
+	return len(b), nil
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/webdav"
+)
+
+// caseInsensitiveFS resolves every name to an existing entry ignoring case
+// before passing it on, so clients on case-insensitive platforms find
+// "File.txt" when asking for "file.txt". Exact matches always win; otherwise
+// the first matching entry in name order is used. Names without any match are
+// passed on unchanged, so new files keep the requested spelling.
+type caseInsensitiveFS struct {
+	webdav.FileSystem
+}
+
+// resolve returns the real name of the entry matching name ignoring case
+func (fs caseInsensitiveFS) resolve(ctx context.Context, name string) string {
+	name = path.Clean("/" + name)
+	if name == "/" {
+		return name
+	}
+	if _, err := fs.FileSystem.Stat(ctx, name); err == nil {
+		return name
+	}
+
+	dir, base := path.Split(name)
+	dir = fs.resolve(ctx, dir)
+	f, err := fs.FileSystem.OpenFile(ctx, dir, os.O_RDONLY, 0)
+	if err != nil {
+		return path.Join(dir, base)
+	}
+	defer f.Close()
+
+	entries, err := f.Readdir(-1)
+	if err != nil {
+		return path.Join(dir, base)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	for _, e := range entries {
+		if strings.EqualFold(e.Name(), base) {
+			return path.Join(dir, e.Name())
+		}
+	}
+	return path.Join(dir, base)
+}
+
+func (fs caseInsensitiveFS) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	return fs.FileSystem.Mkdir(ctx, fs.resolve(ctx, name), perm)
+}
+
+func (fs caseInsensitiveFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	return fs.FileSystem.OpenFile(ctx, fs.resolve(ctx, name), flag, perm)
+}
+
+func (fs caseInsensitiveFS) RemoveAll(ctx context.Context, name string) error {
+	return fs.FileSystem.RemoveAll(ctx, fs.resolve(ctx, name))
+}
+
+func (fs caseInsensitiveFS) Rename(ctx context.Context, oldName, newName string) error {
+	return fs.FileSystem.Rename(ctx, fs.resolve(ctx, oldName), fs.resolve(ctx, newName))
+}
+
+func (fs caseInsensitiveFS) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	return fs.FileSystem.Stat(ctx, fs.resolve(ctx, name))
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newCaseTestDir(t *testing.T) string {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, "Docs"), 0755); err != nil {
+		t.Fatalf("Failed to create test dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "file.txt"), []byte("lower"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "Docs", "Report.txt"), []byte("report"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	return tmpDir
+}
+
+func TestCaseInsensitiveLookup(t *testing.T) {
+	tmpDir := newCaseTestDir(t)
+	srv, err := NewWithOptions(Options{Folder: tmpDir, Port: 18080, Bind: "127.0.0.1", CaseInsensitive: true}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	h := srv.Handler()
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{target: "/FILE.TXT", want: "lower"},
+		{target: "/file.txt", want: "lower"},
+		{target: "/docs/REPORT.TXT", want: "report"},
+	}
+	for _, tt := range tests {
+		rec := doRequest(h, http.MethodGet, tt.target, "", nil)
+		if rec.Code != http.StatusOK || rec.Body.String() != tt.want {
+			t.Errorf("GET %s = %d %q, want 200 %q", tt.target, rec.Code, rec.Body.String(), tt.want)
+		}
+	}
+
+	rec := doRequest(h, http.MethodPut, "/File.Txt", "updated", nil)
+	if rec.Code != http.StatusNoContent && rec.Code != http.StatusCreated {
+		t.Fatalf("PUT /File.Txt status = %d, want success", rec.Code)
+	}
+	data, err := os.ReadFile(filepath.Join(tmpDir, "file.txt"))
+	if err != nil || string(data) != "updated" {
+		t.Errorf("PUT should overwrite the existing file.txt, got %q, %v", data, err)
+	}
+
+	rec = doRequest(h, http.MethodPut, "/DOCS/New.txt", "new", nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("PUT /DOCS/New.txt status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "Docs", "New.txt")); err != nil {
+		t.Errorf("new file should be created in the existing Docs directory with its requested name: %v", err)
+	}
+}
+
+func TestCaseSensitiveByDefault(t *testing.T) {
+	tmpDir := newCaseTestDir(t)
+	if _, err := os.Stat(filepath.Join(tmpDir, "FILE.TXT")); err == nil {
+		t.Skip("file system is case-insensitive")
+	}
+	srv := New(tmpDir, 18080, "127.0.0.1", nil)
+
+	rec := doRequest(srv.Handler(), http.MethodGet, "/FILE.TXT", "", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET /FILE.TXT status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
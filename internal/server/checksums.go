@@ -0,0 +1,166 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/webdav"
+)
+
+// digestAlgorithm is a digest algorithm of RFC 3230, named as in its registry
+type digestAlgorithm struct {
+	name string
+	new  func() hash.Hash
+}
+
+// digestAlgorithms lists the supported algorithms, preferred first
+var digestAlgorithms = []digestAlgorithm{
+	{"SHA-256", sha256.New},
+	{"SHA-512", sha512.New},
+}
+
+// lookupDigest returns the algorithm called name, compared without case and
+// dashes so that both sha256 and SHA-256 are accepted
+func lookupDigest(name string) (digestAlgorithm, bool) {
+	key := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "-", "")
+	for _, a := range digestAlgorithms {
+		if strings.ReplaceAll(strings.ToLower(a.name), "-", "") == key {
+			return a, true
+		}
+	}
+	return digestAlgorithm{}, false
+}
+
+// wantedDigest returns the supported algorithm a Want-Digest header value
+// prefers, the one with the highest q-value, or false if it names none
+func wantedDigest(value string) (digestAlgorithm, bool) {
+	var best digestAlgorithm
+	bestQ := 0.0
+	for _, part := range strings.Split(value, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		a, ok := lookupDigest(name)
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > bestQ {
+			best, bestQ = a, q
+		}
+	}
+	return best, bestQ > 0
+}
+
+// checksums answers GET and HEAD requests for a file that ask for its
+// digest, with a checksum query parameter or a Want-Digest header, with an
+// empty 204 response carrying the digest in a Digest header. Digests are
+// computed on the first request and cached until the file changes.
+func checksums(next http.Handler, fs webdav.FileSystem) http.Handler {
+	cache := newETagCache(etagCacheSize)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		var alg digestAlgorithm
+		query := r.URL.Query().Has("checksum")
+		if query {
+			var ok bool
+			if alg, ok = lookupDigest(r.URL.Query().Get("checksum")); !ok {
+				http.Error(w, "unsupported checksum algorithm", http.StatusBadRequest)
+				return
+			}
+		} else if want := r.Header.Get("Want-Digest"); want != "" {
+			var ok bool
+			if alg, ok = wantedDigest(want); !ok {
+				// A client may not insist on a digest, serve the file
+				next.ServeHTTP(w, r)
+				return
+			}
+		} else {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		f, err := fs.OpenFile(r.Context(), r.URL.Path, 0, 0)
+		if err != nil {
+			// Let the WebDAV handler report the error
+			next.ServeHTTP(w, r)
+			return
+		}
+		defer f.Close()
+		fi, err := f.Stat()
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !fi.Mode().IsRegular() {
+			if query {
+				http.Error(w, "checksums are only available for files", http.StatusBadRequest)
+			} else {
+				next.ServeHTTP(w, r)
+			}
+			return
+		}
+
+		key := alg.name + " " + r.URL.Path
+		sum, ok := cache.get(key, fi)
+		if !ok {
+			if sum, err = fileDigest(r.Context(), f, alg); err != nil {
+				recordError(r, err)
+				http.Error(w, "failed to read file", http.StatusInternalServerError)
+				return
+			}
+			// A file changed while hashing is left out, its digest may mix contents
+			if now, err := f.Stat(); err == nil && now.ModTime().Equal(fi.ModTime()) && now.Size() == fi.Size() {
+				cache.put(key, fi, sum)
+			}
+		}
+
+		h := w.Header()
+		h.Set("Digest", alg.name+"="+sum)
+		if etag, err := fileETag(r.Context(), fi); err == nil {
+			h.Set("ETag", etag)
+		}
+		h.Set("Last-Modified", fi.ModTime().UTC().Format(http.TimeFormat))
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// fileDigest returns the base64 digest of the content of f with alg
+func fileDigest(ctx context.Context, f io.Reader, alg digestAlgorithm) (string, error) {
+	h := alg.new()
+	if _, err := io.Copy(h, contextReader{ctx: ctx, r: f}); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// contextReader stops reading once ctx is done, so hashing a large file
+// ends with the request
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+func TestChecksums(t *testing.T) {
+	dir := t.TempDir()
+	content := []byte("hello checksums")
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	h := davHandler(webdav.Dir(dir), "", webdav.NewMemLS(), Options{Checksums: true}, nil)
+
+	sum256 := sha256.Sum256(content)
+	sum512 := sha512.Sum512(content)
+	want256 := "SHA-256=" + base64.StdEncoding.EncodeToString(sum256[:])
+	want512 := "SHA-512=" + base64.StdEncoding.EncodeToString(sum512[:])
+
+	tests := []struct {
+		name    string
+		method  string
+		target  string
+		headers map[string]string
+		code    int
+		digest  string
+	}{
+		{"query", http.MethodGet, "/file.txt?checksum=sha256", nil, http.StatusNoContent, want256},
+		{"query dashed", http.MethodHead, "/file.txt?checksum=SHA-512", nil, http.StatusNoContent, want512},
+		{"want-digest", http.MethodGet, "/file.txt", map[string]string{"Want-Digest": "sha-256;q=0.3, SHA-512;q=1, md5"}, http.StatusNoContent, want512},
+		{"want-digest excluded", http.MethodGet, "/file.txt", map[string]string{"Want-Digest": "sha-512;q=0, sha-256"}, http.StatusNoContent, want256},
+		{"want-digest unsupported", http.MethodGet, "/file.txt", map[string]string{"Want-Digest": "md5"}, http.StatusOK, ""},
+		{"plain get", http.MethodGet, "/file.txt", nil, http.StatusOK, ""},
+		{"unsupported", http.MethodGet, "/file.txt?checksum=md5", nil, http.StatusBadRequest, ""},
+		{"collection", http.MethodGet, "/sub/?checksum=sha256", nil, http.StatusBadRequest, ""},
+		{"missing", http.MethodGet, "/missing.txt?checksum=sha256", nil, http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(h, tt.method, tt.target, "", tt.headers)
+			if rec.Code != tt.code {
+				t.Fatalf("%s %s status = %d, want %d", tt.method, tt.target, rec.Code, tt.code)
+			}
+			if got := rec.Header().Get("Digest"); got != tt.digest {
+				t.Errorf("Digest = %q, want %q", got, tt.digest)
+			}
+			if tt.digest != "" && rec.Body.Len() != 0 {
+				t.Errorf("body = %q, want none", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestChecksumsCache(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "file.txt")
+	if err := os.WriteFile(name, []byte("first"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	h := davHandler(webdav.Dir(dir), "", webdav.NewMemLS(), Options{Checksums: true}, nil)
+	digest := func() string {
+		return doRequest(h, http.MethodGet, "/file.txt?checksum=sha256", "", nil).Header().Get("Digest")
+	}
+
+	first := digest()
+	if first == "" || digest() != first {
+		t.Fatalf("Digest = %q, want the same digest twice", first)
+	}
+
+	// A change of content and modification time invalidates the cached digest
+	if err := os.WriteFile(name, []byte("second"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(name, later, later); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte("second"))
+	if got, want := digest(), "SHA-256="+base64.StdEncoding.EncodeToString(sum[:]); got != want {
+		t.Errorf("Digest after change = %q, want %q", got, want)
+	}
+}
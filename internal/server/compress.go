@@ -0,0 +1,158 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strings"
+)
+
+// DefaultGzipMinSize is the smallest response body compressed by default
+const DefaultGzipMinSize = 1024
+
+// incompressibleTypes are content type prefixes that are already compressed
+var incompressibleTypes = []string{
+	"image/jpeg", "image/png", "image/gif", "image/webp", "image/avif",
+	"video/", "audio/",
+	"application/zip", "application/gzip", "application/x-gzip",
+	"application/x-bzip2", "application/x-xz", "application/zstd",
+	"application/x-7z-compressed", "application/vnd.rar", "application/x-rar-compressed",
+	"font/woff", "font/woff2",
+}
+
+// compress gzips response bodies of at least minSize bytes for clients
+// accepting gzip. Range requests, HEAD, bodiless statuses, responses that
+// already carry a Content-Encoding and compressed media types are passed
+// through unchanged.
+func compress(next http.Handler, minSize int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if r.Method == http.MethodHead || r.Header.Get("Range") != "" || !acceptsGzip(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		gw := &gzipWriter{ResponseWriter: w, minSize: minSize, status: http.StatusOK}
+		next.ServeHTTP(gw, r)
+		gw.finish()
+	})
+}
+
+// acceptsGzip reports whether the Accept-Encoding header allows gzip
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			return strings.ReplaceAll(params, " ", "") != "q=0"
+		}
+	}
+	return false
+}
+
+// gzipWriter buffers the start of the body until it knows whether the
+// response is large enough to compress. The status code is passed on
+// unchanged, so wrapping writers such as the request logger record it.
+type gzipWriter struct {
+	http.ResponseWriter
+	minSize     int
+	status      int
+	wroteHeader bool
+	decided     bool
+	buf         bytes.Buffer
+	gz          *gzip.Writer
+}
+
+func (w *gzipWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = code
+	if !bodyAllowed(code) {
+		w.decide(false)
+	}
+}
+
+func (w *gzipWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.decided {
+		if w.gz != nil {
+			return w.gz.Write(p)
+		}
+		return w.ResponseWriter.Write(p)
+	}
+
+	w.buf.Write(p)
+	if w.buf.Len() >= w.minSize {
+		if err := w.decide(w.compressible()); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// compressible reports whether the response headers allow compression
+func (w *gzipWriter) compressible() bool {
+	h := w.Header()
+	if h.Get("Content-Encoding") != "" || !bodyAllowed(w.status) || w.status == http.StatusPartialContent {
+		return false
+	}
+	ct := strings.ToLower(h.Get("Content-Type"))
+	for _, prefix := range incompressibleTypes {
+		if strings.HasPrefix(ct, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// decide sends the header, switching to gzip when compressed is set, and
+// flushes the buffered body
+func (w *gzipWriter) decide(compressed bool) error {
+	w.decided = true
+	if compressed {
+		h := w.Header()
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		// The compressed representation is not byte-identical to the file
+		if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			h.Set("ETag", "W/"+etag)
+		}
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	var err error
+	if w.gz != nil {
+		_, err = w.gz.Write(w.buf.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf.Bytes())
+	}
+	w.buf.Reset()
+	return err
+}
+
+// finish sends a response too small to compress and closes the gzip stream
+func (w *gzipWriter) finish() {
+	if !w.wroteHeader {
+		return
+	}
+	if !w.decided {
+		w.decide(false)
+	}
+	if w.gz != nil {
+		w.gz.Close()
+	}
+}
+
+// bodyAllowed reports whether a response with status code may have a body
+func bodyAllowed(code int) bool {
+	return code >= 200 && code != http.StatusNoContent && code != http.StatusNotModified
+}
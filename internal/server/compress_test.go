@@ -0,0 +1,143 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gowebdavd/internal/logger"
+)
+
+func newCompressServer(t *testing.T, log *logger.Logger) http.Handler {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"notes.txt":   strings.Repeat("compress me ", 500),
+		"tiny.txt":    "tiny",
+		"photo.jpg":   strings.Repeat("\xff\xd8jpeg", 1000),
+		"archive.zip": strings.Repeat("PK", 2000),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+	for i := 0; i < 50; i++ {
+		if err := os.WriteFile(filepath.Join(tmpDir, fmt.Sprintf("file%02d.txt", i)), nil, 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	srv, err := NewWithOptions(Options{Folder: tmpDir, Port: 18080, Bind: "127.0.0.1", Gzip: true}, log)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	return srv.Handler()
+}
+
+func gunzip(t *testing.T, body []byte) string {
+	t.Helper()
+
+	zr, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("reading gzip stream: %v", err)
+	}
+	return string(data)
+}
+
+func TestCompressTextResponses(t *testing.T) {
+	h := newCompressServer(t, nil)
+	gz := map[string]string{"Accept-Encoding": "gzip, deflate"}
+
+	rec := doRequest(h, http.MethodGet, "/notes.txt", "", gz)
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("GET text Content-Encoding = %q, want gzip", rec.Header().Get("Content-Encoding"))
+	}
+	if got := gunzip(t, rec.Body.Bytes()); got != strings.Repeat("compress me ", 500) {
+		t.Errorf("decompressed body has %d bytes, want the file content", len(got))
+	}
+	if rec.Header().Get("Content-Length") != "" {
+		t.Error("compressed response should not carry the uncompressed Content-Length")
+	}
+	if etag := rec.Header().Get("ETag"); !strings.HasPrefix(etag, "W/") {
+		t.Errorf("compressed ETag = %q, want a weak validator", etag)
+	}
+
+	rec = doRequest(h, "PROPFIND", "/", "", map[string]string{"Accept-Encoding": "gzip", "Depth": "1"})
+	if rec.Code != http.StatusMultiStatus || rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("PROPFIND = %d with Content-Encoding %q, want compressed 207", rec.Code, rec.Header().Get("Content-Encoding"))
+	}
+	if !strings.Contains(gunzip(t, rec.Body.Bytes()), "file49.txt") {
+		t.Error("decompressed PROPFIND response is incomplete")
+	}
+}
+
+func TestCompressSkips(t *testing.T) {
+	h := newCompressServer(t, nil)
+
+	tests := []struct {
+		name    string
+		method  string
+		target  string
+		headers map[string]string
+	}{
+		{name: "no Accept-Encoding", method: http.MethodGet, target: "/notes.txt"},
+		{name: "gzip refused", method: http.MethodGet, target: "/notes.txt", headers: map[string]string{"Accept-Encoding": "gzip;q=0"}},
+		{name: "below threshold", method: http.MethodGet, target: "/tiny.txt", headers: map[string]string{"Accept-Encoding": "gzip"}},
+		{name: "jpeg", method: http.MethodGet, target: "/photo.jpg", headers: map[string]string{"Accept-Encoding": "gzip"}},
+		{name: "zip", method: http.MethodGet, target: "/archive.zip", headers: map[string]string{"Accept-Encoding": "gzip"}},
+		{name: "range", method: http.MethodGet, target: "/notes.txt", headers: map[string]string{"Accept-Encoding": "gzip", "Range": "bytes=0-2047"}},
+		{name: "HEAD", method: http.MethodHead, target: "/notes.txt", headers: map[string]string{"Accept-Encoding": "gzip"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(h, tt.method, tt.target, "", tt.headers)
+			if rec.Code >= 300 {
+				t.Fatalf("%s %s status = %d", tt.method, tt.target, rec.Code)
+			}
+			if enc := rec.Header().Get("Content-Encoding"); enc != "" {
+				t.Errorf("Content-Encoding = %q, want none", enc)
+			}
+		})
+	}
+}
+
+func TestCompressNotModified(t *testing.T) {
+	h := newCompressServer(t, nil)
+
+	etag := doRequest(h, http.MethodGet, "/notes.txt", "", nil).Header().Get("ETag")
+	rec := doRequest(h, http.MethodGet, "/notes.txt", "", map[string]string{"Accept-Encoding": "gzip", "If-None-Match": etag})
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("conditional GET status = %d, want %d", rec.Code, http.StatusNotModified)
+	}
+	if rec.Header().Get("Content-Encoding") != "" || rec.Body.Len() != 0 {
+		t.Errorf("304 response should be sent unchanged, got encoding %q and %d body bytes", rec.Header().Get("Content-Encoding"), rec.Body.Len())
+	}
+}
+
+func TestCompressKeepsLoggedStatus(t *testing.T) {
+	var buf bytes.Buffer
+	h := newCompressServer(t, logger.NewWithWriter(&buf, true))
+	gz := map[string]string{"Accept-Encoding": "gzip"}
+
+	doRequest(h, http.MethodGet, "/missing.txt", "", gz)
+	doRequest(h, "PROPFIND", "/", "", map[string]string{"Accept-Encoding": "gzip", "Depth": "1"})
+	doRequest(h, http.MethodPut, "/new.txt", "data", gz)
+
+	for _, want := range []string{"GET /missing.txt 404", "PROPFIND / 207", "PUT /new.txt 201"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("log missing %q:\n%s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"net"
+	"net/http"
+	"sync"
+)
+
+// connLimiter caps the number of open connections per client IP. It counts
+// connections at the TCP level, so idle keep-alive connections count too and
+// a client cannot exhaust file descriptors by opening many and sending
+// nothing.
+type connLimiter struct {
+	max int
+
+	mu    sync.Mutex
+	open  map[string]int
+	conns map[net.Conn]string
+}
+
+func newConnLimiter(max int) *connLimiter {
+	return &connLimiter{
+		max:   max,
+		open:  make(map[string]int),
+		conns: make(map[net.Conn]string),
+	}
+}
+
+// connState is an http.Server ConnState hook. A new connection from an IP
+// that already has max open connections is closed before any request is read.
+func (l *connLimiter) connState(conn net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateNew:
+		ip := connIP(conn)
+		l.mu.Lock()
+		if l.open[ip] >= l.max {
+			l.mu.Unlock()
+			conn.Close()
+			return
+		}
+		l.open[ip]++
+		l.conns[conn] = ip
+		l.mu.Unlock()
+	case http.StateHijacked, http.StateClosed:
+		l.mu.Lock()
+		if ip, ok := l.conns[conn]; ok {
+			delete(l.conns, conn)
+			if l.open[ip]--; l.open[ip] == 0 {
+				delete(l.open, ip)
+			}
+		}
+		l.mu.Unlock()
+	}
+}
+
+// connIP returns the IP address of the remote end of conn
+func connIP(conn net.Conn) string {
+	addr := conn.RemoteAddr().String()
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaxConnsPerIP(t *testing.T) {
+	srv, err := NewWithOptions(Options{Folder: t.TempDir(), Bind: "127.0.0.1", MaxConnsPerIP: 2}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	url, done := startTestServer(t, srv)
+	addr := strings.TrimPrefix(url, "http://")
+
+	// healthy sends a request on conn and reports whether it was answered
+	healthy := func(conn net.Conn) bool {
+		conn.SetDeadline(time.Now().Add(2 * time.Second))
+		if _, err := io.WriteString(conn, "GET /health HTTP/1.1\r\nHost: test\r\n\r\n"); err != nil {
+			return false
+		}
+		resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}
+
+	var conns []net.Conn
+	for i := 0; i < 3; i++ {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Fatalf("Dial() error = %v", err)
+		}
+		defer conn.Close()
+		conns = append(conns, conn)
+	}
+	if !healthy(conns[0]) || !healthy(conns[1]) {
+		t.Fatal("Connections within the limit should be served")
+	}
+	if healthy(conns[2]) {
+		t.Error("Connection beyond the limit should be closed")
+	}
+
+	// Closing a connection frees its slot
+	conns[0].Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Fatalf("Dial() error = %v", err)
+		}
+		ok := healthy(conn)
+		conn.Close()
+		if ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Connection after a slot was freed should be served")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	srv.shutdown()
+	waitServe(t, done)
+}
+
+func TestConnLimiterSeparatesIPs(t *testing.T) {
+	l := newConnLimiter(1)
+	a1, a2, b := newStubConn("10.0.0.1:1000"), newStubConn("10.0.0.1:1001"), newStubConn("10.0.0.2:1000")
+
+	l.connState(a1, http.StateNew)
+	l.connState(a2, http.StateNew)
+	l.connState(b, http.StateNew)
+	if !a2.closed {
+		t.Error("Second connection from 10.0.0.1 should be closed")
+	}
+	if a1.closed || b.closed {
+		t.Error("First connection of each IP should stay open")
+	}
+
+	l.connState(a2, http.StateClosed)
+	l.connState(a1, http.StateClosed)
+	l.connState(b, http.StateHijacked)
+	if len(l.open) != 0 || len(l.conns) != 0 {
+		t.Errorf("Closed connections should be forgotten, open = %v", l.open)
+	}
+}
+
+// stubConn is a net.Conn that only records Close
+type stubConn struct {
+	net.Conn
+	addr   net.Addr
+	closed bool
+}
+
+func newStubConn(addr string) *stubConn {
+	tcpAddr, _ := net.ResolveTCPAddr("tcp", addr)
+	return &stubConn{addr: tcpAddr}
+}
+
+func (c *stubConn) RemoteAddr() net.Addr { return c.addr }
+func (c *stubConn) Close() error         { c.closed = true; return nil }
@@ -0,0 +1,102 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"golang.org/x/net/webdav"
+)
+
+// crossDeviceFS moves names between filesystems mounted below the served
+// directory. A rename failing because source and destination are on
+// different devices falls back to copying the tree, then removing the source.
+type crossDeviceFS struct {
+	webdav.FileSystem
+	// root is the served directory
+	root string
+}
+
+func (fs crossDeviceFS) Rename(ctx context.Context, oldName, newName string) error {
+	err := fs.FileSystem.Rename(ctx, oldName, newName)
+	if err == nil || !isCrossDevice(err) {
+		return err
+	}
+	src, dst := localPath(fs.root, oldName), localPath(fs.root, newName)
+	if err := copyTree(src, dst); err != nil {
+		// Leave no partial copy behind; the source is untouched
+		os.RemoveAll(dst)
+		return fmt.Errorf("move across devices: %w", err)
+	}
+	if err := os.RemoveAll(src); err != nil {
+		return fmt.Errorf("move across devices: remove source: %w", err)
+	}
+	return nil
+}
+
+// localPath maps a slash-separated name to its path below root
+func localPath(root, name string) string {
+	return filepath.Join(root, filepath.FromSlash(path.Clean("/"+name)))
+}
+
+// copyTree copies src to dst, which must not exist, keeping the mode and
+// modification time of every member. Symbolic links are copied as links.
+func copyTree(src, dst string) error {
+	fi, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	switch {
+	case fi.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, dst)
+	case fi.IsDir():
+		if err := os.Mkdir(dst, fi.Mode().Perm()|0o700); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if err := copyTree(filepath.Join(src, e.Name()), filepath.Join(dst, e.Name())); err != nil {
+				return err
+			}
+		}
+	default:
+		if err := copyFile(src, dst, fi.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	// After the members, as creating them updates the time of a directory
+	if err := os.Chmod(dst, fi.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, fi.ModTime(), fi.ModTime())
+}
+
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
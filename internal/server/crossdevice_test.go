@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+// exdevFS fails every rename as if the names were on different devices
+type exdevFS struct {
+	webdav.Dir
+}
+
+func (exdevFS) Rename(ctx context.Context, oldName, newName string) error {
+	return &os.LinkError{Op: "rename", Old: oldName, New: newName, Err: syscall.EXDEV}
+}
+
+func newCrossDeviceHandler(dir string) http.Handler {
+	fs := crossDeviceFS{FileSystem: exdevFS{webdav.Dir(dir)}, root: dir}
+	return &webdav.Handler{FileSystem: fs, LockSystem: webdav.NewMemLS()}
+}
+
+func TestCrossDeviceMove(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test fakes EXDEV, which Windows renames do not return")
+	}
+	dir := t.TempDir()
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.MkdirAll(filepath.Join(dir, "src", "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "src", "sub", "a.txt"), []byte("hello"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("sub/a.txt", filepath.Join(dir, "src", "link")); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"src/sub/a.txt", "src/sub", "src"} {
+		if err := os.Chtimes(filepath.Join(dir, p), mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := doRequest(newCrossDeviceHandler(dir), "MOVE", "/src", "", map[string]string{"Destination": "/dst"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("MOVE status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "src")); !os.IsNotExist(err) {
+		t.Errorf("source still exists after MOVE: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "dst", "sub", "a.txt"))
+	if err != nil || string(data) != "hello" {
+		t.Fatalf("moved file = %q, %v, want %q", data, err, "hello")
+	}
+	fi, err := os.Stat(filepath.Join(dir, "dst", "sub", "a.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0o640 {
+		t.Errorf("moved file mode = %v, want %v", fi.Mode().Perm(), os.FileMode(0o640))
+	}
+	for _, p := range []string{"dst/sub/a.txt", "dst/sub", "dst"} {
+		fi, err := os.Stat(filepath.Join(dir, p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !fi.ModTime().Equal(mtime) {
+			t.Errorf("%s modified = %v, want %v", p, fi.ModTime(), mtime)
+		}
+	}
+	if target, err := os.Readlink(filepath.Join(dir, "dst", "link")); err != nil || target != "sub/a.txt" {
+		t.Errorf("moved link = %q, %v, want %q", target, err, "sub/a.txt")
+	}
+}
+
+func TestCrossDeviceMove_RemovesPartialCopy(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test fakes EXDEV, which Windows renames do not return")
+	}
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "src"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "src", "a.txt"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// A socket cannot be opened for reading, failing the copy midway
+	l, err := net.Listen("unix", filepath.Join(dir, "src", "z.sock"))
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	defer l.Close()
+
+	w := doRequest(newCrossDeviceHandler(dir), "MOVE", "/src", "", map[string]string{"Destination": "/dst"})
+	if w.Code < 400 {
+		t.Errorf("MOVE status = %d, want an error", w.Code)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "dst")); !os.IsNotExist(err) {
+		t.Errorf("partial copy left behind: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "src", "a.txt")); err != nil {
+		t.Errorf("source removed after failed MOVE: %v", err)
+	}
+}
+
+func TestCrossDeviceFS_OtherErrors(t *testing.T) {
+	dir := t.TempDir()
+	fs := crossDeviceFS{FileSystem: webdav.Dir(dir), root: dir}
+	if err := fs.Rename(context.Background(), "/missing", "/dst"); !os.IsNotExist(err) {
+		t.Errorf("Rename() error = %v, want not exist", err)
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isCrossDevice reports whether err is a rename failing with EXDEV
+func isCrossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
@@ -0,0 +1,18 @@
+//go:build windows
+
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"errors"
+
+	"golang.org/x/sys/windows"
+)
+
+// isCrossDevice reports whether err is a rename failing because the names
+// are on different volumes
+func isCrossDevice(err error) bool {
+	return errors.Is(err, windows.ERROR_NOT_SAME_DEVICE)
+}
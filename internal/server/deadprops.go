@@ -0,0 +1,459 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/webdav"
+
+	"gowebdavd/internal/logger"
+)
+
+// deadPropsName is the per-directory file holding the dead properties of
+// the directory's members. Names starting with it are hidden from clients.
+const deadPropsName = ".gowebdavd-props.json"
+
+// maxDeadPropsSize bounds how much of a properties file is read
+const maxDeadPropsSize = 1 << 20
+
+// deadPropsSelf is the key of the root's own properties in its file. Every
+// other collection keeps its properties in the file of its parent.
+const deadPropsSelf = "."
+
+// storedProp is a dead property as written to a properties file
+type storedProp struct {
+	Space string `json:"namespace"`
+	Local string `json:"name"`
+	Lang  string `json:"lang,omitempty"`
+	Value string `json:"value"`
+}
+
+// deadProps maps member names of a directory to their properties
+type deadProps map[string][]storedProp
+
+// deadPropsStore reads and writes properties files through fs. mu serializes
+// every access, so concurrent PROPPATCH requests on one directory do not
+// lose each other's changes. Parsed files are cached and read again when
+// their size or modification time changes.
+type deadPropsStore struct {
+	fs     webdav.FileSystem
+	logger *logger.Logger
+	mu     sync.Mutex
+	cache  map[string]*cachedDeadProps
+}
+
+type cachedDeadProps struct {
+	modTime time.Time
+	size    int64
+	props   deadProps
+}
+
+func newDeadPropsStore(fs webdav.FileSystem, log *logger.Logger) *deadPropsStore {
+	return &deadPropsStore{fs: fs, logger: log, cache: make(map[string]*cachedDeadProps)}
+}
+
+// deadPropsKey returns the directory whose file holds the properties of name
+// and the key they are stored under
+func deadPropsKey(name string) (dir, key string) {
+	name = path.Clean("/" + name)
+	if name == "/" {
+		return "/", deadPropsSelf
+	}
+	return path.Dir(name), path.Base(name)
+}
+
+// isDeadPropsFile reports whether name is a properties file or a temporary
+// file written while replacing one. The name is compared without regard to
+// case, as case-insensitive file systems resolve any spelling to the file.
+func isDeadPropsFile(name string) bool {
+	base := path.Base(name)
+	return len(base) >= len(deadPropsName) && strings.EqualFold(base[:len(deadPropsName)], deadPropsName)
+}
+
+// load returns the properties file of dir, empty when dir has none. The
+// caller holds s.mu.
+func (s *deadPropsStore) load(ctx context.Context, dir string) (deadProps, error) {
+	name := path.Join(dir, deadPropsName)
+	fi, err := s.fs.Stat(ctx, name)
+	if err != nil || fi.IsDir() {
+		delete(s.cache, dir)
+		return deadProps{}, nil
+	}
+	if cached, ok := s.cache[dir]; ok && cached.modTime.Equal(fi.ModTime()) && cached.size == fi.Size() {
+		return cached.props, nil
+	}
+
+	props, err := s.read(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	s.cache[dir] = &cachedDeadProps{modTime: fi.ModTime(), size: fi.Size(), props: props}
+	return props, nil
+}
+
+// read parses the properties file name
+func (s *deadPropsStore) read(ctx context.Context, name string) (deadProps, error) {
+	f, err := s.fs.OpenFile(ctx, name, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxDeadPropsSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if len(data) > maxDeadPropsSize {
+		return nil, fmt.Errorf("%s exceeds %d bytes", name, maxDeadPropsSize)
+	}
+	var props deadProps
+	if err := json.Unmarshal(data, &props); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if props == nil {
+		props = deadProps{}
+	}
+	return props, nil
+}
+
+// save replaces the properties file of dir with props, or removes it when
+// props is empty. The file is written next to its final name and renamed,
+// so readers never see a partial file. The caller holds s.mu.
+func (s *deadPropsStore) save(ctx context.Context, dir string, props deadProps) error {
+	delete(s.cache, dir)
+	name := path.Join(dir, deadPropsName)
+	if len(props) == 0 {
+		if err := s.fs.RemoveAll(ctx, name); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(props)
+	if err != nil {
+		return err
+	}
+	tmp := name + ".tmp"
+	f, err := s.fs.OpenFile(ctx, tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = s.fs.Rename(ctx, tmp, name)
+	}
+	if err != nil {
+		s.fs.RemoveAll(ctx, tmp)
+		return err
+	}
+	return nil
+}
+
+// get returns the dead properties of name. An unreadable properties file is
+// logged and treated as empty, so PROPFIND keeps working.
+func (s *deadPropsStore) get(ctx context.Context, name string) map[xml.Name]webdav.Property {
+	dir, key := deadPropsKey(name)
+	s.mu.Lock()
+	props, err := s.load(ctx, dir)
+	s.mu.Unlock()
+	if err != nil {
+		logf(s.logger, "Warning: dead properties unavailable: %v", err)
+		return nil
+	}
+
+	m := make(map[xml.Name]webdav.Property, len(props[key]))
+	for _, p := range props[key] {
+		n := xml.Name{Space: p.Space, Local: p.Local}
+		m[n] = webdav.Property{XMLName: n, Lang: p.Lang, InnerXML: []byte(p.Value)}
+	}
+	return m
+}
+
+// patch applies patches to the dead properties of name. When the properties
+// file cannot be read or written, no patch is applied and every property is
+// reported with 507 Insufficient Storage, as RFC 4918 does for properties
+// the server cannot record.
+func (s *deadPropsStore) patch(ctx context.Context, name string, patches []webdav.Proppatch) []webdav.Propstat {
+	pstat := webdav.Propstat{Status: http.StatusOK}
+	for _, patch := range patches {
+		for _, p := range patch.Props {
+			pstat.Props = append(pstat.Props, webdav.Property{XMLName: p.XMLName})
+		}
+	}
+
+	dir, key := deadPropsKey(name)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	props, err := s.load(ctx, dir)
+	if err == nil {
+		err = s.save(ctx, dir, patched(props, key, patches))
+	}
+	if err != nil {
+		logf(s.logger, "Warning: failed to store dead properties of %s: %v", name, err)
+		pstat.Status = http.StatusInsufficientStorage
+	}
+	return []webdav.Propstat{pstat}
+}
+
+// patched returns a copy of props with patches applied to the entry key
+func patched(props deadProps, key string, patches []webdav.Proppatch) deadProps {
+	m := make(map[xml.Name]storedProp)
+	for _, p := range props[key] {
+		m[xml.Name{Space: p.Space, Local: p.Local}] = p
+	}
+	for _, patch := range patches {
+		for _, p := range patch.Props {
+			if patch.Remove {
+				delete(m, p.XMLName)
+				continue
+			}
+			m[p.XMLName] = storedProp{Space: p.XMLName.Space, Local: p.XMLName.Local, Lang: p.Lang, Value: string(p.InnerXML)}
+		}
+	}
+
+	entry := make([]storedProp, 0, len(m))
+	for _, p := range m {
+		entry = append(entry, p)
+	}
+	slices.SortFunc(entry, func(a, b storedProp) int {
+		return cmp.Or(cmp.Compare(a.Space, b.Space), cmp.Compare(a.Local, b.Local))
+	})
+	return withEntry(props, key, entry)
+}
+
+// withEntry returns a copy of props with key set to entry, or without key
+// when entry is empty, leaving the cached original untouched
+func withEntry(props deadProps, key string, entry []storedProp) deadProps {
+	out := make(deadProps, len(props)+1)
+	for k, v := range props {
+		out[k] = v
+	}
+	if len(entry) == 0 {
+		delete(out, key)
+	} else {
+		out[key] = entry
+	}
+	return out
+}
+
+// move carries the properties of oldName over to newName after a rename
+func (s *deadPropsStore) move(ctx context.Context, oldName, newName string) {
+	oldDir, oldKey := deadPropsKey(oldName)
+	newDir, newKey := deadPropsKey(newName)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	props, err := s.load(ctx, oldDir)
+	if err == nil && len(props[oldKey]) > 0 {
+		entry := props[oldKey]
+		if err = s.save(ctx, oldDir, withEntry(props, oldKey, nil)); err == nil {
+			if props, err = s.load(ctx, newDir); err == nil {
+				err = s.save(ctx, newDir, withEntry(props, newKey, entry))
+			}
+		}
+	}
+	if err != nil {
+		logf(s.logger, "Warning: failed to move dead properties of %s: %v", oldName, err)
+	}
+}
+
+// remove drops the properties of name after it was removed
+func (s *deadPropsStore) remove(ctx context.Context, name string) {
+	dir, key := deadPropsKey(name)
+	if key == deadPropsSelf {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	props, err := s.load(ctx, dir)
+	if err == nil && len(props[key]) > 0 {
+		err = s.save(ctx, dir, withEntry(props, key, nil))
+	}
+	if err != nil {
+		logf(s.logger, "Warning: failed to remove dead properties of %s: %v", name, err)
+	}
+}
+
+// copyDeadProps copies the properties of the collections a successful COPY
+// created. The WebDAV handler copies those of files itself.
+func copyDeadProps(next http.Handler, store *deadPropsStore, prefix string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dst := destinationPath(r)
+		if r.Method != "COPY" || dst == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// COPY with Depth 0 copies a collection without its members
+		recurse := r.Header.Get("Depth") != "0"
+		apply := func() {
+			src := strings.TrimPrefix(r.URL.Path, prefix)
+			store.copyCollections(r.Context(), src, strings.TrimPrefix(dst, prefix), recurse)
+		}
+		next.ServeHTTP(&modTimeWriter{ResponseWriter: w, apply: apply}, r)
+	})
+}
+
+// copyCollections copies the properties of src to dst when src is a
+// collection, and those of its member collections when recurse is set
+func (s *deadPropsStore) copyCollections(ctx context.Context, src, dst string, recurse bool) {
+	f, err := s.fs.OpenFile(ctx, src, os.O_RDONLY, 0)
+	if err != nil {
+		return
+	}
+	fi, err := f.Stat()
+	var entries []os.FileInfo
+	if err == nil && fi.IsDir() && recurse {
+		entries, _ = f.Readdir(-1)
+	}
+	f.Close()
+	if err != nil || !fi.IsDir() {
+		return
+	}
+
+	s.copyEntry(ctx, src, dst)
+	for _, e := range entries {
+		if e.IsDir() && !isDeadPropsFile(e.Name()) {
+			s.copyCollections(ctx, path.Join(src, e.Name()), path.Join(dst, e.Name()), true)
+		}
+	}
+}
+
+// copyEntry gives dst the properties of src
+func (s *deadPropsStore) copyEntry(ctx context.Context, src, dst string) {
+	srcDir, srcKey := deadPropsKey(src)
+	dstDir, dstKey := deadPropsKey(dst)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	props, err := s.load(ctx, srcDir)
+	if err == nil && len(props[srcKey]) > 0 {
+		entry := props[srcKey]
+		if props, err = s.load(ctx, dstDir); err == nil {
+			err = s.save(ctx, dstDir, withEntry(props, dstKey, entry))
+		}
+	}
+	if err != nil {
+		logf(s.logger, "Warning: failed to copy dead properties of %s: %v", src, err)
+	}
+}
+
+// deadPropsFS keeps the dead properties set with PROPPATCH in a properties
+// file per directory, which clients can neither list nor access
+type deadPropsFS struct {
+	webdav.FileSystem
+	store *deadPropsStore
+}
+
+// deadPropsHidden returns the error for an operation on a properties file
+func deadPropsHidden(op, name string) error {
+	return &os.PathError{Op: op, Path: name, Err: os.ErrNotExist}
+}
+
+func (fs deadPropsFS) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	if isDeadPropsFile(name) {
+		return deadPropsHidden("mkdir", name)
+	}
+	return fs.FileSystem.Mkdir(ctx, name, perm)
+}
+
+func (fs deadPropsFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	if isDeadPropsFile(name) {
+		return nil, deadPropsHidden("open", name)
+	}
+	f, err := fs.FileSystem.OpenFile(ctx, name, flag, perm)
+	if err != nil && flag == os.O_RDWR {
+		// PROPPATCH opens its target for writing, which the operating
+		// system refuses for directories. Their properties need no write.
+		if fi, serr := fs.FileSystem.Stat(ctx, name); serr == nil && fi.IsDir() {
+			f, err = fs.FileSystem.OpenFile(ctx, name, os.O_RDONLY, 0)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &deadPropsFile{File: f, ctx: ctx, name: name, store: fs.store}, nil
+}
+
+func (fs deadPropsFS) RemoveAll(ctx context.Context, name string) error {
+	if isDeadPropsFile(name) {
+		return deadPropsHidden("remove", name)
+	}
+	if err := fs.FileSystem.RemoveAll(ctx, name); err != nil {
+		return err
+	}
+	fs.store.remove(ctx, name)
+	return nil
+}
+
+func (fs deadPropsFS) Rename(ctx context.Context, oldName, newName string) error {
+	if isDeadPropsFile(oldName) {
+		return deadPropsHidden("rename", oldName)
+	}
+	if isDeadPropsFile(newName) {
+		return deadPropsHidden("rename", newName)
+	}
+	if err := fs.FileSystem.Rename(ctx, oldName, newName); err != nil {
+		return err
+	}
+	fs.store.move(ctx, oldName, newName)
+	return nil
+}
+
+func (fs deadPropsFS) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	if isDeadPropsFile(name) {
+		return nil, deadPropsHidden("stat", name)
+	}
+	return fs.FileSystem.Stat(ctx, name)
+}
+
+// deadPropsFile offers the stored properties of a file and leaves the
+// properties files out of directory listings
+type deadPropsFile struct {
+	webdav.File
+	ctx   context.Context
+	name  string
+	store *deadPropsStore
+}
+
+func (f *deadPropsFile) DeadProps() (map[xml.Name]webdav.Property, error) {
+	return f.store.get(f.ctx, f.name), nil
+}
+
+func (f *deadPropsFile) Patch(patches []webdav.Proppatch) ([]webdav.Propstat, error) {
+	return f.store.patch(f.ctx, f.name, patches), nil
+}
+
+func (f *deadPropsFile) Readdir(count int) ([]os.FileInfo, error) {
+	for {
+		entries, err := f.File.Readdir(count)
+		visible := entries[:0]
+		for _, e := range entries {
+			if !isDeadPropsFile(e.Name()) {
+				visible = append(visible, e)
+			}
+		}
+		// A positive count must yield at least one entry unless the end is reached
+		if count <= 0 || len(visible) > 0 || err != nil {
+			return visible, err
+		}
+	}
+}
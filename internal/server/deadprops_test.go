@@ -0,0 +1,234 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/net/webdav"
+)
+
+const colorPatch = `<?xml version="1.0"?>
+<D:propertyupdate xmlns:D="DAV:" xmlns:Z="urn:example">
+<D:set><D:prop><Z:color>%s</Z:color></D:prop></D:set>
+</D:propertyupdate>`
+
+const colorRemove = `<?xml version="1.0"?>
+<D:propertyupdate xmlns:D="DAV:" xmlns:Z="urn:example">
+<D:remove><D:prop><Z:color/></D:prop></D:remove>
+</D:propertyupdate>`
+
+const colorPropfind = `<?xml version="1.0"?>
+<D:propfind xmlns:D="DAV:"><D:prop><Z:color xmlns:Z="urn:example"/></D:prop></D:propfind>`
+
+func newDeadPropsHandler(dir string, opts Options) http.Handler {
+	opts.DeadProps = true
+	return davHandler(webdav.Dir(dir), "", webdav.NewMemLS(), opts, nil)
+}
+
+// setColor sets the color property of target and returns the propstat status
+func setColor(t *testing.T, h http.Handler, target, color string) string {
+	t.Helper()
+
+	rec := doRequest(h, "PROPPATCH", target, fmt.Sprintf(colorPatch, color), nil)
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("PROPPATCH %s status = %d, want %d", target, rec.Code, http.StatusMultiStatus)
+	}
+	return rec.Body.String()
+}
+
+// color returns the color property of target, empty when it is not set
+func color(t *testing.T, h http.Handler, target string) string {
+	t.Helper()
+
+	rec := doRequest(h, "PROPFIND", target, colorPropfind, map[string]string{"Depth": "0"})
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("PROPFIND %s status = %d, want %d", target, rec.Code, http.StatusMultiStatus)
+	}
+	body := rec.Body.String()
+	start := strings.Index(body, `xmlns="urn:example">`)
+	if start < 0 {
+		return ""
+	}
+	value := body[start+len(`xmlns="urn:example">`):]
+	return value[:strings.Index(value, "<")]
+}
+
+func TestDeadPropsPersist(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	h := newDeadPropsHandler(dir, Options{})
+
+	if body := setColor(t, h, "/a.txt", "red"); !strings.Contains(body, "200 OK") {
+		t.Errorf("PROPPATCH body = %s, want 200 OK", body)
+	}
+	setColor(t, h, "/", "blue")
+
+	// A new handler reads the properties back from disk
+	h = newDeadPropsHandler(dir, Options{})
+	if got := color(t, h, "/a.txt"); got != "red" {
+		t.Errorf("color of /a.txt = %q, want %q", got, "red")
+	}
+	if got := color(t, h, "/"); got != "blue" {
+		t.Errorf("color of / = %q, want %q", got, "blue")
+	}
+
+	doRequest(h, "PROPPATCH", "/a.txt", colorRemove, nil)
+	if got := color(t, h, "/a.txt"); got != "" {
+		t.Errorf("color of /a.txt after remove = %q, want none", got)
+	}
+}
+
+func TestDeadPropsHidden(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	h := newDeadPropsHandler(dir, Options{})
+	setColor(t, h, "/a.txt", "red")
+	if _, err := os.Stat(filepath.Join(dir, deadPropsName)); err != nil {
+		t.Fatalf("properties file not written: %v", err)
+	}
+
+	rec := doRequest(h, "PROPFIND", "/", "", map[string]string{"Depth": "1"})
+	if strings.Contains(rec.Body.String(), deadPropsName) {
+		t.Errorf("PROPFIND lists the properties file: %s", rec.Body.String())
+	}
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+		if rec := doRequest(h, method, "/"+deadPropsName, "{}", nil); rec.Code < 400 {
+			t.Errorf("%s of the properties file status = %d, want an error", method, rec.Code)
+		}
+	}
+	if got := color(t, h, "/a.txt"); got != "red" {
+		t.Errorf("color of /a.txt = %q, want it untouched", got)
+	}
+
+	// Case-insensitive file systems resolve any spelling to the file
+	h = newDeadPropsHandler(dir, Options{CaseInsensitive: true})
+	for _, name := range []string{"/.GOWEBDAVD-PROPS.json", "/.Gowebdavd-Props.JSON"} {
+		for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+			if rec := doRequest(h, method, name, "{}", nil); rec.Code < 400 {
+				t.Errorf("%s %s status = %d, want an error", method, name, rec.Code)
+			}
+		}
+	}
+	if got := color(t, h, "/a.txt"); got != "red" {
+		t.Errorf("color of /a.txt = %q, want it untouched", got)
+	}
+}
+
+func TestDeadPropsFollowFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "d"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	h := newDeadPropsHandler(dir, Options{})
+	setColor(t, h, "/a.txt", "red")
+	setColor(t, h, "/d", "green")
+
+	doRequest(h, "MOVE", "/a.txt", "", map[string]string{"Destination": "/d/b.txt"})
+	if got := color(t, h, "/d/b.txt"); got != "red" {
+		t.Errorf("color after MOVE = %q, want %q", got, "red")
+	}
+	doRequest(h, "COPY", "/d", "", map[string]string{"Destination": "/e"})
+	if got := color(t, h, "/e"); got != "green" {
+		t.Errorf("color of the copied collection = %q, want %q", got, "green")
+	}
+	if got := color(t, h, "/e/b.txt"); got != "red" {
+		t.Errorf("color of the copied file = %q, want %q", got, "red")
+	}
+
+	doRequest(h, http.MethodDelete, "/d/b.txt", "", nil)
+	doRequest(h, http.MethodPut, "/d/b.txt", "new", nil)
+	if got := color(t, h, "/d/b.txt"); got != "" {
+		t.Errorf("color of a recreated file = %q, want none", got)
+	}
+}
+
+func TestDeadPropsWriteFailure(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// A directory in the way of the temporary file makes every write fail
+	if err := os.Mkdir(filepath.Join(dir, deadPropsName+".tmp"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	h := newDeadPropsHandler(dir, Options{})
+
+	if body := setColor(t, h, "/a.txt", "red"); !strings.Contains(body, "507 Insufficient Storage") {
+		t.Errorf("PROPPATCH body = %s, want 507 Insufficient Storage", body)
+	}
+	if got := color(t, h, "/a.txt"); got != "" {
+		t.Errorf("color after failed PROPPATCH = %q, want none", got)
+	}
+}
+
+func TestDeadPropsCorruptFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, deadPropsName), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	h := newDeadPropsHandler(dir, Options{})
+
+	if got := color(t, h, "/a.txt"); got != "" {
+		t.Errorf("color with a corrupt file = %q, want none", got)
+	}
+	if body := setColor(t, h, "/a.txt", "red"); !strings.Contains(body, "507 Insufficient Storage") {
+		t.Errorf("PROPPATCH body = %s, want 507 Insufficient Storage", body)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, deadPropsName)); string(data) != "{" {
+		t.Errorf("corrupt properties file was overwritten with %q", data)
+	}
+}
+
+func TestDeadPropsConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	h := newDeadPropsHandler(dir, Options{})
+	const n = 20
+	for i := range n {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.txt", i)), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Go(func() {
+			doRequest(h, "PROPPATCH", fmt.Sprintf("/%d.txt", i), fmt.Sprintf(colorPatch, fmt.Sprint(i)), nil)
+		})
+	}
+	wg.Wait()
+
+	for i := range n {
+		if got := color(t, h, fmt.Sprintf("/%d.txt", i)); got != fmt.Sprint(i) {
+			t.Errorf("color of /%d.txt = %q, want %q", i, got, fmt.Sprint(i))
+		}
+	}
+}
+
+func TestDeadPropsWithReportQuota(t *testing.T) {
+	dir := t.TempDir()
+	h := newDeadPropsHandler(dir, Options{ReportQuota: true})
+	setColor(t, h, "/", "blue")
+
+	if got := color(t, h, "/"); got != "blue" {
+		t.Errorf("color of / = %q, want %q", got, "blue")
+	}
+	rec := doRequest(h, "PROPFIND", "/", quotaPropfind, map[string]string{"Depth": "0"})
+	if available, _ := quotaValues(t, rec.Body.String()); available < 0 {
+		t.Errorf("PROPFIND body = %s, want the quota properties", rec.Body.String())
+	}
+}
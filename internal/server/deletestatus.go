@@ -0,0 +1,148 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"golang.org/x/net/webdav"
+)
+
+// deleteFailuresKey stores the *deleteFailures of a DELETE request in its context
+type deleteFailuresKey struct{}
+
+// deleteFailure is a member of a collection that could not be removed
+type deleteFailure struct {
+	name  string
+	isDir bool
+	err   error
+}
+
+// deleteFailures collects the members a DELETE could not remove
+type deleteFailures struct {
+	members []deleteFailure
+}
+
+// errPartialDelete is returned by RemoveAll when some members were removed
+// and others were not
+var errPartialDelete = errors.New("some members of the collection could not be removed")
+
+// deleteFS removes collections member by member for DELETE requests handled
+// by deleteMultiStatus, so that a failing member does not stop the removal
+// of its siblings and every failure can be reported
+type deleteFS struct {
+	webdav.FileSystem
+}
+
+func (fs deleteFS) RemoveAll(ctx context.Context, name string) error {
+	failures, ok := ctx.Value(deleteFailuresKey{}).(*deleteFailures)
+	if !ok {
+		return fs.FileSystem.RemoveAll(ctx, name)
+	}
+	fi, err := fs.FileSystem.Stat(ctx, name)
+	if err != nil || !fi.IsDir() {
+		return fs.FileSystem.RemoveAll(ctx, name)
+	}
+	if !fs.removeTree(ctx, name, failures) && len(failures.members) > 0 {
+		return errPartialDelete
+	}
+	return nil
+}
+
+// removeTree removes the collection name bottom-up and reports whether it is
+// gone. Failing members are recorded; their ancestors are left in place
+// without being recorded, as RFC 4918 §9.6.1 asks.
+func (fs deleteFS) removeTree(ctx context.Context, name string, failures *deleteFailures) bool {
+	f, err := fs.FileSystem.OpenFile(ctx, name, os.O_RDONLY, 0)
+	if err != nil {
+		failures.members = append(failures.members, deleteFailure{name: name, isDir: true, err: err})
+		return false
+	}
+	entries, err := f.Readdir(-1)
+	f.Close()
+	if err != nil {
+		failures.members = append(failures.members, deleteFailure{name: name, isDir: true, err: err})
+		return false
+	}
+
+	removed := true
+	for _, e := range entries {
+		child := path.Join(name, e.Name())
+		if e.IsDir() {
+			removed = fs.removeTree(ctx, child, failures) && removed
+			continue
+		}
+		if err := fs.FileSystem.RemoveAll(ctx, child); err != nil {
+			failures.members = append(failures.members, deleteFailure{name: child, err: err})
+			removed = false
+		}
+	}
+	if !removed {
+		return false
+	}
+	if err := fs.FileSystem.RemoveAll(ctx, name); err != nil {
+		failures.members = append(failures.members, deleteFailure{name: name, isDir: true, err: err})
+		return false
+	}
+	return true
+}
+
+// deleteMultiStatus answers a DELETE of a collection whose members could only
+// partly be removed with 207 Multi-Status, listing each member that is still
+// there, instead of a single error status for the whole request
+func deleteMultiStatus(next http.Handler, prefix string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		failures := &deleteFailures{}
+		r = r.WithContext(context.WithValue(r.Context(), deleteFailuresKey{}, failures))
+		rec := newBufferedResponse()
+		next.ServeHTTP(rec, r)
+		if len(failures.members) == 0 || rec.status < 400 {
+			rec.replay(w)
+			return
+		}
+
+		var b strings.Builder
+		b.WriteString(xml.Header)
+		b.WriteString(`<D:multistatus xmlns:D="DAV:">` + "\n")
+		for _, m := range failures.members {
+			href := prefix + m.name
+			if m.isDir {
+				href += "/"
+			}
+			b.WriteString("<D:response><D:href>")
+			xml.EscapeText(&b, []byte((&url.URL{Path: href}).EscapedPath()))
+			status := deleteFailureStatus(m.err)
+			fmt.Fprintf(&b, "</D:href><D:status>HTTP/1.1 %d %s</D:status></D:response>\n", status, http.StatusText(status))
+		}
+		b.WriteString("</D:multistatus>\n")
+
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.WriteHeader(http.StatusMultiStatus)
+		w.Write([]byte(b.String()))
+	})
+}
+
+// deleteFailureStatus maps the error removing a member to its response status
+func deleteFailureStatus(err error) int {
+	switch {
+	case errors.Is(err, os.ErrPermission):
+		return http.StatusForbidden
+	case errors.Is(err, os.ErrNotExist):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
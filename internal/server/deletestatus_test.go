@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/webdav"
+)
+
+// stuckFS refuses to remove files named stuck.txt
+type stuckFS struct {
+	webdav.FileSystem
+}
+
+func (fs stuckFS) RemoveAll(ctx context.Context, name string) error {
+	if path.Base(name) == "stuck.txt" {
+		return &os.PathError{Op: "remove", Path: name, Err: os.ErrPermission}
+	}
+	return fs.FileSystem.RemoveAll(ctx, name)
+}
+
+// newDeleteTree creates /dir with a removable file, a subdirectory holding an
+// unremovable file and an empty subdirectory
+func newDeleteTree(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for _, dir := range []string{"dir/sub", "dir/empty"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+	for _, name := range []string{"dir/a.txt", "dir/sub/stuck.txt", "dir/sub/b.txt"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("data"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+	return root
+}
+
+func TestDeleteMultiStatus(t *testing.T) {
+	root := newDeleteTree(t)
+	fs := stuckFS{FileSystem: webdav.Dir(root)}
+	h := davHandler(fs, "", webdav.NewMemLS(), Options{DeleteMultiStatus: true}, nil)
+
+	rec := doRequest(h, http.MethodDelete, "/dir", "", nil)
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("DELETE status = %d, want %d", rec.Code, http.StatusMultiStatus)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "<D:href>/dir/sub/stuck.txt</D:href><D:status>HTTP/1.1 403 Forbidden</D:status>") {
+		t.Errorf("multistatus does not report the stuck file:\n%s", body)
+	}
+	// Ancestors of the failing member are implied and not listed
+	if strings.Count(body, "<D:response>") != 1 {
+		t.Errorf("multistatus lists more than the failing member:\n%s", body)
+	}
+
+	for _, name := range []string{"dir/a.txt", "dir/sub/b.txt", "dir/empty"} {
+		if _, err := os.Stat(filepath.Join(root, name)); !os.IsNotExist(err) {
+			t.Errorf("%s was not removed", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "dir/sub/stuck.txt")); err != nil {
+		t.Errorf("stuck file is gone: %v", err)
+	}
+}
+
+func TestDeleteMultiStatusUnaffected(t *testing.T) {
+	root := newDeleteTree(t)
+	fs := stuckFS{FileSystem: webdav.Dir(root)}
+	h := davHandler(fs, "/files", webdav.NewMemLS(), Options{DeleteMultiStatus: true}, nil)
+
+	if rec := doRequest(h, http.MethodDelete, "/files/dir/a.txt", "", nil); rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE of a file status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if rec := doRequest(h, http.MethodDelete, "/files/dir/sub/stuck.txt", "", nil); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE of the stuck file status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+	if rec := doRequest(h, http.MethodDelete, "/files/dir/empty", "", nil); rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE of a removable collection status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+
+	rec := doRequest(h, http.MethodDelete, "/files/dir", "", nil)
+	if rec.Code != http.StatusMultiStatus || !strings.Contains(rec.Body.String(), "<D:href>/files/dir/sub/stuck.txt</D:href>") {
+		t.Errorf("DELETE under a prefix = %d:\n%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestDeleteWithoutMultiStatus(t *testing.T) {
+	root := newDeleteTree(t)
+	h := davHandler(stuckFS{FileSystem: webdav.Dir(root)}, "", webdav.NewMemLS(), Options{}, nil)
+
+	// The WebDAV handler stops at the first failure with a single status
+	if rec := doRequest(h, http.MethodDelete, "/dir/sub/stuck.txt", "", nil); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultNonceTTL is how long a Digest nonce stays valid
+const DefaultNonceTTL = 5 * time.Minute
+
+// maxNonces caps the nonce store so a scanning client cannot grow it without bound
+const maxNonces = 10000
+
+// nonceStore issues Digest nonces and tracks their nonce counts to reject
+// replayed requests
+type nonceStore struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	nonces    map[string]*nonceEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type nonceEntry struct {
+	issued time.Time
+	count  uint64
+}
+
+func newNonceStore(ttl time.Duration) *nonceStore {
+	return &nonceStore{
+		ttl:    ttl,
+		nonces: make(map[string]*nonceEntry),
+		now:    time.Now,
+	}
+}
+
+// issue returns a new random nonce
+func (s *nonceStore) issue() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	nonce := hex.EncodeToString(b)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if now.Sub(s.lastSweep) > s.ttl/2 || len(s.nonces) >= maxNonces {
+		s.sweep(now)
+	}
+	s.nonces[nonce] = &nonceEntry{issued: now}
+	return nonce
+}
+
+// use validates nonce and its count. It reports whether the nonce is known,
+// unexpired and count exceeds every count seen before, and whether the nonce
+// is stale, i.e. unknown or expired, so the client should retry with a fresh one.
+func (s *nonceStore) use(nonce string, count uint64) (ok, stale bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, found := s.nonces[nonce]
+	if !found {
+		return false, true
+	}
+	if s.now().Sub(e.issued) > s.ttl {
+		delete(s.nonces, nonce)
+		return false, true
+	}
+	if count <= e.count {
+		return false, false
+	}
+	e.count = count
+	return true, false
+}
+
+// sweep drops expired nonces, and the oldest ones while the store is full.
+// The caller must hold s.mu.
+func (s *nonceStore) sweep(now time.Time) {
+	s.lastSweep = now
+	for nonce, e := range s.nonces {
+		if now.Sub(e.issued) > s.ttl {
+			delete(s.nonces, nonce)
+		}
+	}
+	for len(s.nonces) >= maxNonces {
+		var oldest string
+		for nonce, e := range s.nonces {
+			if oldest == "" || e.issued.Before(s.nonces[oldest].issued) {
+				oldest = nonce
+			}
+		}
+		delete(s.nonces, oldest)
+	}
+}
+
+// digestAuth requires HTTP Digest credentials (RFC 2617, qop=auth) matching
+// creds and challenges unauthenticated requests with 401
+func digestAuth(next http.Handler, creds Credentials, ttl time.Duration) http.Handler {
+	nonces := newNonceStore(ttl)
+
+	challenge := func(w http.ResponseWriter, stale bool) {
+		h := fmt.Sprintf(`Digest realm="%s", qop="auth", algorithm=MD5, nonce="%s"`, authRealm, nonces.issue())
+		if stale {
+			h += ", stale=true"
+		}
+		w.Header().Set("WWW-Authenticate", h)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scheme, params, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !strings.EqualFold(scheme, "Digest") {
+			challenge(w, false)
+			return
+		}
+
+		p := parseAuthParams(params)
+		user := p["username"]
+		pass, known := creds[user]
+		count, err := strconv.ParseUint(p["nc"], 16, 64)
+		if !known || err != nil || p["realm"] != authRealm || p["qop"] != "auth" || p["uri"] != r.RequestURI {
+			challenge(w, false)
+			return
+		}
+
+		ha1 := md5Hex(user + ":" + authRealm + ":" + pass)
+		ha2 := md5Hex(r.Method + ":" + p["uri"])
+		want := md5Hex(ha1 + ":" + p["nonce"] + ":" + p["nc"] + ":" + p["cnonce"] + ":auth:" + ha2)
+		if subtle.ConstantTimeCompare([]byte(want), []byte(p["response"])) != 1 {
+			challenge(w, false)
+			return
+		}
+
+		// Only a correct response consumes the nonce count
+		if ok, stale := nonces.use(p["nonce"], count); !ok {
+			challenge(w, stale)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// parseAuthParams parses comma-separated key=value pairs of an Authorization
+// header, where values may be quoted strings containing commas
+func parseAuthParams(s string) map[string]string {
+	params := make(map[string]string)
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		key, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			return params
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			var b strings.Builder
+			i := 1
+			for ; i < len(rest) && rest[i] != '"'; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) {
+					i++
+				}
+				b.WriteByte(rest[i])
+			}
+			value, s = b.String(), rest[min(i+1, len(rest)):]
+		} else {
+			value, s, _ = strings.Cut(rest, ",")
+			value = strings.TrimSpace(value)
+		}
+		params[key] = value
+	}
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
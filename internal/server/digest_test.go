@@ -0,0 +1,130 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// digestAuthorization computes an Authorization header answering challenge
+func digestAuthorization(user, pass, method, uri, challenge string, nc int) string {
+	p := parseAuthParams(strings.TrimPrefix(challenge, "Digest "))
+	ncHex := fmt.Sprintf("%08x", nc)
+	cnonce := "0a4f113b"
+	ha1 := md5Hex(user + ":" + p["realm"] + ":" + pass)
+	ha2 := md5Hex(method + ":" + uri)
+	response := md5Hex(ha1 + ":" + p["nonce"] + ":" + ncHex + ":" + cnonce + ":auth:" + ha2)
+	return fmt.Sprintf(`Digest username="%s", realm="%s", nonce="%s", uri="%s", qop=auth, nc=%s, cnonce="%s", response="%s"`,
+		user, p["realm"], p["nonce"], uri, ncHex, cnonce, response)
+}
+
+func newDigestHandler(t *testing.T) http.Handler {
+	t.Helper()
+
+	srv, err := NewWithOptions(Options{
+		Folder:      t.TempDir(),
+		Port:        18080,
+		Bind:        "127.0.0.1",
+		Credentials: Credentials{"alice": "secret"},
+		DigestAuth:  true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	return srv.Handler()
+}
+
+func propfindWith(h http.Handler, authorization string) *httptest.ResponseRecorder {
+	headers := map[string]string{"Depth": "0"}
+	if authorization != "" {
+		headers["Authorization"] = authorization
+	}
+	return doRequest(h, "PROPFIND", "/", "", headers)
+}
+
+func TestDigestAuth(t *testing.T) {
+	h := newDigestHandler(t)
+
+	rec := propfindWith(h, "")
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("PROPFIND without credentials status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	challenge := rec.Header().Get("WWW-Authenticate")
+	if !strings.HasPrefix(challenge, `Digest realm="gowebdavd", qop="auth"`) {
+		t.Fatalf("WWW-Authenticate = %q, want a Digest challenge", challenge)
+	}
+
+	rec = propfindWith(h, digestAuthorization("alice", "secret", "PROPFIND", "/", challenge, 1))
+	if rec.Code != http.StatusMultiStatus {
+		t.Errorf("PROPFIND with valid digest status = %d, want %d", rec.Code, http.StatusMultiStatus)
+	}
+
+	rec = propfindWith(h, digestAuthorization("alice", "secret", "PROPFIND", "/", challenge, 1))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("replayed nonce count status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	rec = propfindWith(h, digestAuthorization("alice", "secret", "PROPFIND", "/", challenge, 2))
+	if rec.Code != http.StatusMultiStatus {
+		t.Errorf("next nonce count status = %d, want %d", rec.Code, http.StatusMultiStatus)
+	}
+
+	rec = propfindWith(h, digestAuthorization("alice", "wrong", "PROPFIND", "/", challenge, 3))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("wrong password status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	rec = propfindWith(h, digestAuthorization("alice", "secret", "PROPFIND", "/other", challenge, 4))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("mismatched uri status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	rec = propfindWith(h, "Basic YWxpY2U6c2VjcmV0")
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Basic credentials status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestDigestAuthRequiresCredentials(t *testing.T) {
+	_, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18080, Bind: "127.0.0.1", DigestAuth: true}, nil)
+	if err == nil {
+		t.Error("NewWithOptions() should reject digest authentication without credentials")
+	}
+}
+
+func TestNonceStoreExpiry(t *testing.T) {
+	now := time.Now()
+	store := newNonceStore(time.Minute)
+	store.now = func() time.Time { return now }
+
+	nonce := store.issue()
+	if ok, _ := store.use(nonce, 1); !ok {
+		t.Fatal("use() of a fresh nonce should succeed")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if ok, stale := store.use(nonce, 2); ok || !stale {
+		t.Errorf("use() of an expired nonce = %v, %v, want false, true", ok, stale)
+	}
+
+	// Issuing after the window sweeps expired entries
+	store.issue()
+	now = now.Add(2 * time.Minute)
+	store.issue()
+	store.mu.Lock()
+	count := len(store.nonces)
+	store.mu.Unlock()
+	if count != 1 {
+		t.Errorf("nonce store holds %d entries, want 1 after sweeping", count)
+	}
+}
+
+func TestParseAuthParams(t *testing.T) {
+	p := parseAuthParams(`username="a,b", qop=auth, nc=00000001, response="x\"y"`)
+	if p["username"] != "a,b" || p["qop"] != "auth" || p["nc"] != "00000001" || p["response"] != `x"y` {
+		t.Errorf("parseAuthParams() = %v", p)
+	}
+}
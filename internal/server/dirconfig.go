@@ -0,0 +1,304 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+// dirConfigName is the per-directory configuration file
+const dirConfigName = ".gowebdavd.json"
+
+// isDirConfigFile reports whether name is a configuration file. The name is
+// compared without regard to case, as case-insensitive file systems resolve
+// any spelling to the file.
+func isDirConfigFile(name string) bool {
+	return strings.EqualFold(path.Base(name), dirConfigName)
+}
+
+// maxDirConfigSize bounds how much of a directory configuration file is read
+const maxDirConfigSize = 64 << 10
+
+// dirConfig is the content of a directory configuration file. Unset fields
+// inherit the value from the nearest ancestor directory that sets them.
+type dirConfig struct {
+	// ReadOnly rejects every modification of the subtree
+	ReadOnly *bool `json:"read_only"`
+	// DenyExtensions lists file extensions that cannot be created in the subtree
+	DenyExtensions []string `json:"deny_extensions"`
+	// Hidden serves the subtree as if it did not exist. A subdirectory cannot
+	// unhide itself.
+	Hidden *bool `json:"hidden"`
+}
+
+// dirPolicy is the effective configuration of a path
+type dirPolicy struct {
+	readOnly       bool
+	denyExtensions []string
+	hidden         bool
+}
+
+// denies reports whether name has one of the denied extensions
+func (p dirPolicy) denies(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	for _, denied := range p.denyExtensions {
+		if ext == denied {
+			return true
+		}
+	}
+	return false
+}
+
+// dirConfigs reads directory configuration files from fs. Parsed files are
+// cached and read again when their size or modification time changes.
+type dirConfigs struct {
+	fs    webdav.FileSystem
+	mu    sync.Mutex
+	cache map[string]*cachedDirConfig
+}
+
+type cachedDirConfig struct {
+	modTime time.Time
+	size    int64
+	config  dirConfig
+	err     error
+}
+
+func newDirConfigs(fs webdav.FileSystem) *dirConfigs {
+	return &dirConfigs{fs: fs, cache: make(map[string]*cachedDirConfig)}
+}
+
+// policy merges the configuration files from the root down to name itself,
+// so a collection's own file applies to the collection
+func (c *dirConfigs) policy(ctx context.Context, name string) (dirPolicy, error) {
+	var p dirPolicy
+	dir := "/"
+	for _, elem := range strings.Split(strings.Trim(path.Clean("/"+name), "/"), "/") {
+		cfg, err := c.load(ctx, dir)
+		if err != nil {
+			return dirPolicy{}, err
+		}
+		p.apply(cfg)
+		dir = path.Join(dir, elem)
+	}
+	if dir != "/" {
+		cfg, err := c.load(ctx, dir)
+		if err != nil {
+			return dirPolicy{}, err
+		}
+		p.apply(cfg)
+	}
+	return p, nil
+}
+
+// apply overrides p with the fields cfg sets
+func (p *dirPolicy) apply(cfg dirConfig) {
+	if cfg.ReadOnly != nil {
+		p.readOnly = *cfg.ReadOnly
+	}
+	if cfg.DenyExtensions != nil {
+		p.denyExtensions = p.denyExtensions[:0:0]
+		for _, ext := range cfg.DenyExtensions {
+			ext = strings.ToLower(ext)
+			if !strings.HasPrefix(ext, ".") {
+				ext = "." + ext
+			}
+			p.denyExtensions = append(p.denyExtensions, ext)
+		}
+	}
+	if cfg.Hidden != nil && *cfg.Hidden {
+		p.hidden = true
+	}
+}
+
+// load returns the configuration file of dir, or an empty configuration when
+// dir has none
+func (c *dirConfigs) load(ctx context.Context, dir string) (dirConfig, error) {
+	name := path.Join(dir, dirConfigName)
+	fi, err := c.fs.Stat(ctx, name)
+	if err != nil || fi.IsDir() {
+		c.mu.Lock()
+		delete(c.cache, dir)
+		c.mu.Unlock()
+		return dirConfig{}, nil
+	}
+
+	c.mu.Lock()
+	cached, ok := c.cache[dir]
+	c.mu.Unlock()
+	if ok && cached.modTime.Equal(fi.ModTime()) && cached.size == fi.Size() {
+		return cached.config, cached.err
+	}
+
+	cached = &cachedDirConfig{modTime: fi.ModTime(), size: fi.Size()}
+	cached.config, cached.err = c.read(ctx, name)
+	c.mu.Lock()
+	c.cache[dir] = cached
+	c.mu.Unlock()
+	return cached.config, cached.err
+}
+
+// read parses the configuration file name. Unknown fields are rejected so
+// that a misspelt setting does not silently leave a directory writable.
+func (c *dirConfigs) read(ctx context.Context, name string) (dirConfig, error) {
+	f, err := c.fs.OpenFile(ctx, name, os.O_RDONLY, 0)
+	if err != nil {
+		return dirConfig{}, fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxDirConfigSize+1))
+	if err != nil {
+		return dirConfig{}, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if len(data) > maxDirConfigSize {
+		return dirConfig{}, fmt.Errorf("%s exceeds %d bytes", name, maxDirConfigSize)
+	}
+
+	var cfg dirConfig
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return dirConfig{}, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return cfg, nil
+}
+
+// dirConfigPolicy enforces the directory configuration of every request path
+// below prefix. Hidden paths and the configuration files themselves answer
+// 404. Modifications of read-only paths, and new files with a denied
+// extension, are rejected with 403. Removing or moving a collection is
+// rejected when it contains a read-only or hidden subtree.
+func dirConfigPolicy(next http.Handler, prefix string, configs *dirConfigs) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		strip := func(p string) (string, bool) {
+			rest, ok := strings.CutPrefix(p, prefix)
+			return path.Clean("/" + rest), ok && p != ""
+		}
+
+		// target is a request path with the access the request needs
+		type target struct {
+			path          string
+			write, create bool
+		}
+		var targets []target
+		var trees []string
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, "PROPFIND":
+			targets = []target{{path: r.URL.Path}}
+		case http.MethodPut:
+			targets = []target{{path: r.URL.Path, write: true, create: true}}
+		case "COPY":
+			targets = []target{{path: r.URL.Path}, {path: destinationPath(r), write: true, create: true}}
+		case "MOVE":
+			targets = []target{{path: r.URL.Path, write: true}, {path: destinationPath(r), write: true, create: true}}
+			trees = []string{r.URL.Path}
+		case http.MethodDelete:
+			targets = []target{{path: r.URL.Path, write: true}}
+			trees = []string{r.URL.Path}
+		default:
+			targets = []target{{path: r.URL.Path, write: true}}
+		}
+
+		for _, t := range targets {
+			name, ok := strip(t.path)
+			if !ok {
+				continue
+			}
+			if isDirConfigFile(name) {
+				http.Error(w, "Not Found", http.StatusNotFound)
+				return
+			}
+			policy, err := configs.policy(r.Context(), name)
+			if err != nil {
+				recordError(r, err)
+				http.Error(w, "Internal Server Error: invalid directory configuration", http.StatusInternalServerError)
+				return
+			}
+			switch {
+			case policy.hidden:
+				http.Error(w, "Not Found", http.StatusNotFound)
+				return
+			case t.write && policy.readOnly:
+				http.Error(w, "Forbidden: directory is read-only", http.StatusForbidden)
+				return
+			case t.create && policy.denies(name):
+				http.Error(w, "Forbidden: file extension not allowed", http.StatusForbidden)
+				return
+			}
+		}
+		for _, p := range trees {
+			name, ok := strip(p)
+			if ok && containsMatch(r.Context(), configs.fs, name, func(child string) bool {
+				if !isDirConfigFile(child) {
+					return false
+				}
+				policy, err := configs.policy(r.Context(), path.Dir(child))
+				return err != nil || policy.readOnly || policy.hidden
+			}) {
+				http.Error(w, "Forbidden: collection contains a protected directory", http.StatusForbidden)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// dirConfigFS leaves configuration files and hidden subtrees out of
+// directory listings
+type dirConfigFS struct {
+	webdav.FileSystem
+	configs *dirConfigs
+}
+
+func (fs dirConfigFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	f, err := fs.FileSystem.OpenFile(ctx, name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &dirConfigFile{File: f, ctx: ctx, name: name, configs: fs.configs}, nil
+}
+
+// dirConfigFile filters Readdir results of a collection
+type dirConfigFile struct {
+	webdav.File
+	ctx     context.Context
+	name    string
+	configs *dirConfigs
+}
+
+func (f *dirConfigFile) Readdir(count int) ([]os.FileInfo, error) {
+	for {
+		entries, err := f.File.Readdir(count)
+		visible := entries[:0]
+		for _, e := range entries {
+			if isDirConfigFile(e.Name()) {
+				continue
+			}
+			if e.IsDir() {
+				policy, perr := f.configs.policy(f.ctx, path.Join(f.name, e.Name()))
+				if perr != nil || policy.hidden {
+					continue
+				}
+			}
+			visible = append(visible, e)
+		}
+		// A positive count must yield at least one entry unless the end is reached
+		if count <= 0 || len(visible) > 0 || err != nil {
+			return visible, err
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newDirConfigServer serves a tree with a writable root, a read-only
+// "archive" directory and a hidden "private" directory
+func newDirConfigServer(t *testing.T) (http.Handler, string) {
+	t.Helper()
+	return newDirConfigServerWithOptions(t, Options{})
+}
+
+// newDirConfigServerWithOptions serves the tree of newDirConfigServer with
+// the directory configuration enabled in opts
+func newDirConfigServerWithOptions(t *testing.T, opts Options) (http.Handler, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	for _, sub := range []string{"archive/2025", "private", "uploads"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+	files := map[string]string{
+		"archive/.gowebdavd.json": `{"read_only": true}`,
+		"archive/report.txt":      "report",
+		"private/.gowebdavd.json": `{"hidden": true}`,
+		"private/secret.txt":      "secret",
+		"uploads/.gowebdavd.json": `{"deny_extensions": ["exe", ".BAT"]}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	opts.Folder, opts.Port, opts.Bind, opts.DirConfig = dir, 18080, "127.0.0.1", true
+	srv, err := NewWithOptions(opts, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	return srv.Handler(), dir
+}
+
+func TestDirConfigReadOnly(t *testing.T) {
+	h, dir := newDirConfigServer(t)
+
+	tests := []struct {
+		method  string
+		target  string
+		headers map[string]string
+		want    int
+	}{
+		{method: http.MethodPut, target: "/archive/new.txt", want: http.StatusForbidden},
+		{method: http.MethodPut, target: "/archive/2025/new.txt", want: http.StatusForbidden},
+		{method: http.MethodDelete, target: "/archive/report.txt", want: http.StatusForbidden},
+		{method: http.MethodDelete, target: "/archive", want: http.StatusForbidden},
+		{method: "MKCOL", target: "/archive/new", want: http.StatusForbidden},
+		{method: "COPY", target: "/notes.txt", headers: map[string]string{"Destination": "http://example.com/archive/notes.txt"}, want: http.StatusForbidden},
+		{method: http.MethodGet, target: "/archive/report.txt", want: http.StatusOK},
+		{method: http.MethodPut, target: "/notes.txt", want: http.StatusCreated},
+		{method: "COPY", target: "/archive/report.txt", headers: map[string]string{"Destination": "http://example.com/copy.txt"}, want: http.StatusCreated},
+	}
+	for _, tt := range tests {
+		rec := doRequest(h, tt.method, tt.target, "data", tt.headers)
+		if rec.Code != tt.want {
+			t.Errorf("%s %s status = %d, want %d", tt.method, tt.target, rec.Code, tt.want)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "archive", "report.txt")); err != nil {
+		t.Errorf("read-only file was removed: %v", err)
+	}
+}
+
+func TestDirConfigDenyExtensions(t *testing.T) {
+	h, _ := newDirConfigServer(t)
+
+	for target, want := range map[string]int{
+		"/uploads/setup.exe":  http.StatusForbidden,
+		"/uploads/run.bat":    http.StatusForbidden,
+		"/uploads/readme.txt": http.StatusCreated,
+		"/setup.exe":          http.StatusCreated,
+	} {
+		if rec := doRequest(h, http.MethodPut, target, "data", nil); rec.Code != want {
+			t.Errorf("PUT %s status = %d, want %d", target, rec.Code, want)
+		}
+	}
+}
+
+func TestDirConfigCaseInsensitive(t *testing.T) {
+	h, dir := newDirConfigServerWithOptions(t, Options{CaseInsensitive: true})
+
+	for _, target := range []string{"/uploads/.GOWEBDAVD.JSON", "/uploads/.Gowebdavd.json", "/archive/.GOWEBDAVD.json"} {
+		if rec := doRequest(h, http.MethodPut, target, "{}", nil); rec.Code != http.StatusNotFound {
+			t.Errorf("PUT %s status = %d, want %d", target, rec.Code, http.StatusNotFound)
+		}
+		if rec := doRequest(h, http.MethodGet, target, "", nil); rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s status = %d, want %d", target, rec.Code, http.StatusNotFound)
+		}
+	}
+	if rec := doRequest(h, http.MethodPut, "/uploads/evil.exe", "data", nil); rec.Code != http.StatusForbidden {
+		t.Errorf("PUT /uploads/evil.exe status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "uploads", ".gowebdavd.json"))
+	if err != nil || !strings.Contains(string(data), "deny_extensions") {
+		t.Errorf("configuration file = %q, %v, want it unchanged", data, err)
+	}
+}
+
+func TestDirConfigHidden(t *testing.T) {
+	h, _ := newDirConfigServer(t)
+
+	for _, target := range []string{"/private/secret.txt", "/private/", "/archive/.gowebdavd.json"} {
+		if rec := doRequest(h, http.MethodGet, target, "", nil); rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s status = %d, want %d", target, rec.Code, http.StatusNotFound)
+		}
+	}
+	if rec := doRequest(h, http.MethodPut, "/uploads/.gowebdavd.json", "{}", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("PUT of a config file status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	rec := doRequest(h, "PROPFIND", "/", "", map[string]string{"Depth": "1"})
+	if !strings.Contains(rec.Body.String(), "/archive/") {
+		t.Fatalf("PROPFIND / is missing /archive/:\n%s", rec.Body.String())
+	}
+	if strings.Contains(rec.Body.String(), "/private/") {
+		t.Errorf("PROPFIND / lists the hidden directory:\n%s", rec.Body.String())
+	}
+	rec = doRequest(h, "PROPFIND", "/archive/", "", map[string]string{"Depth": "1"})
+	if strings.Contains(rec.Body.String(), dirConfigName) {
+		t.Errorf("PROPFIND /archive/ lists the config file:\n%s", rec.Body.String())
+	}
+}
+
+func TestDirConfigInvalidation(t *testing.T) {
+	h, dir := newDirConfigServer(t)
+	config := filepath.Join(dir, "archive", dirConfigName)
+
+	if err := os.WriteFile(config, []byte(`{"read_only": false}`), 0644); err != nil {
+		t.Fatalf("Failed to update config: %v", err)
+	}
+	// Make sure the change is visible even on coarse timestamps
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(config, future, future)
+	if rec := doRequest(h, http.MethodPut, "/archive/new.txt", "data", nil); rec.Code != http.StatusCreated {
+		t.Errorf("PUT after lifting read-only status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+
+	if err := os.WriteFile(config, []byte(`{"readonly": true}`), 0644); err != nil {
+		t.Fatalf("Failed to update config: %v", err)
+	}
+	if rec := doRequest(h, http.MethodPut, "/archive/new.txt", "data", nil); rec.Code != http.StatusInternalServerError {
+		t.Errorf("PUT with an invalid config status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+
+	if err := os.Remove(config); err != nil {
+		t.Fatalf("Failed to remove config: %v", err)
+	}
+	if rec := doRequest(h, http.MethodDelete, "/archive/new.txt", "", nil); rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE after removing the config status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// davMethods lists the methods answered by the WebDAV handler
+var davMethods = []string{
+	http.MethodOptions, "LOCK", http.MethodGet, http.MethodHead, http.MethodPost, http.MethodDelete,
+	"PROPPATCH", "COPY", "MOVE", "UNLOCK", "PROPFIND", http.MethodPut, "MKCOL",
+}
+
+// searchMethod is answered in addition to davMethods with Options.Search
+const searchMethod = "SEARCH"
+
+// ParseDisabledMethods upper-cases method names to disable. OPTIONS cannot
+// be disabled, as clients need it to discover the others.
+func ParseDisabledMethods(names []string) ([]string, error) {
+	var methods []string
+	for _, name := range names {
+		m := strings.ToUpper(strings.TrimSpace(name))
+		switch {
+		case m == http.MethodOptions:
+			return nil, fmt.Errorf("cannot disable OPTIONS")
+		case !slices.Contains(davMethods, m) && m != searchMethod:
+			return nil, fmt.Errorf("unknown method: %q", name)
+		case !slices.Contains(methods, m):
+			methods = append(methods, m)
+		}
+	}
+	return methods, nil
+}
+
+// disableMethods rejects the disabled methods with 405 and an Allow header
+// listing the others, SEARCH included when search is set, and removes them
+// from the Allow header of every other response, so OPTIONS advertises only
+// the enabled methods
+func disableMethods(next http.Handler, disabled []string, search bool) http.Handler {
+	methods := davMethods
+	if search {
+		methods = append(slices.Clip(davMethods), searchMethod)
+	}
+	var enabled []string
+	for _, m := range methods {
+		if !slices.Contains(disabled, m) {
+			enabled = append(enabled, m)
+		}
+	}
+	allow := strings.Join(enabled, ", ")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slices.Contains(disabled, r.Method) {
+			w.Header().Set("Allow", allow)
+			http.Error(w, fmt.Sprintf("Method Not Allowed: %s is disabled", r.Method), http.StatusMethodNotAllowed)
+			return
+		}
+		aw := &allowFilterWriter{ResponseWriter: w, disabled: disabled}
+		next.ServeHTTP(aw, r)
+		aw.filter()
+	})
+}
+
+// allowFilterWriter removes disabled methods from the Allow header before
+// the response header is sent
+type allowFilterWriter struct {
+	http.ResponseWriter
+	disabled []string
+	filtered bool
+}
+
+// filter rewrites the Allow header once; the WebDAV handler answers OPTIONS
+// without writing, so it also runs after the handler returns
+func (w *allowFilterWriter) filter() {
+	if w.filtered {
+		return
+	}
+	w.filtered = true
+	h := w.ResponseWriter.Header()
+	if slices.Contains(w.disabled, searchMethod) {
+		// The search grammars are only advertised with the method
+		h.Del("DASL")
+	}
+	allow := h.Get("Allow")
+	if allow == "" {
+		return
+	}
+	var kept []string
+	for _, m := range strings.Split(allow, ",") {
+		if m = strings.TrimSpace(m); m != "" && !slices.Contains(w.disabled, strings.ToUpper(m)) {
+			kept = append(kept, m)
+		}
+	}
+	h.Set("Allow", strings.Join(kept, ", "))
+}
+
+func (w *allowFilterWriter) WriteHeader(code int) {
+	w.filter()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *allowFilterWriter) Write(b []byte) (int, error) {
+	w.filter()
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer
+func (w *allowFilterWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newDisabledMethodsServer(t *testing.T, methods ...string) (http.Handler, string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "song.mp3"), []byte("music"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	disabled, err := ParseDisabledMethods(methods)
+	if err != nil {
+		t.Fatalf("ParseDisabledMethods() error = %v", err)
+	}
+	srv, err := NewWithOptions(Options{Folder: tmpDir, Port: 18080, Bind: "127.0.0.1", DisabledMethods: disabled}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	return srv.Handler(), tmpDir
+}
+
+func TestDisableMethods_Rejects(t *testing.T) {
+	h, tmpDir := newDisabledMethodsServer(t, "delete", "MOVE")
+
+	for _, method := range []string{http.MethodDelete, "MOVE"} {
+		rec := doRequest(h, method, "/song.mp3", "", map[string]string{"Destination": "/moved.mp3"})
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s status = %d, want %d", method, rec.Code, http.StatusMethodNotAllowed)
+		}
+		allow := rec.Header().Get("Allow")
+		if strings.Contains(allow, method) || !strings.Contains(allow, http.MethodPut) {
+			t.Errorf("%s Allow = %q, want the enabled methods", method, allow)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "song.mp3")); err != nil {
+		t.Errorf("file gone after disabled methods: %v", err)
+	}
+
+	rec := doRequest(h, http.MethodPut, "/new.txt", "data", nil)
+	if rec.Code != http.StatusCreated {
+		t.Errorf("PUT status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+}
+
+func TestDisableMethods_Options(t *testing.T) {
+	h, _ := newDisabledMethodsServer(t, "DELETE", "PROPPATCH")
+
+	rec := doRequest(h, http.MethodOptions, "/song.mp3", "", nil)
+	allow := rec.Header().Get("Allow")
+	for _, m := range strings.Split(allow, ",") {
+		if m = strings.TrimSpace(m); m == http.MethodDelete || m == "PROPPATCH" {
+			t.Errorf("OPTIONS Allow = %q, advertises disabled %s", allow, m)
+		}
+	}
+	if !strings.Contains(allow, http.MethodPut) || !strings.Contains(allow, "PROPFIND") {
+		t.Errorf("OPTIONS Allow = %q, want the enabled methods", allow)
+	}
+}
+
+func TestDisableMethods_WithReadOnly(t *testing.T) {
+	disabled, _ := ParseDisabledMethods([]string{"PROPFIND"})
+	srv, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18080, Bind: "127.0.0.1", ReadOnly: true, DisabledMethods: disabled}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := doRequest(srv.Handler(), http.MethodOptions, "/", "", nil)
+	if allow := rec.Header().Get("Allow"); allow != "GET, HEAD, OPTIONS" {
+		t.Errorf("OPTIONS Allow = %q, want %q", allow, "GET, HEAD, OPTIONS")
+	}
+}
+
+func TestParseDisabledMethods(t *testing.T) {
+	got, err := ParseDisabledMethods([]string{"delete", " MOVE ", "Delete"})
+	if err != nil {
+		t.Fatalf("ParseDisabledMethods() error = %v", err)
+	}
+	if strings.Join(got, ",") != "DELETE,MOVE" {
+		t.Errorf("ParseDisabledMethods() = %v, want [DELETE MOVE]", got)
+	}
+	for _, bad := range []string{"OPTIONS", "FOO", ""} {
+		if _, err := ParseDisabledMethods([]string{bad}); err == nil {
+			t.Errorf("ParseDisabledMethods(%q) error = nil, want error", bad)
+		}
+	}
+}
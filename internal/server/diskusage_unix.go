@@ -0,0 +1,19 @@
+//go:build !windows
+
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import "syscall"
+
+// diskUsage reads the bytes used on the filesystem holding dir and the bytes
+// available to unprivileged users
+func diskUsage(dir string) (used, available uint64, err error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return 0, 0, err
+	}
+	bsize := uint64(st.Bsize)
+	return (uint64(st.Blocks) - uint64(st.Bfree)) * bsize, uint64(st.Bavail) * bsize, nil
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import "golang.org/x/sys/windows"
+
+// diskUsage reads the bytes used on the volume holding dir and the bytes
+// available to the calling user
+func diskUsage(dir string) (used, available uint64, err error) {
+	p, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, 0, err
+	}
+	var total, free uint64
+	if err := windows.GetDiskFreeSpaceEx(p, &available, &total, &free); err != nil {
+		return 0, 0, err
+	}
+	return total - free, available, nil
+}
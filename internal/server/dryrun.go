@@ -0,0 +1,179 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/webdav"
+
+	"gowebdavd/internal/logger"
+)
+
+// dryRunFS logs every modification instead of applying it, so the requests
+// of a client can be audited without touching the served tree. Writes are
+// discarded, and Mkdir, RemoveAll and Rename succeed as long as the real
+// call would not fail on a missing parent or an existing collection.
+// Collections that were only pretended to be created are remembered, so a
+// client can fill them and COPY can descend into its own destination.
+type dryRunFS struct {
+	webdav.FileSystem
+	logger *logger.Logger
+	dirs   *dryRunDirs
+}
+
+// dryRunDirs holds the collections a dry run pretended to create
+type dryRunDirs struct {
+	mu    sync.Mutex
+	names map[string]bool
+}
+
+func newDryRunFS(fs webdav.FileSystem, log *logger.Logger) dryRunFS {
+	return dryRunFS{FileSystem: fs, logger: log, dirs: &dryRunDirs{names: make(map[string]bool)}}
+}
+
+// pretended reports whether name was created by an earlier dry run Mkdir
+func (d *dryRunDirs) pretended(name string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.names[name]
+}
+
+func (d *dryRunDirs) add(name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.names[name] = true
+}
+
+// remove forgets name and every pretended collection below it
+func (d *dryRunDirs) remove(name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for n := range d.names {
+		if n == name || strings.HasPrefix(n, name+"/") {
+			delete(d.names, n)
+		}
+	}
+}
+
+// checkParent returns the error op on name would fail with when the parent
+// collection of name does not exist
+func (fs dryRunFS) checkParent(ctx context.Context, op, name string) error {
+	dir := path.Dir(path.Clean("/" + name))
+	if fs.dirs.pretended(dir) {
+		return nil
+	}
+	fi, err := fs.FileSystem.Stat(ctx, dir)
+	if err == nil && !fi.IsDir() {
+		err = errors.New("not a directory")
+	}
+	if err != nil {
+		return &os.PathError{Op: op, Path: name, Err: os.ErrNotExist}
+	}
+	return nil
+}
+
+func (fs dryRunFS) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	if err := fs.checkParent(ctx, "mkdir", name); err != nil {
+		return err
+	}
+	name = path.Clean("/" + name)
+	if _, err := fs.FileSystem.Stat(ctx, name); err == nil || fs.dirs.pretended(name) {
+		return &os.PathError{Op: "mkdir", Path: name, Err: os.ErrExist}
+	}
+	fs.dirs.add(name)
+	logf(fs.logger, "Dry run: create collection %s", name)
+	return nil
+}
+
+func (fs dryRunFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) == 0 {
+		return fs.FileSystem.OpenFile(ctx, name, flag, perm)
+	}
+	if err := fs.checkParent(ctx, "open", name); err != nil {
+		return nil, err
+	}
+	if fi, err := fs.FileSystem.Stat(ctx, name); err == nil && fi.IsDir() {
+		return nil, &os.PathError{Op: "open", Path: name, Err: errors.New("is a directory")}
+	}
+	logf(fs.logger, "Dry run: write %s", name)
+	return &dryRunFile{name: path.Base(name)}, nil
+}
+
+func (fs dryRunFS) RemoveAll(ctx context.Context, name string) error {
+	fs.dirs.remove(path.Clean("/" + name))
+	logf(fs.logger, "Dry run: remove %s", name)
+	return nil
+}
+
+func (fs dryRunFS) Rename(ctx context.Context, oldName, newName string) error {
+	if err := fs.checkParent(ctx, "rename", newName); err != nil {
+		return err
+	}
+	logf(fs.logger, "Dry run: rename %s to %s", oldName, newName)
+	return nil
+}
+
+// dryRunFile discards what is written to it
+type dryRunFile struct {
+	name      string
+	pos, size int64
+}
+
+func (f *dryRunFile) Close() error {
+	return nil
+}
+
+func (f *dryRunFile) Read(p []byte) (int, error) {
+	return 0, io.EOF
+}
+
+func (f *dryRunFile) Write(p []byte) (int, error) {
+	f.pos += int64(len(p))
+	f.size = max(f.size, f.pos)
+	return len(p), nil
+}
+
+func (f *dryRunFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.pos
+	case io.SeekEnd:
+		offset += f.size
+	}
+	if offset < 0 {
+		return 0, os.ErrInvalid
+	}
+	f.pos = offset
+	return offset, nil
+}
+
+func (f *dryRunFile) Readdir(count int) ([]os.FileInfo, error) {
+	return nil, os.ErrInvalid
+}
+
+func (f *dryRunFile) Stat() (os.FileInfo, error) {
+	return dryRunInfo{name: f.name, size: f.size, modTime: time.Now()}, nil
+}
+
+// dryRunInfo describes a file as if the discarded writes had been applied
+type dryRunInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (fi dryRunInfo) Name() string       { return fi.name }
+func (fi dryRunInfo) Size() int64        { return fi.size }
+func (fi dryRunInfo) Mode() os.FileMode  { return 0o644 }
+func (fi dryRunInfo) ModTime() time.Time { return fi.modTime }
+func (fi dryRunInfo) IsDir() bool        { return false }
+func (fi dryRunInfo) Sys() any           { return nil }
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/webdav"
+
+	"gowebdavd/internal/logger"
+)
+
+func newDryRunHandler(t *testing.T, opts Options) (http.Handler, string, *bytes.Buffer) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "d"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "d", "a.txt"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	opts.DryRun = true
+	return davHandler(webdav.Dir(dir), "", webdav.NewMemLS(), opts, logger.NewWithWriter(&buf, true)), dir, &buf
+}
+
+func TestDryRun_Modifications(t *testing.T) {
+	h, dir, buf := newDryRunHandler(t, Options{PreserveModTimes: true})
+
+	for _, tt := range []struct {
+		method, target string
+		headers        map[string]string
+		want           int
+		log            string
+	}{
+		{http.MethodPut, "/d/new.txt", nil, http.StatusCreated, "write /d/new.txt"},
+		{http.MethodPut, "/d/a.txt", nil, http.StatusCreated, "write /d/a.txt"},
+		{"MKCOL", "/e", nil, http.StatusCreated, "create collection /e"},
+		{http.MethodPut, "/e/f.txt", nil, http.StatusCreated, "write /e/f.txt"},
+		{"MOVE", "/d/a.txt", map[string]string{"Destination": "/d/b.txt"}, http.StatusCreated, "rename /d/a.txt to /d/b.txt"},
+		{"COPY", "/d", map[string]string{"Destination": "/c"}, http.StatusCreated, "write /c/a.txt"},
+		{http.MethodDelete, "/d", nil, http.StatusNoContent, "remove /d"},
+	} {
+		body := ""
+		if tt.method == http.MethodPut {
+			body = "data"
+		}
+		w := doRequest(h, tt.method, tt.target, body, tt.headers)
+		if w.Code != tt.want {
+			t.Errorf("%s %s status = %d, want %d", tt.method, tt.target, w.Code, tt.want)
+		}
+		if !strings.Contains(buf.String(), "Dry run: "+tt.log) {
+			t.Errorf("%s %s log = %q, want %q", tt.method, tt.target, buf.String(), tt.log)
+		}
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 || entries[0].Name() != "d" {
+		t.Errorf("served tree changed: %v", entries)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "d", "a.txt")); err != nil || string(data) != "hello" {
+		t.Errorf("a.txt = %q, %v, want it unchanged", data, err)
+	}
+	if w := doRequest(h, http.MethodGet, "/d/a.txt", "", nil); w.Body.String() != "hello" {
+		t.Errorf("GET body = %q, want the real content", w.Body.String())
+	}
+}
+
+func TestDryRun_Errors(t *testing.T) {
+	h, _, _ := newDryRunHandler(t, Options{})
+
+	if w := doRequest(h, http.MethodPut, "/missing/a.txt", "x", nil); w.Code != http.StatusConflict {
+		t.Errorf("PUT into missing collection status = %d, want %d", w.Code, http.StatusConflict)
+	}
+	if w := doRequest(h, "MKCOL", "/d", "", nil); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("MKCOL on existing collection status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+	doRequest(h, "MKCOL", "/e", "", nil)
+	if w := doRequest(h, "MKCOL", "/e", "", nil); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("second MKCOL status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+	if w := doRequest(h, http.MethodDelete, "/missing", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("DELETE of missing file status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestDryRun_Locks(t *testing.T) {
+	h, dir, _ := newDryRunHandler(t, Options{})
+	w := doRequest(h, "LOCK", "/d/new.txt", lockBody, nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("LOCK status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	token := strings.Trim(w.Header().Get("Lock-Token"), "<>")
+
+	w = doRequest(h, http.MethodPut, "/d/new.txt", "data", map[string]string{"If": "(<" + token + ">)"})
+	if w.Code != http.StatusCreated {
+		t.Errorf("PUT with lock status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	if w := doRequest(h, "UNLOCK", "/d/new.txt", "", map[string]string{"Lock-Token": "<" + token + ">"}); w.Code != http.StatusNoContent {
+		t.Errorf("UNLOCK status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "d", "new.txt")); !os.IsNotExist(err) {
+		t.Errorf("LOCK created the file: %v", err)
+	}
+}
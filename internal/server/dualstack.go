@@ -0,0 +1,52 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// Settings of IPV6_V6ONLY on IPv6 listeners
+const (
+	// DualStackOn accepts IPv4 connections, as IPv4-mapped addresses, on an
+	// IPv6 listener
+	DualStackOn = "on"
+	// DualStackOff accepts only IPv6 connections on an IPv6 listener
+	DualStackOff = "off"
+)
+
+// validateDualStack checks that mode is empty, DualStackOn or DualStackOff
+func validateDualStack(mode string) error {
+	switch mode {
+	case "", DualStackOn, DualStackOff:
+		return nil
+	}
+	return fmt.Errorf("invalid dual-stack mode %q (want %s or %s)", mode, DualStackOn, DualStackOff)
+}
+
+// dualStackControl returns the net.ListenConfig Control function setting
+// IPV6_V6ONLY on IPv6 sockets according to mode. An empty mode keeps the
+// setting of Go and the OS and returns nil.
+func dualStackControl(mode string) func(network, address string, c syscall.RawConn) error {
+	if mode == "" {
+		return nil
+	}
+	return func(network, address string, c syscall.RawConn) error {
+		if network != "tcp6" {
+			return nil
+		}
+		var serr error
+		err := c.Control(func(fd uintptr) {
+			serr = setV6Only(fd, mode == DualStackOff)
+		})
+		if err != nil {
+			return err
+		}
+		if serr != nil {
+			return fmt.Errorf("failed to set IPV6_V6ONLY: %w", serr)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import "syscall"
+
+// setV6Only sets IPV6_V6ONLY on the socket fd
+func setV6Only(fd uintptr, v6only bool) error {
+	v := 0
+	if v6only {
+		v = 1
+	}
+	return syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IPV6, syscall.IPV6_V6ONLY, v)
+}
@@ -0,0 +1,70 @@
+//go:build !windows
+
+package server
+
+import (
+	"context"
+	"net"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// listenV6Only listens on the IPv6 loopback with the control function of
+// mode and returns the IPV6_V6ONLY value of the socket
+func listenV6Only(t *testing.T, mode string) int {
+	t.Helper()
+	lc := net.ListenConfig{Control: dualStackControl(mode)}
+	ln, err := lc.Listen(context.Background(), "tcp", "[::1]:0")
+	if err != nil {
+		t.Skipf("IPv6 is not available: %v", err)
+	}
+	defer ln.Close()
+
+	rc, err := ln.(*net.TCPListener).SyscallConn()
+	if err != nil {
+		t.Fatalf("SyscallConn() error = %v", err)
+	}
+	var v int
+	var gerr error
+	rc.Control(func(fd uintptr) {
+		v, gerr = getV6Only(fd)
+	})
+	if gerr != nil {
+		t.Fatalf("getsockopt(IPV6_V6ONLY) error = %v", gerr)
+	}
+	return v
+}
+
+func TestDualStackControl(t *testing.T) {
+	if got := listenV6Only(t, DualStackOn); got != 0 {
+		t.Errorf("IPV6_V6ONLY with %q = %d, want 0", DualStackOn, got)
+	}
+	if got := listenV6Only(t, DualStackOff); got != 1 {
+		t.Errorf("IPV6_V6ONLY with %q = %d, want 1", DualStackOff, got)
+	}
+	if dualStackControl("") != nil {
+		t.Error("dualStackControl(\"\") should leave the socket alone")
+	}
+
+	// IPv4 sockets are not touched
+	control := dualStackControl(DualStackOff)
+	lc := net.ListenConfig{Control: control}
+	ln, err := lc.Listen(context.Background(), "tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen(tcp4) error = %v", err)
+	}
+	ln.Close()
+}
+
+func TestDualStackInvalid(t *testing.T) {
+	_, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18080, Bind: "::", DualStack: "both"}, nil)
+	if err == nil || !strings.Contains(err.Error(), `"both"`) {
+		t.Errorf("NewWithOptions() error = %v, want invalid dual-stack mode", err)
+	}
+}
+
+// getV6Only reads IPV6_V6ONLY of the socket fd
+func getV6Only(fd uintptr) (int, error) {
+	return syscall.GetsockoptInt(int(fd), syscall.IPPROTO_IPV6, syscall.IPV6_V6ONLY)
+}
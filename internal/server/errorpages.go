@@ -0,0 +1,94 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// loadErrorPages reads the custom error pages in dir, named by their status
+// code, e.g. 404.html. Other files are ignored.
+func loadErrorPages(dir string) (map[int][]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read error pages: %w", err)
+	}
+	pages := make(map[int][]byte)
+	for _, e := range entries {
+		code, err := strconv.Atoi(strings.TrimSuffix(e.Name(), ".html"))
+		if err != nil || !strings.HasSuffix(e.Name(), ".html") || code < 400 || code > 599 || e.IsDir() {
+			continue
+		}
+		page, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read error page: %w", err)
+		}
+		pages[code] = page
+	}
+	return pages, nil
+}
+
+// errorPages replaces error responses to clients accepting text/html with
+// the custom page for their status, keeping the status and other headers.
+// WebDAV clients and statuses without a page get the response unchanged.
+func errorPages(next http.Handler, pages map[int][]byte) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !acceptsHTML(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(&errorPageWriter{ResponseWriter: w, pages: pages, head: r.Method == http.MethodHead}, r)
+	})
+}
+
+// errorPageWriter sends the custom page in place of the body of an error
+// response that has one
+type errorPageWriter struct {
+	http.ResponseWriter
+	pages       map[int][]byte
+	head        bool
+	wroteHeader bool
+	replaced    bool
+}
+
+func (w *errorPageWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	page, ok := w.pages[code]
+	if !ok {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.replaced = true
+	h := w.Header()
+	h.Set("Content-Type", "text/html; charset=utf-8")
+	h.Set("Content-Length", strconv.Itoa(len(page)))
+	h.Del("Content-Encoding")
+	w.ResponseWriter.WriteHeader(code)
+	if !w.head {
+		w.ResponseWriter.Write(page)
+	}
+}
+
+func (w *errorPageWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.replaced {
+		// The original body is dropped
+		return len(p), nil
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *errorPageWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newErrorPagesServer(t *testing.T, opts Options) http.Handler {
+	t.Helper()
+
+	pagesDir := t.TempDir()
+	os.WriteFile(filepath.Join(pagesDir, "404.html"), []byte("<h1>Nothing here</h1>"), 0644)
+	os.WriteFile(filepath.Join(pagesDir, "403.html"), []byte("<h1>Keep out</h1>"), 0644)
+	os.WriteFile(filepath.Join(pagesDir, "notes.txt"), []byte("ignored"), 0644)
+
+	opts.Folder = t.TempDir()
+	opts.Bind = "127.0.0.1"
+	opts.ErrorPagesDir = pagesDir
+	srv, err := NewWithOptions(opts, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	return srv.Handler()
+}
+
+func TestErrorPages(t *testing.T) {
+	h := newErrorPagesServer(t, Options{})
+	browser := map[string]string{"Accept": "text/html,*/*;q=0.8"}
+
+	rec := doRequest(h, http.MethodGet, "/missing.txt", "", browser)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("GET status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if rec.Body.String() != "<h1>Nothing here</h1>" {
+		t.Errorf("GET body = %q, want the custom page", rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("Content-Type = %q, want text/html", ct)
+	}
+
+	rec = doRequest(h, http.MethodHead, "/missing.txt", "", browser)
+	if rec.Code != http.StatusNotFound || rec.Body.Len() != 0 {
+		t.Errorf("HEAD = %d with %d bytes, want %d without a body", rec.Code, rec.Body.Len(), http.StatusNotFound)
+	}
+
+	// WebDAV clients keep the default error body
+	for _, accept := range []string{"", "application/xml", "*/*"} {
+		rec = doRequest(h, http.MethodGet, "/missing.txt", "", map[string]string{"Accept": accept})
+		if rec.Code != http.StatusNotFound || strings.Contains(rec.Body.String(), "Nothing here") {
+			t.Errorf("GET with Accept %q = %d %q, want the default 404", accept, rec.Code, rec.Body.String())
+		}
+	}
+
+	// Statuses without a page pass unchanged
+	rec = doRequest(h, "PROPPATCH", "/", "<bogus", browser)
+	if strings.Contains(rec.Body.String(), "<h1>") {
+		t.Errorf("PROPPATCH %d got a custom page: %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestErrorPagesReadOnly(t *testing.T) {
+	h := newErrorPagesServer(t, Options{ReadOnly: true})
+
+	rec := doRequest(h, "LOCK", "/file.txt", lockBody, map[string]string{"Accept": "text/html"})
+	if rec.Code != http.StatusForbidden || rec.Body.String() != "<h1>Keep out</h1>" {
+		t.Errorf("LOCK in read-only mode = %d %q, want %d with the custom page", rec.Code, rec.Body.String(), http.StatusForbidden)
+	}
+}
+
+func TestErrorPagesMissingDir(t *testing.T) {
+	_, err := NewWithOptions(Options{Folder: t.TempDir(), ErrorPagesDir: filepath.Join(t.TempDir(), "missing")}, nil)
+	if err == nil {
+		t.Error("NewWithOptions() should fail for a missing error pages directory")
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+
+	"gowebdavd/internal/logger"
+)
+
+// errorSlotKey stores the *errorSlot of a request in its context
+type errorSlotKey struct{}
+
+// errorSlot receives the error behind a WebDAV handler response
+type errorSlot struct {
+	err error
+}
+
+// recordError is the WebDAV handler's error logger. It hands the error to
+// errorDetail, which decides whether and where it is shown.
+func recordError(r *http.Request, err error) {
+	if slot, ok := r.Context().Value(errorSlotKey{}).(*errorSlot); ok {
+		slot.err = err
+	}
+}
+
+// errorDetail logs the underlying error of every 5xx response. With verbose
+// set, the error message also replaces the generic response body, which can
+// expose paths and system details and is meant for debugging only.
+func errorDetail(next http.Handler, verbose bool, log *logger.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		slot := &errorSlot{}
+		r = r.WithContext(context.WithValue(r.Context(), errorSlotKey{}, slot))
+		ew := &errorWriter{ResponseWriter: w}
+		next.ServeHTTP(ew, r)
+		if ew.status == 0 {
+			return
+		}
+
+		body := ew.body.Bytes()
+		if slot.err != nil {
+			if log != nil {
+				log.Printf("%s %s %s %d error: %v", r.RemoteAddr, r.Method, r.URL.Path, ew.status, slot.err)
+			}
+			if verbose {
+				body = []byte(fmt.Sprintf("%s: %v", http.StatusText(ew.status), slot.err))
+				w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			}
+		}
+		w.Header().Del("Content-Length")
+		w.WriteHeader(ew.status)
+		w.Write(body)
+	})
+}
+
+// errorWriter holds back 5xx responses until the error behind them is known
+type errorWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *errorWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if code >= 500 {
+		w.status = code
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *errorWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.status != 0 {
+		return w.body.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/webdav"
+	"gowebdavd/internal/logger"
+)
+
+// failingLS is a lock system whose backing store is unavailable
+type failingLS struct {
+	webdav.LockSystem
+}
+
+func (failingLS) Create(now time.Time, details webdav.LockDetails) (string, error) {
+	return "", errors.New("lock store unavailable")
+}
+
+func newFailingHandler(t *testing.T, verbose bool, log *logger.Logger) http.Handler {
+	t.Helper()
+
+	h := &webdav.Handler{
+		FileSystem: webdav.Dir(t.TempDir()),
+		LockSystem: failingLS{LockSystem: webdav.NewMemLS()},
+		Logger:     recordError,
+	}
+	return errorDetail(h, verbose, log)
+}
+
+func TestVerboseErrors(t *testing.T) {
+	var buf bytes.Buffer
+	h := newFailingHandler(t, true, logger.NewWithWriter(&buf, true))
+
+	rec := doRequest(h, http.MethodPut, "/file.txt", "data", nil)
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("PUT status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if !strings.Contains(rec.Body.String(), "lock store unavailable") {
+		t.Errorf("verbose body = %q, want the underlying error", rec.Body.String())
+	}
+	if !strings.Contains(buf.String(), "PUT /file.txt 500 error: lock store unavailable") {
+		t.Errorf("error not logged:\n%s", buf.String())
+	}
+}
+
+func TestGenericErrors(t *testing.T) {
+	var buf bytes.Buffer
+	h := newFailingHandler(t, false, logger.NewWithWriter(&buf, true))
+
+	rec := doRequest(h, http.MethodPut, "/file.txt", "data", nil)
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("PUT status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if strings.Contains(rec.Body.String(), "lock store") {
+		t.Errorf("generic body = %q, should not reveal the error", rec.Body.String())
+	}
+	if rec.Body.String() != http.StatusText(http.StatusInternalServerError) {
+		t.Errorf("generic body = %q, want %q", rec.Body.String(), http.StatusText(http.StatusInternalServerError))
+	}
+	if !strings.Contains(buf.String(), "lock store unavailable") {
+		t.Errorf("error should be logged even without verbose errors:\n%s", buf.String())
+	}
+}
+
+func TestErrorDetailPassesSuccess(t *testing.T) {
+	h, _ := newIfTestServer(t)
+	h = errorDetail(h, true, nil)
+
+	rec := doRequest(h, http.MethodGet, "/file.txt", "", nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != "original" {
+		t.Errorf("GET = %d %q, want 200 %q", rec.Code, rec.Body.String(), "original")
+	}
+	rec = doRequest(h, http.MethodGet, "/missing.txt", "", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET missing status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
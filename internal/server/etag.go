@@ -0,0 +1,202 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+// ETag modes
+const (
+	// ETagModTime derives the ETag from the modification time and size of a
+	// file, as webdav.Dir does
+	ETagModTime = "mtime"
+	// ETagStrong derives the ETag from a SHA-256 hash of the file content, so
+	// it only changes with the content
+	ETagStrong = "strong"
+)
+
+// etagCacheSize bounds the number of file hashes kept by an etagCache
+const etagCacheSize = 4096
+
+// fileETag returns the ETag the WebDAV handler gives the file described by fi
+func fileETag(ctx context.Context, fi os.FileInfo) (string, error) {
+	if e, ok := fi.(webdav.ETager); ok {
+		if etag, err := e.ETag(ctx); err != webdav.ErrNotImplemented {
+			return etag, err
+		}
+	}
+	// The heuristic of the handler for files without their own ETag
+	return fmt.Sprintf(`"%x%x"`, fi.ModTime().UnixNano(), fi.Size()), nil
+}
+
+// contentETagsKey marks contexts in which files get their content ETag
+type contentETagsKey struct{}
+
+// withContentETags returns ctx with content ETags enabled
+func withContentETags(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contentETagsKey{}, true)
+}
+
+// contentETags enables content ETags for GET and HEAD requests only. Other
+// requests, PROPFIND of large collections in particular, get the default
+// ETag, so that only the files actually requested are read.
+func contentETags(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			r = r.WithContext(withContentETags(r.Context()))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// etagCache keeps the content ETags, or other content hashes, of the most
+// recently used files. An entry only matches while the modification time and size of its file are
+// unchanged.
+type etagCache struct {
+	mu      sync.Mutex
+	max     int
+	order   *list.List // of *etagEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+type etagEntry struct {
+	name    string
+	modTime time.Time
+	size    int64
+	etag    string
+}
+
+func newETagCache(max int) *etagCache {
+	return &etagCache{max: max, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (c *etagCache) get(name string, fi os.FileInfo) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[name]
+	if !ok {
+		return "", false
+	}
+	e := el.Value.(*etagEntry)
+	if !e.modTime.Equal(fi.ModTime()) || e.size != fi.Size() {
+		c.order.Remove(el)
+		delete(c.entries, name)
+		return "", false
+	}
+	c.order.MoveToFront(el)
+	return e.etag, true
+}
+
+func (c *etagCache) put(name string, fi os.FileInfo, etag string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[name]; ok {
+		c.order.Remove(el)
+	}
+	c.entries[name] = c.order.PushFront(&etagEntry{name: name, modTime: fi.ModTime(), size: fi.Size(), etag: etag})
+	for c.order.Len() > c.max {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*etagEntry).name)
+	}
+}
+
+// strongETagFS gives files strong ETags hashed from their content. The WebDAV
+// handler takes ETags from file information, so the information returned by
+// Stat, and by Stat and Readdir of opened files, carries them; wrappers
+// around this one pass it through unchanged.
+type strongETagFS struct {
+	webdav.FileSystem
+	cache *etagCache
+}
+
+func (fs strongETagFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	f, err := fs.FileSystem.OpenFile(ctx, name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &strongETagFile{File: f, fs: fs, name: name}, nil
+}
+
+func (fs strongETagFS) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	fi, err := fs.FileSystem.Stat(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return etagFileInfo{FileInfo: fi, fs: fs, name: name}, nil
+}
+
+// etag hashes the content of the file name, described by fi, unless the
+// cache holds its hash
+func (fs strongETagFS) etag(ctx context.Context, name string, fi os.FileInfo) (string, error) {
+	if etag, ok := fs.cache.get(name, fi); ok {
+		return etag, nil
+	}
+	f, err := fs.FileSystem.OpenFile(ctx, name, os.O_RDONLY, 0)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	etag := `"` + hex.EncodeToString(h.Sum(nil)) + `"`
+	// A file changed while hashing is left out, its hash may mix contents
+	if now, err := f.Stat(); err == nil && now.ModTime().Equal(fi.ModTime()) && now.Size() == fi.Size() {
+		fs.cache.put(name, fi, etag)
+	}
+	return etag, nil
+}
+
+type strongETagFile struct {
+	webdav.File
+	fs   strongETagFS
+	name string
+}
+
+func (f *strongETagFile) Stat() (os.FileInfo, error) {
+	fi, err := f.File.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return etagFileInfo{FileInfo: fi, fs: f.fs, name: f.name}, nil
+}
+
+func (f *strongETagFile) Readdir(count int) ([]os.FileInfo, error) {
+	infos, err := f.File.Readdir(count)
+	for i, fi := range infos {
+		infos[i] = etagFileInfo{FileInfo: fi, fs: f.fs, name: path.Join(f.name, fi.Name())}
+	}
+	return infos, err
+}
+
+// etagFileInfo implements webdav.ETager with the content hash of a regular
+// file, in contexts set up by withContentETags. Collections keep the default
+// ETag.
+type etagFileInfo struct {
+	os.FileInfo
+	fs   strongETagFS
+	name string
+}
+
+func (fi etagFileInfo) ETag(ctx context.Context) (string, error) {
+	if !fi.Mode().IsRegular() || ctx.Value(contentETagsKey{}) == nil {
+		return "", webdav.ErrNotImplemented
+	}
+	return fi.fs.etag(ctx, fi.name, fi.FileInfo)
+}
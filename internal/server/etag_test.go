@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+func newStrongETagHandler(t *testing.T, opts Options) (http.Handler, string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	opts.ETag = ETagStrong
+	return davHandler(webdav.Dir(dir), "", webdav.NewMemLS(), opts, nil), dir
+}
+
+func TestStrongETag_Content(t *testing.T) {
+	h, dir := newStrongETagHandler(t, Options{})
+	sum := sha256.Sum256([]byte("hello"))
+	want := `"` + hex.EncodeToString(sum[:]) + `"`
+
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		if got := doRequest(h, method, "/a.txt", "", nil).Header().Get("ETag"); got != want {
+			t.Errorf("%s ETag = %q, want %q", method, got, want)
+		}
+	}
+
+	// Touching the file keeps the tag
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "a.txt"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if got := doRequest(h, http.MethodGet, "/a.txt", "", nil).Header().Get("ETag"); got != want {
+		t.Errorf("ETag after touch = %q, want %q", got, want)
+	}
+
+	// Changing the content changes it
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("world"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := doRequest(h, http.MethodGet, "/a.txt", "", nil).Header().Get("ETag"); got == want {
+		t.Errorf("ETag after change = %q, want a new tag", got)
+	}
+}
+
+func TestStrongETag_IfNoneMatch(t *testing.T) {
+	h, _ := newStrongETagHandler(t, Options{ResponseBufferSize: 4096})
+	etag := doRequest(h, http.MethodGet, "/a.txt", "", nil).Header().Get("ETag")
+
+	w := doRequest(h, http.MethodGet, "/a.txt", "", map[string]string{"If-None-Match": etag})
+	if w.Code != http.StatusNotModified {
+		t.Errorf("GET If-None-Match status = %d, want %d", w.Code, http.StatusNotModified)
+	}
+	w = doRequest(h, http.MethodGet, "/a.txt", "", map[string]string{"If-None-Match": `"other"`})
+	if w.Code != http.StatusOK {
+		t.Errorf("GET If-None-Match other status = %d, want %d", w.Code, http.StatusOK)
+	}
+	w = doRequest(h, http.MethodPut, "/a.txt", "new", map[string]string{"If-Match": etag})
+	if w.Code != http.StatusNoContent && w.Code != http.StatusCreated {
+		t.Errorf("PUT If-Match status = %d, want success", w.Code)
+	}
+}
+
+func TestStrongETag_Propfind(t *testing.T) {
+	h, dir := newStrongETagHandler(t, Options{})
+	etag := doRequest(h, http.MethodGet, "/a.txt", "", nil).Header().Get("ETag")
+
+	// PROPFIND does not read the files it lists
+	w := doRequest(h, "PROPFIND", "/", "", map[string]string{"Depth": "1"})
+	if w.Code != http.StatusMultiStatus {
+		t.Fatalf("PROPFIND status = %d, want %d", w.Code, http.StatusMultiStatus)
+	}
+	fi, err := os.Stat(filepath.Join(dir, "a.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mtimeETag, _ := fileETag(context.Background(), fi)
+	if body := w.Body.String(); strings.Contains(body, strings.Trim(etag, `"`)) || !strings.Contains(body, mtimeETag) {
+		t.Errorf("PROPFIND body = %s, want getetag %s", body, mtimeETag)
+	}
+
+	// If headers accept the tags of both GET and PROPFIND
+	for _, tag := range []string{etag, mtimeETag} {
+		w = doRequest(h, "PROPPATCH", "/a.txt", fmt.Sprintf(colorPatch, "red"), map[string]string{"If": "([" + tag + "])"})
+		if w.Code != http.StatusMultiStatus {
+			t.Errorf("PROPPATCH If [%s] status = %d, want %d", tag, w.Code, http.StatusMultiStatus)
+		}
+	}
+	if w = doRequest(h, "PROPPATCH", "/a.txt", fmt.Sprintf(colorPatch, "red"), map[string]string{"If": `(["other"])`}); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("PROPPATCH If [other] status = %d, want %d", w.Code, http.StatusPreconditionFailed)
+	}
+}
+
+func TestETagCache_Bounded(t *testing.T) {
+	c := newETagCache(2)
+	dir := t.TempDir()
+	var infos []os.FileInfo
+	for i, name := range []string{"a", "b", "c"} {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := time.Date(2020, 1, i+1, 0, 0, 0, 0, time.UTC)
+		if err := os.Chtimes(p, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		fi, err := os.Stat(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		infos = append(infos, fi)
+	}
+	c.put("/a", infos[0], `"a"`)
+	c.put("/b", infos[1], `"b"`)
+	c.get("/a", infos[0])
+	c.put("/c", infos[2], `"c"`)
+
+	if _, ok := c.get("/b", infos[1]); ok {
+		t.Error("least recently used entry kept beyond the bound")
+	}
+	if etag, ok := c.get("/a", infos[0]); !ok || etag != `"a"` {
+		t.Errorf("get(/a) = %q, %v, want %q", etag, ok, `"a"`)
+	}
+	if _, ok := c.get("/a", infos[2]); ok {
+		t.Error("entry matched a file with another size or modification time")
+	}
+}
+
+func TestNewWithOptions_InvalidETag(t *testing.T) {
+	if _, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18080, ETag: "weak"}, nil); err == nil {
+		t.Error("NewWithOptions() accepted an invalid ETag mode")
+	}
+}
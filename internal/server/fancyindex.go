@@ -0,0 +1,216 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"html/template"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+// fancyIndexTemplate renders the sortable listing of fancyIndex
+var fancyIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Index of {{.Path}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { padding: 0.2em 1em; text-align: left; }
+td.size { text-align: right; }
+tr:nth-child(even) { background: #f4f4f4; }
+</style></head>
+<body><h1>Index of {{.Path}}</h1>
+<table>
+<thead><tr>{{range .Columns}}<th><a href="{{.Href}}">{{.Label}}</a>{{.Arrow}}</th>{{end}}</tr></thead>
+<tbody>
+{{- if .Parent}}
+<tr><td><a href="{{.Parent}}">../</a></td><td class="size">-</td><td></td></tr>
+{{- end}}
+{{- range .Entries}}
+<tr><td><a href="{{.Href}}">{{.Name}}</a></td><td class="size">{{.Size}}</td><td>{{.Modified}}</td></tr>
+{{- end}}
+</tbody></table></body></html>
+`))
+
+// fancyIndexColumns are the sort keys of the listing with their headings
+var fancyIndexColumns = []struct{ key, label string }{
+	{"name", "Name"},
+	{"size", "Size"},
+	{"modified", "Modified"},
+}
+
+type fancyIndexColumn struct {
+	Label string
+	Href  string
+	Arrow string
+}
+
+type fancyIndexEntry struct {
+	Name     string
+	Href     string
+	Size     string
+	Modified string
+}
+
+// fancyIndex answers GET and HEAD on collections from browsers, i.e. clients
+// accepting text/html, with a table of the members that can be sorted by
+// name, size or modification time with the sort and order query parameters,
+// e.g. ?sort=size&order=desc. Other clients pass unchanged.
+func fancyIndex(next http.Handler, fs webdav.FileSystem) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if (r.Method != http.MethodGet && r.Method != http.MethodHead) || !acceptsHTML(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		fi, err := fs.Stat(r.Context(), r.URL.Path)
+		if err != nil || !fi.IsDir() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := renderFancyIndex(r, fs)
+		if err != nil {
+			http.Error(w, "failed to list directory", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.Header().Add("Vary", "Accept")
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			w.Write(body)
+		}
+	})
+}
+
+// acceptsHTML reports whether the Accept header lists text/html or text/*
+// with a non-zero quality
+func acceptsHTML(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || (mediaType != "text/html" && mediaType != "text/*") {
+			continue
+		}
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// renderFancyIndex builds the sorted listing of the collection at r.URL.Path
+func renderFancyIndex(r *http.Request, fs webdav.FileSystem) ([]byte, error) {
+	dir, err := fs.OpenFile(r.Context(), r.URL.Path, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+
+	entries, err := dir.Readdir(-1)
+	if err != nil {
+		return nil, err
+	}
+
+	key := r.URL.Query().Get("sort")
+	if key != "size" && key != "modified" {
+		key = "name"
+	}
+	desc := r.URL.Query().Get("order") == "desc"
+	sortEntries(entries, key, desc)
+
+	base := r.URL.Path
+	if !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+
+	data := struct {
+		Path    string
+		Parent  string
+		Columns []fancyIndexColumn
+		Entries []fancyIndexEntry
+	}{Path: base}
+	if base != "/" {
+		data.Parent = path.Dir(strings.TrimSuffix(base, "/")) + "/"
+	}
+	for _, c := range fancyIndexColumns {
+		col := fancyIndexColumn{Label: c.label, Href: "?sort=" + c.key}
+		if c.key == key {
+			col.Arrow = " ▲"
+			if desc {
+				col.Arrow = " ▼"
+			} else {
+				col.Href += "&order=desc"
+			}
+		}
+		data.Columns = append(data.Columns, col)
+	}
+	for _, fi := range entries {
+		e := fancyIndexEntry{Name: fi.Name(), Size: "-", Modified: fi.ModTime().UTC().Format(time.DateTime)}
+		if fi.IsDir() {
+			e.Name += "/"
+		} else {
+			e.Size = formatSize(fi.Size())
+		}
+		e.Href = base + (&url.URL{Path: e.Name}).EscapedPath()
+		data.Entries = append(data.Entries, e)
+	}
+
+	var buf bytes.Buffer
+	if err := fancyIndexTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// sortEntries orders entries by key, directories first. Ties are broken by
+// name, so the order is stable across requests.
+func sortEntries(entries []os.FileInfo, key string, desc bool) {
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.IsDir() != b.IsDir() {
+			return a.IsDir()
+		}
+		var c int
+		switch key {
+		case "size":
+			c = cmp.Compare(a.Size(), b.Size())
+		case "modified":
+			c = a.ModTime().Compare(b.ModTime())
+		}
+		if c == 0 {
+			c = strings.Compare(a.Name(), b.Name())
+		}
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	})
+}
+
+// formatSize renders n bytes in the largest binary unit that keeps it at or
+// above 1
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newFancyIndexServer(t *testing.T) http.Handler {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	files := []struct {
+		name string
+		size int
+		age  time.Duration
+	}{
+		{"b.txt", 30, 2 * time.Hour},
+		{"a.txt", 10, time.Hour},
+		{"c <x>.txt", 20, 3 * time.Hour},
+	}
+	for _, f := range files {
+		p := filepath.Join(tmpDir, f.name)
+		if err := os.WriteFile(p, make([]byte, f.size), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		mtime := time.Now().Add(-f.age)
+		os.Chtimes(p, mtime, mtime)
+	}
+	os.Mkdir(filepath.Join(tmpDir, "sub"), 0755)
+
+	srv, err := NewWithOptions(Options{Folder: tmpDir, Port: 18080, Bind: "127.0.0.1", FancyIndex: true}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	return srv.Handler()
+}
+
+// listedOrder returns the names of the entries in the order they appear
+func listedOrder(body string, names ...string) []string {
+	type pos struct {
+		name string
+		at   int
+	}
+	var found []pos
+	for _, n := range names {
+		if i := strings.Index(body, ">"+n+"</a>"); i >= 0 {
+			found = append(found, pos{n, i})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].at < found[j].at })
+	order := make([]string, len(found))
+	for i, p := range found {
+		order[i] = p.name
+	}
+	return order
+}
+
+func TestFancyIndexSorting(t *testing.T) {
+	h := newFancyIndexServer(t)
+	browser := map[string]string{"Accept": "text/html,application/xhtml+xml,*/*;q=0.8"}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"/", "sub/ a.txt b.txt c &lt;x&gt;.txt"},
+		{"/?sort=name&order=desc", "sub/ c &lt;x&gt;.txt b.txt a.txt"},
+		{"/?sort=size", "sub/ a.txt c &lt;x&gt;.txt b.txt"},
+		{"/?sort=size&order=desc", "sub/ b.txt c &lt;x&gt;.txt a.txt"},
+		{"/?sort=modified", "sub/ c &lt;x&gt;.txt b.txt a.txt"},
+		{"/?sort=bogus", "sub/ a.txt b.txt c &lt;x&gt;.txt"},
+	}
+	for _, tt := range tests {
+		rec := doRequest(h, http.MethodGet, tt.query, "", browser)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s status = %d, want %d", tt.query, rec.Code, http.StatusOK)
+		}
+		got := strings.Join(listedOrder(rec.Body.String(), "sub/", "a.txt", "b.txt", "c &lt;x&gt;.txt"), " ")
+		if got != tt.want {
+			t.Errorf("GET %s order = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+
+	rec := doRequest(h, http.MethodGet, "/", "", browser)
+	body := rec.Body.String()
+	if !strings.Contains(body, `href="/c%20%3Cx%3E.txt"`) {
+		t.Errorf("listing does not escape the entry href: %s", body)
+	}
+	if !strings.Contains(body, `href="?sort=name&amp;order=desc"`) {
+		t.Errorf("listing does not offer the reverse order of the current column: %s", body)
+	}
+
+	rec = doRequest(h, http.MethodHead, "/sub", "", browser)
+	if rec.Code != http.StatusOK || rec.Body.Len() != 0 {
+		t.Errorf("HEAD /sub = %d with %d bytes, want %d without a body", rec.Code, rec.Body.Len(), http.StatusOK)
+	}
+}
+
+func TestFancyIndexNonHTMLClients(t *testing.T) {
+	h := newFancyIndexServer(t)
+
+	for _, accept := range []string{"", "application/xml", "text/html;q=0"} {
+		rec := doRequest(h, http.MethodGet, "/", "", map[string]string{"Accept": accept})
+		if strings.Contains(rec.Body.String(), "<table>") {
+			t.Errorf("GET / with Accept %q served the fancy index", accept)
+		}
+	}
+
+	rec := doRequest(h, "PROPFIND", "/", "", map[string]string{"Accept": "text/html", "Depth": "1"})
+	if rec.Code != http.StatusMultiStatus {
+		t.Errorf("PROPFIND / status = %d, want %d", rec.Code, http.StatusMultiStatus)
+	}
+
+	rec = doRequest(h, http.MethodGet, "/a.txt", "", map[string]string{"Accept": "text/html"})
+	if rec.Code != http.StatusOK || rec.Body.Len() != 10 {
+		t.Errorf("GET /a.txt = %d with %d bytes, want the file", rec.Code, rec.Body.Len())
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{0: "0 B", 1023: "1023 B", 1536: "1.5 KiB", 5 << 20: "5.0 MiB"}
+	for n, want := range tests {
+		if got := formatSize(n); got != want {
+			t.Errorf("formatSize(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
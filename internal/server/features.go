@@ -0,0 +1,61 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"net/http"
+	"strings"
+)
+
+// featuresHeader lists the optional features of the server in OPTIONS responses
+const featuresHeader = "X-GoWebDAVd-Features"
+
+// features returns the names of the optional features opts enable, in a
+// stable order. Byte ranges are always served.
+func features(opts Options) []string {
+	list := []string{"range"}
+	for _, f := range []struct {
+		name    string
+		enabled bool
+	}{
+		{"read-only", opts.ReadOnly},
+		{"zip", opts.ZipFile != ""},
+		{"mounts", len(opts.Mounts) > 0},
+		{"quota", opts.Quota > 0},
+		{"listing", opts.DirListing},
+		{"fancy-index", opts.FancyIndex},
+		{"case-insensitive", opts.CaseInsensitive},
+		{"dir-config", opts.DirConfig},
+		{"dead-props", opts.DeadProps},
+		{"delete-multistatus", opts.DeleteMultiStatus},
+		{"cross-device-move", opts.CrossDeviceMove},
+		{"preserve-mtime", opts.PreserveModTimes},
+		{"partial-put", opts.PartialPut},
+		{"dry-run", opts.DryRun},
+		{"rename-on-conflict", opts.RenameOnConflict},
+		{"batch-propfind", opts.BatchPropfind},
+		{"search", opts.Search},
+		{"checksums", opts.Checksums},
+		{"gzip", opts.Gzip},
+		{"secure-headers", opts.SecureHeaders},
+		{"metrics", opts.Metrics},
+	} {
+		if f.enabled {
+			list = append(list, f.name)
+		}
+	}
+	return list
+}
+
+// advertiseFeatures adds the features header to OPTIONS responses, next to
+// the DAV header, so that clients can adapt to the server
+func advertiseFeatures(next http.Handler, names []string) http.Handler {
+	value := strings.Join(names, ",")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			w.Header().Set(featuresHeader, value)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestAdvertiseFeatures(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{name: "defaults", want: "range"},
+		{name: "read-only listing", opts: Options{ReadOnly: true, DirListing: true}, want: "range,read-only,listing"},
+		{name: "gzip and metrics", opts: Options{Gzip: true, Metrics: true, DeleteMultiStatus: true}, want: "range,delete-multistatus,gzip,metrics"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.Folder, opts.Port, opts.Bind, opts.AdvertiseFeatures = t.TempDir(), 18080, "127.0.0.1", true
+			srv, err := NewWithOptions(opts, nil)
+			if err != nil {
+				t.Fatalf("NewWithOptions() error = %v", err)
+			}
+
+			rec := doRequest(srv.Handler(), http.MethodOptions, "/", "", nil)
+			if got := rec.Header().Get(featuresHeader); got != tt.want {
+				t.Errorf("%s = %q, want %q", featuresHeader, got, tt.want)
+			}
+			if rec.Header().Get("DAV") == "" {
+				t.Error("OPTIONS should still carry the DAV header")
+			}
+
+			rec = doRequest(srv.Handler(), http.MethodGet, "/", "", nil)
+			if got := rec.Header().Get(featuresHeader); got != "" {
+				t.Errorf("GET carries %s = %q, want it on OPTIONS only", featuresHeader, got)
+			}
+		})
+	}
+}
+
+func TestAdvertiseFeaturesDisabledByDefault(t *testing.T) {
+	rec := doRequest(New(t.TempDir(), 18080, "127.0.0.1", nil).Handler(), http.MethodOptions, "/", "", nil)
+	if got := rec.Header().Get(featuresHeader); got != "" {
+		t.Errorf("%s = %q without AdvertiseFeatures, want none", featuresHeader, got)
+	}
+}
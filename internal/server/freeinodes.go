@@ -0,0 +1,27 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import "net/http"
+
+// freeInodesFunc returns the number of inodes available to unprivileged
+// users in the filesystem holding dir
+type freeInodesFunc func(dir string) (uint64, error)
+
+// requireFreeInodes rejects requests that create files or collections below
+// dir with 507 Insufficient Storage when its filesystem has fewer than min
+// free inodes, so that a flood of small files cannot exhaust them. When the
+// count cannot be read the request is let through.
+func requireFreeInodes(next http.Handler, dir string, min uint64, free freeInodesFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut, "MKCOL", "COPY":
+			if n, err := free(dir); err == nil && n < min {
+				http.Error(w, "Insufficient Storage: too few free inodes", http.StatusInsufficientStorage)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestRequireFreeInodes(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		free   uint64
+		err    error
+		want   int
+	}{
+		{name: "PUT below reserve", method: http.MethodPut, free: 99, want: http.StatusInsufficientStorage},
+		{name: "MKCOL below reserve", method: "MKCOL", free: 0, want: http.StatusInsufficientStorage},
+		{name: "COPY below reserve", method: "COPY", free: 10, want: http.StatusInsufficientStorage},
+		{name: "PUT at reserve", method: http.MethodPut, free: 100, want: http.StatusCreated},
+		{name: "GET below reserve", method: http.MethodGet, free: 0, want: http.StatusCreated},
+		{name: "DELETE below reserve", method: http.MethodDelete, free: 0, want: http.StatusCreated},
+		{name: "statfs error", method: http.MethodPut, err: errors.New("statfs failed"), want: http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotDir string
+			free := func(dir string) (uint64, error) {
+				gotDir = dir
+				return tt.free, tt.err
+			}
+			h := requireFreeInodes(next, "/srv/data", 100, free)
+
+			rec := doRequest(h, tt.method, "/file.txt", "", nil)
+			if rec.Code != tt.want {
+				t.Errorf("%s status = %d, want %d", tt.method, rec.Code, tt.want)
+			}
+			if gotDir != "" && gotDir != "/srv/data" {
+				t.Errorf("free inodes read for %q, want /srv/data", gotDir)
+			}
+		})
+	}
+}
+
+func TestNewWithOptions_MinFreeInodes(t *testing.T) {
+	_, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18080, Bind: "127.0.0.1", MinFreeInodes: -1}, nil)
+	if err == nil {
+		t.Error("NewWithOptions() should reject a negative inode reserve")
+	}
+	if !freeInodesSupported {
+		return
+	}
+
+	tmpDir := t.TempDir()
+	free, err := statfsFreeInodes(tmpDir)
+	if err != nil {
+		t.Skipf("statfs not available: %v", err)
+	}
+	if free == 0 {
+		t.Skip("filesystem reports no inode count")
+	}
+	srv, err := NewWithOptions(Options{Folder: tmpDir, Port: 18080, Bind: "127.0.0.1", MinFreeInodes: 1}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	if rec := doRequest(srv.Handler(), http.MethodPut, "/file.txt", "data", nil); rec.Code != http.StatusCreated {
+		t.Errorf("PUT status = %d, want %d with free inodes above the reserve", rec.Code, http.StatusCreated)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !windows
+
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import "syscall"
+
+// freeInodesSupported reports whether statfsFreeInodes works on this platform
+const freeInodesSupported = true
+
+// statfsFreeInodes reads the free inode count of the filesystem holding dir
+func statfsFreeInodes(dir string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return 0, err
+	}
+	return uint64(st.Ffree), nil
+}
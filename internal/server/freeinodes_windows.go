@@ -0,0 +1,16 @@
+//go:build windows
+
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import "errors"
+
+// freeInodesSupported reports whether statfsFreeInodes works on this platform
+const freeInodesSupported = false
+
+// statfsFreeInodes is unavailable on Windows, which has no inode limit
+func statfsFreeInodes(dir string) (uint64, error) {
+	return 0, errors.New("free inode count is not available on Windows")
+}
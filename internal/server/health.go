@@ -0,0 +1,73 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// Health endpoint defaults
+const (
+	healthPath          = "/health"
+	livezPath           = "/livez"
+	readyzPath          = "/readyz"
+	DefaultHealthBody   = "OK"
+	DefaultHealthStatus = http.StatusOK
+)
+
+// notReady returns why the server cannot take requests, empty when it can.
+// It is not ready while it starts or drains, and with deep set while a root
+// is inaccessible, e.g. a network share unmounted underneath the server.
+func notReady(ready func() bool, roots []string, deep bool) string {
+	if !ready() {
+		return "not ready"
+	}
+	if deep {
+		for _, root := range roots {
+			if _, err := os.Stat(root); err != nil {
+				return "root unavailable"
+			}
+		}
+	}
+	return ""
+}
+
+// healthHandler answers health probes with the configured body and status
+// code while the server is ready, and with 503 otherwise. Roots are only
+// checked with ?deep=1.
+func healthHandler(body string, code int, ready func() bool, roots []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if reason := notReady(ready, roots, r.URL.Query().Get("deep") == "1"); reason != "" {
+			writeProbe(w, http.StatusServiceUnavailable, reason)
+			return
+		}
+		writeProbe(w, code, body)
+	}
+}
+
+// readyzHandler answers readiness probes, which always check the roots
+func readyzHandler(ready func() bool, roots []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if reason := notReady(ready, roots, true); reason != "" {
+			writeProbe(w, http.StatusServiceUnavailable, reason)
+			return
+		}
+		writeProbe(w, http.StatusOK, DefaultHealthBody)
+	}
+}
+
+// livezHandler answers liveness probes for as long as the process serves
+// requests at all, including while it drains
+func livezHandler(w http.ResponseWriter, r *http.Request) {
+	writeProbe(w, http.StatusOK, DefaultHealthBody)
+}
+
+func writeProbe(w http.ResponseWriter, code int, body string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	fmt.Fprint(w, body)
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHealthDefault(t *testing.T) {
+	srv := New(t.TempDir(), 18080, "127.0.0.1", nil)
+	srv.ready.Store(true)
+
+	rec := doRequest(srv.routes(), http.MethodGet, "/health", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET /health status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec.Body.String() != DefaultHealthBody {
+		t.Errorf("GET /health body = %q, want %q", rec.Body.String(), DefaultHealthBody)
+	}
+}
+
+// start waits for /livez, so it must not follow -health-status
+func TestLivezIgnoresHealthStatus(t *testing.T) {
+	srv, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18080, Bind: "127.0.0.1", HealthStatus: http.StatusServiceUnavailable}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	srv.ready.Store(true)
+
+	if rec := doRequest(srv.routes(), http.MethodGet, "/livez", "", nil); rec.Code != http.StatusOK {
+		t.Errorf("GET /livez status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestHealthCustomBodyAndStatus(t *testing.T) {
+	opts := Options{
+		Folder:       t.TempDir(),
+		Port:         18080,
+		Bind:         "127.0.0.1",
+		HealthBody:   "gowebdavd alive",
+		HealthStatus: http.StatusAccepted,
+	}
+	srv, err := NewWithOptions(opts, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	srv.ready.Store(true)
+
+	rec := doRequest(srv.routes(), http.MethodGet, "/health", "", nil)
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("GET /health status = %d, want %d", rec.Code, http.StatusAccepted)
+	}
+	if rec.Body.String() != "gowebdavd alive" {
+		t.Errorf("GET /health body = %q, want %q", rec.Body.String(), "gowebdavd alive")
+	}
+}
+
+func TestHealthInvalidStatus(t *testing.T) {
+	_, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18080, Bind: "127.0.0.1", HealthStatus: 1000}, nil)
+	if err == nil {
+		t.Error("NewWithOptions() should reject an invalid health status code")
+	}
+}
+
+func TestRoutesPassThrough(t *testing.T) {
+	srv := New(t.TempDir(), 18080, "127.0.0.1", nil)
+
+	rec := doRequest(srv.routes(), "PROPFIND", "/", "", map[string]string{"Depth": "0"})
+	if rec.Code != http.StatusMultiStatus {
+		t.Errorf("PROPFIND / status = %d, want %d", rec.Code, http.StatusMultiStatus)
+	}
+}
+
+func TestHealthNotReady(t *testing.T) {
+	srv := New(t.TempDir(), 18080, "127.0.0.1", nil)
+
+	rec := doRequest(srv.routes(), http.MethodGet, "/health", "", nil)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("GET /health before serving status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestHealthServeAndShutdown(t *testing.T) {
+	srv := New(t.TempDir(), 0, "127.0.0.1", nil)
+	url, done := startTestServer(t, srv)
+
+	resp, err := http.Get(url + healthPath)
+	if err != nil {
+		t.Fatalf("GET %s error = %v", healthPath, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET %s while serving status = %d, want %d", healthPath, resp.StatusCode, http.StatusOK)
+	}
+
+	srv.shutdown()
+	waitServe(t, done)
+	rec := doRequest(srv.routes(), http.MethodGet, healthPath, "", nil)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("GET %s after shutdown status = %d, want %d", healthPath, rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestHealthDeep(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mnt")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	srv := New(dir, 18080, "127.0.0.1", nil)
+	srv.ready.Store(true)
+
+	if rec := doRequest(srv.routes(), http.MethodGet, "/health?deep=1", "", nil); rec.Code != http.StatusOK {
+		t.Errorf("GET /health?deep=1 status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if err := os.Remove(dir); err != nil {
+		t.Fatal(err)
+	}
+	if rec := doRequest(srv.routes(), http.MethodGet, "/health", "", nil); rec.Code != http.StatusOK {
+		t.Errorf("GET /health with a missing root status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec := doRequest(srv.routes(), http.MethodGet, "/health?deep=1", "", nil); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("GET /health?deep=1 with a missing root status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestLivezAndReadyz(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mnt")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	srv, err := NewWithOptions(Options{Folder: dir, Port: 18080, Bind: "127.0.0.1", HealthStatus: http.StatusNoContent}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+
+	probe := func(path string, want int) {
+		t.Helper()
+		if rec := doRequest(srv.routes(), http.MethodGet, path, "", nil); rec.Code != want {
+			t.Errorf("GET %s status = %d, want %d", path, rec.Code, want)
+		}
+	}
+
+	// Starting
+	probe(livezPath, http.StatusOK)
+	probe(readyzPath, http.StatusServiceUnavailable)
+
+	srv.ready.Store(true)
+	probe(livezPath, http.StatusOK)
+	probe(readyzPath, http.StatusOK)
+	probe(healthPath, http.StatusNoContent)
+
+	if err := os.Remove(dir); err != nil {
+		t.Fatal(err)
+	}
+	probe(livezPath, http.StatusOK)
+	probe(readyzPath, http.StatusServiceUnavailable)
+	probe(healthPath, http.StatusNoContent)
+
+	// Draining
+	srv.ready.Store(false)
+	probe(livezPath, http.StatusOK)
+	probe(healthPath, http.StatusServiceUnavailable)
+}
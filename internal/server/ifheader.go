@@ -0,0 +1,109 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+// conditionLS wraps a webdav.LockSystem so that Confirm evaluates every kind
+// of If header condition (RFC 4918 §10.4). The webdav handler parses the header
+// into Conditions, but the in-memory lock system only understands plain lock
+// tokens and treats "Not <token>" as a positive match and "[etag]" as a failure.
+type conditionLS struct {
+	webdav.LockSystem
+	fs webdav.FileSystem
+}
+
+// newConditionLS wraps ls, resolving entity tags against fs
+func newConditionLS(ls webdav.LockSystem, fs webdav.FileSystem) *conditionLS {
+	return &conditionLS{LockSystem: ls, fs: fs}
+}
+
+// Confirm reports whether the conditions of a single If header list hold for
+// name0. Not and ETag conditions are evaluated here, positive lock tokens are
+// confirmed by the wrapped lock system. A list without positive lock tokens
+// still has to respect locks held by other clients, so temporary locks are
+// taken exactly as the handler does for requests without an If header.
+func (l *conditionLS) Confirm(now time.Time, name0, name1 string, conditions ...webdav.Condition) (func(), error) {
+	var tokens []webdav.Condition
+	for _, c := range conditions {
+		var holds bool
+		switch {
+		case c.ETag != "":
+			holds = l.etagMatches(name0, c.ETag)
+		case c.Not:
+			holds = l.tokenLocks(now, name0, c.Token)
+		default:
+			tokens = append(tokens, c)
+			continue
+		}
+		if holds == c.Not {
+			return nil, webdav.ErrConfirmationFailed
+		}
+	}
+
+	if len(tokens) > 0 {
+		return l.LockSystem.Confirm(now, name0, name1, tokens...)
+	}
+	return l.lockTemporarily(now, name0, name1)
+}
+
+// etagMatches reports whether etag is the current entity tag of name, as
+// reported by PROPFIND or, with content ETags, by GET
+func (l *conditionLS) etagMatches(name, etag string) bool {
+	ctx := context.Background()
+	fi, err := l.fs.Stat(ctx, name)
+	if err != nil {
+		return false
+	}
+	if current, err := fileETag(ctx, fi); err == nil && current == etag {
+		return true
+	}
+	current, err := fileETag(withContentETags(ctx), fi)
+	return err == nil && current == etag
+}
+
+// tokenLocks reports whether token identifies a lock covering name
+func (l *conditionLS) tokenLocks(now time.Time, name, token string) bool {
+	release, err := l.LockSystem.Confirm(now, name, "", webdav.Condition{Token: token})
+	if err != nil {
+		return false
+	}
+	release()
+	return true
+}
+
+// lockTemporarily takes zero-depth locks on the named resources, failing the
+// confirmation when another client already holds a lock on either of them
+func (l *conditionLS) lockTemporarily(now time.Time, names ...string) (func(), error) {
+	var held []string
+	release := func() {
+		for _, token := range held {
+			l.LockSystem.Unlock(now, token)
+		}
+	}
+	for i, name := range names {
+		if name == "" || (i > 0 && name == names[0]) {
+			continue
+		}
+		token, err := l.LockSystem.Create(now, webdav.LockDetails{
+			Root:      name,
+			Duration:  -1,
+			ZeroDepth: true,
+		})
+		if err != nil {
+			release()
+			if err == webdav.ErrLocked {
+				return nil, webdav.ErrConfirmationFailed
+			}
+			return nil, err
+		}
+		held = append(held, token)
+	}
+	return release, nil
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const lockBody = `<?xml version="1.0" encoding="utf-8"?>
+<D:lockinfo xmlns:D="DAV:">
+  <D:lockscope><D:exclusive/></D:lockscope>
+  <D:locktype><D:write/></D:locktype>
+  <D:owner>test</D:owner>
+</D:lockinfo>`
+
+// doRequest sends a request through h and returns the recorded response
+func doRequest(h http.Handler, method, target, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// lockFile locks target and returns the lock token without angle brackets
+func lockFile(t *testing.T, h http.Handler, target string) string {
+	t.Helper()
+
+	rec := doRequest(h, "LOCK", target, lockBody, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("LOCK status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	token := strings.Trim(rec.Header().Get("Lock-Token"), "<>")
+	if token == "" {
+		t.Fatal("LOCK returned no Lock-Token")
+	}
+	return token
+}
+
+func newIfTestServer(t *testing.T) (http.Handler, string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "file.txt"), []byte("original"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	return New(tmpDir, 18080, "127.0.0.1", nil).Handler(), tmpDir
+}
+
+func TestIfHeaderLockToken(t *testing.T) {
+	h, _ := newIfTestServer(t)
+	token := lockFile(t, h, "/file.txt")
+
+	rec := doRequest(h, http.MethodPut, "/file.txt", "updated", nil)
+	if rec.Code != http.StatusLocked {
+		t.Errorf("PUT without If status = %d, want %d", rec.Code, http.StatusLocked)
+	}
+
+	rec = doRequest(h, http.MethodPut, "/file.txt", "updated", map[string]string{
+		"If": "(<" + token + ">)",
+	})
+	if rec.Code != http.StatusNoContent && rec.Code != http.StatusCreated {
+		t.Errorf("PUT with lock token status = %d, want success", rec.Code)
+	}
+}
+
+func TestIfHeaderETag(t *testing.T) {
+	h, tmpDir := newIfTestServer(t)
+
+	etag := doRequest(h, http.MethodGet, "/file.txt", "", nil).Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("GET returned no ETag")
+	}
+
+	rec := doRequest(h, http.MethodPut, "/file.txt", "stale", map[string]string{
+		"If": `(["bogus"])`,
+	})
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("PUT with wrong ETag status = %d, want %d", rec.Code, http.StatusPreconditionFailed)
+	}
+
+	rec = doRequest(h, http.MethodPut, "/file.txt", "updated", map[string]string{
+		"If": "([" + etag + "])",
+	})
+	if rec.Code != http.StatusNoContent && rec.Code != http.StatusCreated {
+		t.Errorf("PUT with matching ETag status = %d, want success", rec.Code)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "file.txt"))
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(data) != "updated" {
+		t.Errorf("file content = %q, want %q", data, "updated")
+	}
+}
+
+func TestIfHeaderNotToken(t *testing.T) {
+	h, _ := newIfTestServer(t)
+
+	// Unlocked resource: "Not <DAV:no-lock>" always holds
+	rec := doRequest(h, http.MethodPut, "/file.txt", "updated", map[string]string{
+		"If": "(Not <DAV:no-lock>)",
+	})
+	if rec.Code != http.StatusNoContent && rec.Code != http.StatusCreated {
+		t.Errorf("PUT with Not no-lock status = %d, want success", rec.Code)
+	}
+
+	token := lockFile(t, h, "/file.txt")
+
+	// Not of the token actually locking the resource must fail
+	rec = doRequest(h, http.MethodPut, "/file.txt", "updated", map[string]string{
+		"If": "(Not <" + token + ">)",
+	})
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("PUT with Not lock token status = %d, want %d", rec.Code, http.StatusPreconditionFailed)
+	}
+
+	// A satisfied Not condition does not bypass another client's lock
+	rec = doRequest(h, http.MethodPut, "/file.txt", "updated", map[string]string{
+		"If": "(Not <DAV:no-lock>)",
+	})
+	if rec.Code == http.StatusNoContent || rec.Code == http.StatusCreated {
+		t.Errorf("PUT on locked resource with Not no-lock status = %d, want failure", rec.Code)
+	}
+
+	// Not combined with the owned token succeeds
+	rec = doRequest(h, http.MethodPut, "/file.txt", "updated", map[string]string{
+		"If": "(<" + token + "> Not <DAV:no-lock>)",
+	})
+	if rec.Code != http.StatusNoContent && rec.Code != http.StatusCreated {
+		t.Errorf("PUT with token and Not no-lock status = %d, want success", rec.Code)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"golang.org/x/net/webdav"
+)
+
+// validateIndex rejects index file names that are not a single path element
+func validateIndex(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid index file name %q", name)
+	}
+	return nil
+}
+
+// serveIndex answers GET and HEAD on a collection holding a regular file
+// called name with that file, as a static web server does. Collection URLs
+// without a trailing slash are redirected to one first, so relative links in
+// the page resolve inside the collection. Other methods, and collections
+// without the file, pass unchanged.
+func serveIndex(next http.Handler, fs webdav.FileSystem, name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		fi, err := fs.Stat(r.Context(), r.URL.Path)
+		if err != nil || !fi.IsDir() {
+			next.ServeHTTP(w, r)
+			return
+		}
+		index := path.Join(r.URL.Path, name)
+		if fi, err := fs.Stat(r.Context(), index); err != nil || !fi.Mode().IsRegular() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			target := r.URL.EscapedPath() + "/"
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
+			return
+		}
+
+		sub := r.Clone(r.Context())
+		sub.URL.Path, sub.URL.RawPath = index, ""
+		next.ServeHTTP(w, sub)
+	})
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestServeIndex(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "index.html"), []byte("<h1>Home</h1>"), 0644)
+	os.Mkdir(filepath.Join(tmpDir, "site"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "site", "index.html"), []byte("<h1>Site</h1>"), 0644)
+	os.Mkdir(filepath.Join(tmpDir, "plain"), 0755)
+	os.Mkdir(filepath.Join(tmpDir, "odd"), 0755)
+	os.Mkdir(filepath.Join(tmpDir, "odd", "index.html"), 0755)
+
+	srv, err := NewWithOptions(Options{Folder: tmpDir, Bind: "127.0.0.1", Index: "index.html", DirListing: true}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	h := srv.Handler()
+
+	rec := doRequest(h, http.MethodGet, "/", "", nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != "<h1>Home</h1>" {
+		t.Errorf("GET / = %d %q, want the index file", rec.Code, rec.Body.String())
+	}
+	rec = doRequest(h, http.MethodGet, "/site/", "", nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != "<h1>Site</h1>" {
+		t.Errorf("GET /site/ = %d %q, want the index file", rec.Code, rec.Body.String())
+	}
+	get := rec
+	rec = doRequest(h, http.MethodHead, "/site/", "", nil)
+	if rec.Code != http.StatusOK || rec.Body.Len() != 0 {
+		t.Errorf("HEAD /site/ = %d with %d bytes, want %d without a body", rec.Code, rec.Body.Len(), http.StatusOK)
+	}
+	// HEAD describes the same index file as GET
+	if got := rec.Header().Get("Content-Length"); got != strconv.Itoa(len("<h1>Site</h1>")) {
+		t.Errorf("HEAD /site/ Content-Length = %q, want %d", got, len("<h1>Site</h1>"))
+	}
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/html") || got != get.Header().Get("Content-Type") {
+		t.Errorf("HEAD /site/ Content-Type = %q, want %q as for GET", got, get.Header().Get("Content-Type"))
+	}
+
+	rec = doRequest(h, http.MethodGet, "/site?x=1", "", nil)
+	if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/site/?x=1" {
+		t.Errorf("GET /site = %d to %q, want a redirect to /site/?x=1", rec.Code, rec.Header().Get("Location"))
+	}
+
+	// Directories without the file, or with a directory of that name, are listed
+	for _, dir := range []string{"/plain/", "/odd/"} {
+		rec = doRequest(h, http.MethodGet, dir, "", nil)
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Index of "+dir) {
+			t.Errorf("GET %s = %d %q, want the listing", dir, rec.Code, rec.Body.String())
+		}
+	}
+
+	rec = doRequest(h, "PROPFIND", "/site/", "", map[string]string{"Depth": "1"})
+	if rec.Code != http.StatusMultiStatus || !strings.Contains(rec.Body.String(), "/site/index.html") {
+		t.Errorf("PROPFIND /site/ = %d, want the collection members", rec.Code)
+	}
+}
+
+func TestServeIndexInvalidName(t *testing.T) {
+	for _, name := range []string{"..", "a/index.html", `a\index.html`} {
+		if _, err := NewWithOptions(Options{Folder: t.TempDir(), Index: name}, nil); err == nil {
+			t.Errorf("NewWithOptions() with index %q should fail", name)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gowebdavd/internal/pidfile"
+)
+
+// directoryLockPath returns the lock file reserving dir, keyed by its absolute
+// path so the served tree itself is not polluted
+func directoryLockPath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve directory: %w", err)
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(os.TempDir(), fmt.Sprintf("gowebdavd-%x.pid", sum[:8])), nil
+}
+
+// lockDirectory acquires the advisory lock reserving dir for this process and
+// returns a function releasing it
+func lockDirectory(dir string) (func(), error) {
+	path, err := directoryLockPath(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pf := pidfile.NewWithPath(path)
+	if err := pf.Lock(); err != nil {
+		if !errors.Is(err, pidfile.ErrLocked) {
+			return nil, fmt.Errorf("failed to lock directory: %w", err)
+		}
+		if pid, err := pf.Read(); err == nil {
+			return nil, fmt.Errorf("directory %s is already served by another instance (PID: %d)", dir, pid)
+		}
+		return nil, fmt.Errorf("directory %s is already served by another instance", dir)
+	}
+	pf.Write(os.Getpid())
+
+	return func() {
+		pf.Remove()
+		pf.Unlock()
+	}, nil
+}
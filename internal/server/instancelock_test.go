@@ -0,0 +1,50 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLockDirectory(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	release, err := lockDirectory(tmpDir)
+	if err != nil {
+		t.Fatalf("lockDirectory() error = %v", err)
+	}
+
+	if _, err := lockDirectory(tmpDir); err == nil {
+		t.Error("second lockDirectory() should fail while the lock is held")
+	}
+
+	release()
+
+	release, err = lockDirectory(tmpDir)
+	if err != nil {
+		t.Fatalf("lockDirectory() after release error = %v", err)
+	}
+	release()
+}
+
+func TestStartSingleInstanceLock(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	release, err := lockDirectory(tmpDir)
+	if err != nil {
+		t.Fatalf("lockDirectory() error = %v", err)
+	}
+	defer release()
+
+	srv, err := NewWithOptions(Options{Folder: tmpDir, Port: 0, Bind: "127.0.0.1", SingleInstanceLock: true}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+
+	err = srv.Start()
+	if err == nil {
+		t.Fatal("Start() should fail when another instance holds the directory lock")
+	}
+	if !strings.Contains(err.Error(), "already served by another instance") {
+		t.Errorf("Start() error = %v, want directory lock error", err)
+	}
+}
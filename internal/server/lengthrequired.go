@@ -0,0 +1,20 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"net/http"
+)
+
+// requireContentLength rejects PUT requests whose size is not known up
+// front, such as chunked uploads, with 411 Length Required
+func requireContentLength(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && r.ContentLength < 0 {
+			http.Error(w, "Length Required", http.StatusLengthRequired)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRequireContentLength(t *testing.T) {
+	tmpDir := t.TempDir()
+	srv, err := NewWithOptions(Options{Folder: tmpDir, Port: 18080, Bind: "127.0.0.1", ContentLengthRequired: true}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	h := srv.Handler()
+
+	req := httptest.NewRequest(http.MethodPut, "/chunked.txt", strings.NewReader("chunked data"))
+	req.ContentLength = -1
+	req.TransferEncoding = []string{"chunked"}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusLengthRequired {
+		t.Errorf("chunked PUT status = %d, want %d", rec.Code, http.StatusLengthRequired)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "chunked.txt")); !os.IsNotExist(err) {
+		t.Error("chunked PUT should not create the file")
+	}
+
+	rec = doRequest(h, http.MethodPut, "/sized.txt", "sized data", nil)
+	if rec.Code != http.StatusCreated {
+		t.Errorf("PUT with Content-Length status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+
+	rec = doRequest(h, http.MethodPut, "/empty.txt", "", nil)
+	if rec.Code != http.StatusCreated {
+		t.Errorf("empty PUT status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+}
+
+func TestChunkedPutAllowedByDefault(t *testing.T) {
+	h, _ := newIfTestServer(t)
+
+	req := httptest.NewRequest(http.MethodPut, "/chunked.txt", strings.NewReader("chunked data"))
+	req.ContentLength = -1
+	req.TransferEncoding = []string{"chunked"}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Errorf("chunked PUT status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+}
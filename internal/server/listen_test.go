@@ -0,0 +1,21 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestStartAddrInUse(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	srv := New(t.TempDir(), port, "127.0.0.1", nil)
+	if err := srv.Start(); !errors.Is(err, ErrAddrInUse) {
+		t.Errorf("Start() error = %v, want %v", err, ErrAddrInUse)
+	}
+}
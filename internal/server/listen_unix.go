@@ -0,0 +1,16 @@
+//go:build !windows
+
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"errors"
+	"syscall"
+)
+
+// IsAddrInUse reports whether err is a listen failing with EADDRINUSE
+func IsAddrInUse(err error) bool {
+	return errors.Is(err, syscall.EADDRINUSE)
+}
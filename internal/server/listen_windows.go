@@ -0,0 +1,18 @@
+//go:build windows
+
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"errors"
+
+	"golang.org/x/sys/windows"
+)
+
+// IsAddrInUse reports whether err is a listen failing because another
+// socket is bound to the address
+func IsAddrInUse(err error) bool {
+	return errors.Is(err, windows.WSAEADDRINUSE)
+}
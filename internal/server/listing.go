@@ -0,0 +1,124 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/webdav"
+)
+
+// dirListing answers GET and HEAD on collections with an HTML listing whenever
+// the WebDAV handler produces no meaningful body for them. x/net/webdav refuses
+// directory GETs, and other versions may reply with an empty body.
+func dirListing(next http.Handler, fs webdav.FileSystem) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		fi, err := fs.Stat(r.Context(), r.URL.Path)
+		if err != nil || !fi.IsDir() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := newBufferedResponse()
+		next.ServeHTTP(rec, r)
+		if rec.status >= 200 && rec.status < 300 && len(bytes.TrimSpace(rec.body.Bytes())) > 0 {
+			rec.replay(w)
+			return
+		}
+
+		body, err := renderListing(r, fs)
+		if err != nil {
+			http.Error(w, "failed to list directory", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			w.Write(body)
+		}
+	})
+}
+
+// renderListing builds a plain HTML listing of the collection at r.URL.Path
+func renderListing(r *http.Request, fs webdav.FileSystem) ([]byte, error) {
+	dir, err := fs.OpenFile(r.Context(), r.URL.Path, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+
+	entries, err := dir.Readdir(-1)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	base := r.URL.Path
+	if !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+
+	var buf bytes.Buffer
+	title := html.EscapeString(base)
+	fmt.Fprintf(&buf, "<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>Index of %s</title></head>\n", title)
+	fmt.Fprintf(&buf, "<body><h1>Index of %s</h1>\n<ul>\n", title)
+	if base != "/" {
+		fmt.Fprintf(&buf, "<li><a href=\"%s\">../</a></li>\n", html.EscapeString(path.Dir(strings.TrimSuffix(base, "/"))+"/"))
+	}
+	for _, fi := range entries {
+		name := fi.Name()
+		if fi.IsDir() {
+			name += "/"
+		}
+		href := base + (&url.URL{Path: name}).EscapedPath()
+		fmt.Fprintf(&buf, "<li><a href=\"%s\">%s</a></li>\n", html.EscapeString(href), html.EscapeString(name))
+	}
+	buf.WriteString("</ul></body></html>\n")
+	return buf.Bytes(), nil
+}
+
+// bufferedResponse records a complete response so it can be inspected before
+// being sent to the client
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newBufferedResponse() *bufferedResponse {
+	return &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(code int) {
+	b.status = code
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	return b.body.Write(p)
+}
+
+// replay sends the recorded response to w
+func (b *bufferedResponse) replay(w http.ResponseWriter) {
+	for k, v := range b.header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(b.status)
+	w.Write(b.body.Bytes())
+}
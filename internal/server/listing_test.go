@@ -0,0 +1,87 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/webdav"
+)
+
+func newListingDir(t *testing.T) string {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "a file.txt"), []byte("a"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(tmpDir, "sub"), 0755); err != nil {
+		t.Fatalf("Failed to create test dir: %v", err)
+	}
+	return tmpDir
+}
+
+func TestDirListingEmptyBody(t *testing.T) {
+	tmpDir := newListingDir(t)
+
+	// Simulate a handler that answers directory GETs with an empty body
+	empty := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	h := dirListing(empty, webdav.Dir(tmpDir))
+
+	rec := doRequest(h, http.MethodGet, "/", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET / status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, `href="/a%20file.txt"`) || !strings.Contains(body, `href="/sub/"`) {
+		t.Errorf("listing does not contain the directory entries: %s", body)
+	}
+}
+
+func TestDirListingWebDAVHandler(t *testing.T) {
+	tmpDir := newListingDir(t)
+	srv, err := NewWithOptions(Options{Folder: tmpDir, Port: 18080, Bind: "127.0.0.1", DirListing: true}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+
+	rec := doRequest(srv.Handler(), http.MethodGet, "/sub", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /sub status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if !strings.Contains(rec.Body.String(), "Index of /sub/") {
+		t.Errorf("GET /sub body = %s, want a listing", rec.Body.String())
+	}
+
+	rec = doRequest(srv.Handler(), http.MethodHead, "/", "", nil)
+	if rec.Code != http.StatusOK || rec.Body.Len() != 0 {
+		t.Errorf("HEAD / = %d with %d body bytes, want 200 without body", rec.Code, rec.Body.Len())
+	}
+	if rec.Header().Get("Content-Length") == "" {
+		t.Error("HEAD / should report the listing Content-Length")
+	}
+
+	rec = doRequest(srv.Handler(), http.MethodGet, "/a%20file.txt", "", nil)
+	if rec.Body.String() != "a" {
+		t.Errorf("GET file body = %q, want %q", rec.Body.String(), "a")
+	}
+}
+
+func TestDirListingKeepsHandlerBody(t *testing.T) {
+	tmpDir := newListingDir(t)
+
+	custom := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Custom", "yes")
+		w.Write([]byte("custom listing"))
+	})
+	h := dirListing(custom, webdav.Dir(tmpDir))
+
+	rec := doRequest(h, http.MethodGet, "/", "", nil)
+	if rec.Body.String() != "custom listing" || rec.Header().Get("X-Custom") != "yes" {
+		t.Errorf("GET / = %q, want the handler's own listing", rec.Body.String())
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+// LockMode selects the lock system of the served roots
+type LockMode string
+
+// Lock systems selectable with Options.LockMode
+const (
+	// LockModeMemory grants and enforces locks in memory
+	LockModeMemory LockMode = "memory"
+	// LockModeNone answers every LOCK, UNLOCK and refresh with success and
+	// enforces nothing
+	LockModeNone LockMode = "none"
+	// LockModeReadOnly answers LOCK with a token but never enforces it, for
+	// interoperability tests with clients that insist on locking
+	LockModeReadOnly LockMode = "readonly"
+)
+
+// validate checks that m names a lock system; empty means LockModeMemory
+func (m LockMode) validate() error {
+	switch m {
+	case "", LockModeMemory, LockModeNone, LockModeReadOnly:
+		return nil
+	}
+	return fmt.Errorf("invalid lock mode %q (want %s, %s or %s)", string(m), LockModeMemory, LockModeNone, LockModeReadOnly)
+}
+
+// newLockSystem returns a new lock system for mode
+func newLockSystem(mode LockMode) webdav.LockSystem {
+	switch mode {
+	case LockModeNone:
+		return noOpLS{}
+	case LockModeReadOnly:
+		return readOnlyLS{}
+	}
+	return webdav.NewMemLS()
+}
+
+// noOpLS grants every lock request without recording anything
+type noOpLS struct{}
+
+func (noOpLS) Confirm(now time.Time, name0, name1 string, conditions ...webdav.Condition) (func(), error) {
+	return func() {}, nil
+}
+
+func (noOpLS) Create(now time.Time, details webdav.LockDetails) (string, error) {
+	return newLockToken()
+}
+
+func (noOpLS) Refresh(now time.Time, token string, duration time.Duration) (webdav.LockDetails, error) {
+	return webdav.LockDetails{Duration: duration}, nil
+}
+
+func (noOpLS) Unlock(now time.Time, token string) error {
+	return nil
+}
+
+// newLockToken returns a random opaquelocktoken URI
+func newLockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return lockTokenPrefix + hex.EncodeToString(b), nil
+}
+
+// lockTokenPrefix starts every token noOpLS and readOnlyLS hand out
+const lockTokenPrefix = "opaquelocktoken:"
+
+// readOnlyLS understands LOCK and UNLOCK but grants only informational
+// locks: every LOCK gets a fresh token, as if the lock were shared, and no
+// lock ever blocks a request. Unlike noOpLS, Refresh and Unlock reject
+// tokens it cannot have created; any token of the right format passes,
+// since none is recorded.
+type readOnlyLS struct{}
+
+func (readOnlyLS) Confirm(now time.Time, name0, name1 string, conditions ...webdav.Condition) (func(), error) {
+	return func() {}, nil
+}
+
+func (readOnlyLS) Create(now time.Time, details webdav.LockDetails) (string, error) {
+	return newLockToken()
+}
+
+func (readOnlyLS) Refresh(now time.Time, token string, duration time.Duration) (webdav.LockDetails, error) {
+	if !validLockToken(token) {
+		return webdav.LockDetails{}, webdav.ErrNoSuchLock
+	}
+	return webdav.LockDetails{Duration: duration}, nil
+}
+
+func (readOnlyLS) Unlock(now time.Time, token string) error {
+	if !validLockToken(token) {
+		return webdav.ErrNoSuchLock
+	}
+	return nil
+}
+
+// validLockToken reports whether token has the format of the tokens
+// readOnlyLS creates
+func validLockToken(token string) bool {
+	id, ok := strings.CutPrefix(token, lockTokenPrefix)
+	if !ok || len(id) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
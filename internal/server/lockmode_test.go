@@ -0,0 +1,97 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+func TestLockSystems(t *testing.T) {
+	tests := []struct {
+		mode LockMode
+		// Errors of a second Create on the locked root, of Confirm without
+		// the token and of Unlock with a token that was never handed out
+		createAgain, confirm, unlockUnknown error
+	}{
+		{mode: LockModeMemory, createAgain: webdav.ErrLocked, confirm: webdav.ErrConfirmationFailed, unlockUnknown: webdav.ErrNoSuchLock},
+		{mode: "", createAgain: webdav.ErrLocked, confirm: webdav.ErrConfirmationFailed, unlockUnknown: webdav.ErrNoSuchLock},
+		{mode: LockModeNone},
+		{mode: LockModeReadOnly, unlockUnknown: webdav.ErrNoSuchLock},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			ls := newLockSystem(tt.mode)
+			now := time.Now()
+			details := webdav.LockDetails{Root: "/file.txt", Duration: time.Minute, ZeroDepth: true}
+
+			token, err := ls.Create(now, details)
+			if err != nil || token == "" {
+				t.Fatalf("Create() = %q, %v, want a token", token, err)
+			}
+			if _, err := ls.Create(now, details); !errors.Is(err, tt.createAgain) {
+				t.Errorf("second Create() error = %v, want %v", err, tt.createAgain)
+			}
+
+			release, err := ls.Confirm(now, "/file.txt", "")
+			if !errors.Is(err, tt.confirm) {
+				t.Errorf("Confirm() without the token error = %v, want %v", err, tt.confirm)
+			}
+			if err == nil {
+				release()
+			}
+			release, err = ls.Confirm(now, "/file.txt", "", webdav.Condition{Token: token})
+			if err != nil {
+				t.Errorf("Confirm() with the token error = %v", err)
+			} else {
+				release()
+			}
+
+			if err := ls.Unlock(now, "opaquelocktoken:unknown"); !errors.Is(err, tt.unlockUnknown) {
+				t.Errorf("Unlock() of an unknown token error = %v, want %v", err, tt.unlockUnknown)
+			}
+			if err := ls.Unlock(now, token); err != nil {
+				t.Errorf("Unlock() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestLockModeReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "file.txt"), []byte("data"), 0644)
+	srv, err := NewWithOptions(Options{Folder: dir, Port: 18080, Bind: "127.0.0.1", LockMode: LockModeReadOnly}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	h := srv.Handler()
+
+	// Two clients lock the same file, and neither blocks the other
+	first := lockFile(t, h, "/file.txt")
+	second := lockFile(t, h, "/file.txt")
+	if first == second {
+		t.Errorf("both LOCKs got token %s, want a fresh one each", first)
+	}
+	if rec := doRequest(h, http.MethodPut, "/file.txt", "new", nil); rec.Code == http.StatusLocked {
+		t.Errorf("PUT without a token status = %d, want the lock ignored", rec.Code)
+	}
+
+	if rec := doRequest(h, "UNLOCK", "/file.txt", "", map[string]string{"Lock-Token": "<" + first + ">"}); rec.Code != http.StatusNoContent {
+		t.Errorf("UNLOCK status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if rec := doRequest(h, "UNLOCK", "/file.txt", "", map[string]string{"Lock-Token": "<opaquelocktoken:nothex>"}); rec.Code != http.StatusConflict {
+		t.Errorf("UNLOCK with a malformed token status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+}
+
+func TestLockModeInvalid(t *testing.T) {
+	_, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18080, Bind: "127.0.0.1", LockMode: "shared"}, nil)
+	if err == nil || !strings.Contains(err.Error(), `"shared"`) {
+		t.Errorf("NewWithOptions() error = %v, want invalid lock mode", err)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxLockBody bounds the LOCK request body read for inspection
+const maxLockBody = 1 << 20
+
+// lockInfo is the part of a LOCK request body inspected for an owner and
+// scope
+type lockInfo struct {
+	XMLName xml.Name `xml:"DAV: lockinfo"`
+	Owner   *struct {
+		InnerXML string `xml:",innerxml"`
+	} `xml:"DAV: owner"`
+	Scope struct {
+		Shared *struct{} `xml:"DAV: shared"`
+	} `xml:"DAV: lockscope"`
+}
+
+// requireLockOwner rejects LOCK requests creating a lock without a non-empty
+// owner element. Lock refreshes carry no body and are passed through, as are
+// bodies that do not parse, which the WebDAV handler rejects itself.
+func requireLockOwner(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "LOCK" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxLockBody))
+		if err != nil {
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		if len(bytes.TrimSpace(body)) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var info lockInfo
+		if err := xml.Unmarshal(body, &info); err == nil {
+			if info.Owner == nil || strings.TrimSpace(info.Owner.InnerXML) == "" {
+				http.Error(w, "LOCK requires an owner element", http.StatusBadRequest)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
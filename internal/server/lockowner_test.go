@@ -0,0 +1,58 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const ownerlessLockBody = `<?xml version="1.0" encoding="utf-8"?>
+<D:lockinfo xmlns:D="DAV:">
+  <D:lockscope><D:exclusive/></D:lockscope>
+  <D:locktype><D:write/></D:locktype>
+</D:lockinfo>`
+
+func newLockOwnerServer(t *testing.T) http.Handler {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "file.txt"), []byte("content"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	srv, err := NewWithOptions(Options{Folder: tmpDir, Port: 18080, Bind: "127.0.0.1", LockOwnerRequired: true}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	return srv.Handler()
+}
+
+func TestLockOwnerRequired(t *testing.T) {
+	h := newLockOwnerServer(t)
+
+	rec := doRequest(h, "LOCK", "/file.txt", ownerlessLockBody, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("LOCK without owner status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	rec = doRequest(h, "LOCK", "/file.txt", lockBody, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("LOCK with owner status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	// Refreshing the lock has no body and must still work
+	token := rec.Header().Get("Lock-Token")
+	rec = doRequest(h, "LOCK", "/file.txt", "", map[string]string{"If": "(" + token + ")"})
+	if rec.Code != http.StatusOK {
+		t.Errorf("LOCK refresh status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestLockOwnerNotRequiredByDefault(t *testing.T) {
+	h, _ := newIfTestServer(t)
+
+	rec := doRequest(h, "LOCK", "/file.txt", ownerlessLockBody, nil)
+	if rec.Code != http.StatusOK {
+		t.Errorf("LOCK without owner status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
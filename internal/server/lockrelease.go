@@ -0,0 +1,93 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+// heldLock is a lock recorded by heldLocksLS
+type heldLock struct {
+	token   string
+	details webdav.LockDetails
+	// expires is the zero time for locks without a timeout
+	expires time.Time
+}
+
+// heldLocksLS records the locks granted through it, which the wrapped lock
+// system cannot list, so that they can be released on shutdown
+type heldLocksLS struct {
+	webdav.LockSystem
+	mu   sync.Mutex
+	held map[string]heldLock
+}
+
+func newHeldLocksLS(ls webdav.LockSystem) *heldLocksLS {
+	return &heldLocksLS{LockSystem: ls, held: make(map[string]heldLock)}
+}
+
+// record stores the lock token, dropping locks that have expired since
+func (l *heldLocksLS) record(now time.Time, token string, details webdav.LockDetails) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for t, h := range l.held {
+		if !h.expires.IsZero() && now.After(h.expires) {
+			delete(l.held, t)
+		}
+	}
+	h := heldLock{token: token, details: details}
+	if details.Duration >= 0 {
+		h.expires = now.Add(details.Duration)
+	}
+	l.held[token] = h
+}
+
+func (l *heldLocksLS) Create(now time.Time, details webdav.LockDetails) (string, error) {
+	token, err := l.LockSystem.Create(now, details)
+	if err == nil {
+		l.record(now, token, details)
+	}
+	return token, err
+}
+
+func (l *heldLocksLS) Refresh(now time.Time, token string, duration time.Duration) (webdav.LockDetails, error) {
+	details, err := l.LockSystem.Refresh(now, token, duration)
+	if err == nil {
+		l.record(now, token, details)
+	}
+	return details, err
+}
+
+func (l *heldLocksLS) Unlock(now time.Time, token string) error {
+	err := l.LockSystem.Unlock(now, token)
+	if err == nil || err == webdav.ErrNoSuchLock {
+		l.mu.Lock()
+		delete(l.held, token)
+		l.mu.Unlock()
+	}
+	return err
+}
+
+// releaseAll unlocks every lock still held and returns them ordered by root
+func (l *heldLocksLS) releaseAll(now time.Time) []heldLock {
+	l.mu.Lock()
+	held := make([]heldLock, 0, len(l.held))
+	for _, h := range l.held {
+		held = append(held, h)
+	}
+	l.mu.Unlock()
+	sort.Slice(held, func(i, j int) bool { return held[i].details.Root < held[j].details.Root })
+
+	released := held[:0]
+	for _, h := range held {
+		if l.Unlock(now, h.token) == nil {
+			released = append(released, h)
+		}
+	}
+	return released
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+// lockTimeoutLS caps the timeout of every lock created or refreshed through
+// it at max, including locks asked for with "Timeout: Infinite", so that a
+// client crashing mid-edit cannot block a file forever. The in-memory lock
+// system drops expired locks itself on its next call, so the lock blocking a
+// request is released by that very request and no sweeper is needed.
+type lockTimeoutLS struct {
+	webdav.LockSystem
+	max time.Duration
+}
+
+// clamp returns d limited to max. A negative d asks for an infinite timeout.
+func (l lockTimeoutLS) clamp(d time.Duration) time.Duration {
+	if d < 0 || d > l.max {
+		return l.max
+	}
+	return d
+}
+
+func (l lockTimeoutLS) Create(now time.Time, details webdav.LockDetails) (string, error) {
+	details.Duration = l.clamp(details.Duration)
+	return l.LockSystem.Create(now, details)
+}
+
+func (l lockTimeoutLS) Refresh(now time.Time, token string, duration time.Duration) (webdav.LockDetails, error) {
+	return l.LockSystem.Refresh(now, token, l.clamp(duration))
+}
+
+// capLockTimeout shortens the Timeout header of LOCK requests to max, so that
+// the lock information in the response, which the WebDAV handler builds from
+// the header, gives the timeout lockTimeoutLS applies. Headers that do not
+// parse are left to the handler.
+func capLockTimeout(next http.Handler, max time.Duration) http.Handler {
+	capped := fmt.Sprintf("Second-%d", int64(max/time.Second))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "LOCK" && exceedsLockTimeout(r.Header.Get("Timeout"), max) {
+			r.Header.Set("Timeout", capped)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// exceedsLockTimeout reports whether the Timeout header value asks for more
+// than max. Only the first of several timeouts counts, as for the handler.
+func exceedsLockTimeout(value string, max time.Duration) bool {
+	first, _, _ := strings.Cut(value, ",")
+	first = strings.TrimSpace(first)
+	if first == "" || first == "Infinite" {
+		return true
+	}
+	secs, ok := strings.CutPrefix(first, "Second-")
+	if !ok {
+		return false
+	}
+	n, err := strconv.ParseInt(secs, 10, 64)
+	return err == nil && n > int64(max/time.Second)
+}
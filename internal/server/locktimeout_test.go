@@ -0,0 +1,61 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+func TestLockTimeoutClamp(t *testing.T) {
+	srv, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18080, Bind: "127.0.0.1", LockTimeout: time.Hour}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	h := srv.Handler()
+
+	for _, timeout := range []string{"Infinite", "Second-86400"} {
+		rec := doRequest(h, "LOCK", "/"+strings.ToLower(timeout)+".txt", lockBody, map[string]string{"Timeout": timeout})
+		if rec.Code != http.StatusCreated && rec.Code != http.StatusOK {
+			t.Fatalf("LOCK with %s status = %d", timeout, rec.Code)
+		}
+		if !strings.Contains(rec.Body.String(), "<D:timeout>Second-3600</D:timeout>") {
+			t.Errorf("LOCK with %s: body = %s, want a timeout of 3600 seconds", timeout, rec.Body.String())
+		}
+	}
+
+	rec := doRequest(h, "LOCK", "/short.txt", lockBody, map[string]string{"Timeout": "Second-60"})
+	if !strings.Contains(rec.Body.String(), "<D:timeout>Second-60</D:timeout>") {
+		t.Errorf("LOCK with a shorter timeout: body = %s, want it kept", rec.Body.String())
+	}
+}
+
+func TestLockTimeoutExpiry(t *testing.T) {
+	ls := lockTimeoutLS{LockSystem: webdav.NewMemLS(), max: time.Minute}
+	now := time.Unix(1000, 0)
+	details := webdav.LockDetails{Root: "/file.txt", Duration: -1, ZeroDepth: true}
+
+	token, err := ls.Create(now, details)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := ls.Create(now.Add(30*time.Second), details); !errors.Is(err, webdav.ErrLocked) {
+		t.Fatalf("second Create() before expiry error = %v, want %v", err, webdav.ErrLocked)
+	}
+	if got, err := ls.Refresh(now.Add(30*time.Second), token, -1); err != nil || got.Duration != time.Minute {
+		t.Fatalf("Refresh() = %v, %v, want the capped duration", got.Duration, err)
+	}
+	// The refreshed lock expires a minute after the refresh
+	if _, err := ls.Create(now.Add(91*time.Second), details); err != nil {
+		t.Errorf("Create() after expiry error = %v, want the infinite lock released", err)
+	}
+}
+
+func TestLockTimeoutInvalid(t *testing.T) {
+	if _, err := NewWithOptions(Options{Folder: t.TempDir(), LockTimeout: -time.Second}, nil); err == nil {
+		t.Error("NewWithOptions() should reject a negative lock timeout")
+	}
+}
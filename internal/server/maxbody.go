@@ -0,0 +1,129 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/webdav"
+)
+
+// sizeUnits are the suffixes accepted by ParseSize, as powers of 1024
+var sizeUnits = []struct {
+	suffix string
+	shift  uint
+}{
+	{"tib", 40}, {"tb", 40}, {"t", 40},
+	{"gib", 30}, {"gb", 30}, {"g", 30},
+	{"mib", 20}, {"mb", 20}, {"m", 20},
+	{"kib", 10}, {"kb", 10}, {"k", 10},
+	{"b", 0},
+}
+
+// ParseSize parses a human-readable size such as "512", "64KB" or "1.5GB".
+// Units are case-insensitive powers of 1024.
+func ParseSize(s string) (int64, error) {
+	value := strings.ToLower(strings.TrimSpace(s))
+	var shift uint
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(value, u.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, u.suffix))
+			shift = u.shift
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 || math.IsInf(n, 0) || math.IsNaN(n) {
+		return 0, fmt.Errorf("invalid size: %q", s)
+	}
+	bytes := n * float64(int64(1)<<shift)
+	if bytes >= math.MaxInt64 {
+		return 0, fmt.Errorf("size too large: %q", s)
+	}
+	return int64(bytes), nil
+}
+
+// limitBody rejects request bodies larger than limit with 413. Bodies that
+// announce their size are rejected before the handler runs; chunked bodies
+// are cut off once they exceed the limit, and a partially written PUT target
+// is removed.
+func limitBody(next http.Handler, fs webdav.FileSystem, limit int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > limit {
+			http.Error(w, "Request Entity Too Large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		if r.Body == nil || r.Body == http.NoBody {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body := &limitedBody{ReadCloser: http.MaxBytesReader(w, r.Body, limit)}
+		r.Body = body
+		lw := &limitedBodyWriter{ResponseWriter: w, body: body}
+		next.ServeHTTP(lw, r)
+
+		if body.exceeded {
+			if r.Method == http.MethodPut {
+				fs.RemoveAll(r.Context(), r.URL.Path)
+			}
+			if !lw.wroteHeader {
+				http.Error(w, "Request Entity Too Large", http.StatusRequestEntityTooLarge)
+			}
+		}
+	})
+}
+
+// limitedBody records whether the size limit cut off the body
+type limitedBody struct {
+	io.ReadCloser
+	exceeded bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		b.exceeded = true
+	}
+	return n, err
+}
+
+// limitedBodyWriter holds back the handler's response once the body limit
+// was hit, so the client gets 413 instead of the handler's read error
+type limitedBodyWriter struct {
+	http.ResponseWriter
+	body        *limitedBody
+	wroteHeader bool
+	discard     bool
+}
+
+func (w *limitedBodyWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	if w.body.exceeded {
+		w.discard = true
+		return
+	}
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *limitedBodyWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader && !w.discard {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.discard {
+		return len(p), nil
+	}
+	return w.ResponseWriter.Write(p)
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{in: "0", want: 0},
+		{in: "512", want: 512},
+		{in: "512B", want: 512},
+		{in: "64KB", want: 64 << 10},
+		{in: "100MB", want: 100 << 20},
+		{in: "100mb", want: 100 << 20},
+		{in: "1.5G", want: 3 << 29},
+		{in: "2 GiB", want: 2 << 30},
+		{in: "1TB", want: 1 << 40},
+		{in: "", wantErr: true},
+		{in: "MB", wantErr: true},
+		{in: "-1MB", wantErr: true},
+		{in: "10XB", wantErr: true},
+		{in: "99999999TB", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSize(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMaxBody(t *testing.T) {
+	tmpDir := t.TempDir()
+	srv, err := NewWithOptions(Options{Folder: tmpDir, Port: 18080, Bind: "127.0.0.1", MaxBodySize: 16}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	h := srv.Handler()
+
+	rec := doRequest(h, http.MethodPut, "/small.txt", "fits", nil)
+	if rec.Code != http.StatusCreated {
+		t.Errorf("PUT under the limit status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+
+	rec = doRequest(h, http.MethodPut, "/big.txt", strings.Repeat("x", 17), nil)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("PUT over the limit status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+
+	req := httptest.NewRequest(http.MethodPut, "/chunked.txt", strings.NewReader(strings.Repeat("x", 64*1024)))
+	req.ContentLength = -1
+	req.TransferEncoding = []string{"chunked"}
+	chunked := httptest.NewRecorder()
+	h.ServeHTTP(chunked, req)
+	if chunked.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("chunked PUT over the limit status = %d, want %d", chunked.Code, http.StatusRequestEntityTooLarge)
+	}
+
+	for _, name := range []string{"big.txt", "chunked.txt"} {
+		if _, err := os.Stat(filepath.Join(tmpDir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s should not exist after a rejected upload", name)
+		}
+	}
+
+	rec = doRequest(h, "PROPFIND", "/", strings.Repeat(" ", 32), map[string]string{"Depth": "0"})
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("PROPFIND with a large body status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestMaxBodyInvalid(t *testing.T) {
+	if _, err := NewWithOptions(Options{Folder: t.TempDir(), MaxBodySize: -1}, nil); err == nil {
+		t.Error("NewWithOptions() should reject a negative body size limit")
+	}
+}
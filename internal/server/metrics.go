@@ -0,0 +1,141 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"gowebdavd/internal/logger"
+)
+
+const metricsPath = "/metrics"
+
+// durationBuckets are the upper bounds in seconds of the request duration
+// histogram, the Prometheus client defaults
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// metricMethods are the methods counted under their own name. Others are
+// counted as OTHER so that clients cannot grow the label set without bound.
+var metricMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPut: true, http.MethodPost: true,
+	http.MethodDelete: true, http.MethodOptions: true, "PROPFIND": true, "PROPPATCH": true,
+	"MKCOL": true, "COPY": true, "MOVE": true, "LOCK": true, "UNLOCK": true, "SEARCH": true,
+}
+
+// requestKey labels the request counter
+type requestKey struct {
+	method string
+	status int
+}
+
+// metrics collects request and connection counters for the metrics endpoint
+type metrics struct {
+	mu            sync.Mutex
+	requests      map[requestKey]uint64
+	buckets       []uint64
+	durationSum   float64
+	durationCount uint64
+	bytesIn       uint64
+	bytesOut      uint64
+
+	active atomic.Int64
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		requests: make(map[requestKey]uint64),
+		buckets:  make([]uint64, len(durationBuckets)),
+	}
+}
+
+// middleware records every request answered by next
+func (m *metrics) middleware(next http.Handler) http.Handler {
+	return logger.Observe(next, m.observe)
+}
+
+func (m *metrics) observe(r *http.Request, resp logger.Response) {
+	method := r.Method
+	if !metricMethods[method] {
+		method = "OTHER"
+	}
+	seconds := resp.Duration.Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[requestKey{method: method, status: resp.Status}]++
+	for i, bound := range durationBuckets {
+		if seconds <= bound {
+			m.buckets[i]++
+		}
+	}
+	m.durationSum += seconds
+	m.durationCount++
+	m.bytesIn += uint64(resp.BytesRead)
+	m.bytesOut += uint64(resp.BytesWritten)
+}
+
+// connState is an http.Server ConnState hook tracking open connections
+func (m *metrics) connState(conn net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateNew:
+		m.active.Add(1)
+	case http.StateHijacked, http.StateClosed:
+		m.active.Add(-1)
+	}
+}
+
+// handler serves the metrics in the Prometheus text exposition format
+func (m *metrics) handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var b strings.Builder
+
+		m.mu.Lock()
+		keys := make([]requestKey, 0, len(m.requests))
+		for k := range m.requests {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].method != keys[j].method {
+				return keys[i].method < keys[j].method
+			}
+			return keys[i].status < keys[j].status
+		})
+		b.WriteString("# HELP gowebdavd_requests_total Requests handled, by method and status code.\n")
+		b.WriteString("# TYPE gowebdavd_requests_total counter\n")
+		for _, k := range keys {
+			fmt.Fprintf(&b, "gowebdavd_requests_total{method=%q,status=\"%d\"} %d\n", k.method, k.status, m.requests[k])
+		}
+
+		b.WriteString("# HELP gowebdavd_request_duration_seconds Time taken to answer requests.\n")
+		b.WriteString("# TYPE gowebdavd_request_duration_seconds histogram\n")
+		for i, bound := range durationBuckets {
+			fmt.Fprintf(&b, "gowebdavd_request_duration_seconds_bucket{le=%q} %d\n", strconv.FormatFloat(bound, 'g', -1, 64), m.buckets[i])
+		}
+		fmt.Fprintf(&b, "gowebdavd_request_duration_seconds_bucket{le=\"+Inf\"} %d\n", m.durationCount)
+		fmt.Fprintf(&b, "gowebdavd_request_duration_seconds_sum %g\n", m.durationSum)
+		fmt.Fprintf(&b, "gowebdavd_request_duration_seconds_count %d\n", m.durationCount)
+
+		b.WriteString("# HELP gowebdavd_received_bytes_total Request body bytes received.\n")
+		b.WriteString("# TYPE gowebdavd_received_bytes_total counter\n")
+		fmt.Fprintf(&b, "gowebdavd_received_bytes_total %d\n", m.bytesIn)
+		b.WriteString("# HELP gowebdavd_sent_bytes_total Response body bytes sent.\n")
+		b.WriteString("# TYPE gowebdavd_sent_bytes_total counter\n")
+		fmt.Fprintf(&b, "gowebdavd_sent_bytes_total %d\n", m.bytesOut)
+		m.mu.Unlock()
+
+		b.WriteString("# HELP gowebdavd_active_connections Open client connections.\n")
+		b.WriteString("# TYPE gowebdavd_active_connections gauge\n")
+		fmt.Fprintf(&b, "gowebdavd_active_connections %d\n", m.active.Load())
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Write([]byte(b.String()))
+	}
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	srv, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18080, Bind: "127.0.0.1", Metrics: true}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	h := srv.routes()
+
+	doRequest(h, http.MethodPut, "/file.txt", "hello", nil)
+	doRequest(h, http.MethodGet, "/file.txt", "", nil)
+	doRequest(h, http.MethodGet, "/missing.txt", "", nil)
+	doRequest(h, "BREW", "/file.txt", "", nil)
+
+	rec := doRequest(h, http.MethodGet, "/metrics", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /metrics status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q, want the Prometheus text format", ct)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		`gowebdavd_requests_total{method="PUT",status="201"} 1`,
+		`gowebdavd_requests_total{method="GET",status="200"} 1`,
+		`gowebdavd_requests_total{method="GET",status="404"} 1`,
+		`gowebdavd_requests_total{method="OTHER",status="400"} 1`,
+		`gowebdavd_request_duration_seconds_bucket{le="+Inf"} 4`,
+		`gowebdavd_request_duration_seconds_count 4`,
+		"gowebdavd_received_bytes_total 5\n",
+		"# TYPE gowebdavd_active_connections gauge",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q:\n%s", want, body)
+		}
+	}
+	if !strings.Contains(body, "gowebdavd_sent_bytes_total ") || strings.Contains(body, "gowebdavd_sent_bytes_total 0\n") {
+		t.Errorf("metrics should count the GET response body:\n%s", body)
+	}
+}
+
+func TestMetricsDisabledByDefault(t *testing.T) {
+	h := New(t.TempDir(), 18080, "127.0.0.1", nil).routes()
+	rec := doRequest(h, http.MethodGet, "/metrics", "", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET /metrics status = %d, want %d without -metrics", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestMetricsRequiresAuthOffLoopback(t *testing.T) {
+	srv, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18080, Bind: "0.0.0.0", Metrics: true, Credentials: Credentials{"alice": "secret"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := doRequest(srv.routes(), http.MethodGet, "/metrics", "", nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET /metrics without credentials status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.SetBasicAuth("alice", "secret")
+	rec := httptest.NewRecorder()
+	srv.routes().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET /metrics with credentials status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestMetricsActiveConnections(t *testing.T) {
+	srv, err := NewWithOptions(Options{Folder: t.TempDir(), Bind: "127.0.0.1", Metrics: true}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	url, done := startTestServer(t, srv)
+
+	client := &http.Client{Transport: &http.Transport{}}
+	resp, err := client.Get(url + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "gowebdavd_active_connections 1\n") {
+		t.Errorf("metrics should count the open connection:\n%s", body)
+	}
+
+	srv.shutdown()
+	waitServe(t, done)
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// ParseMIMETypes parses "ext=type" entries such as ".md=text/markdown" into
+// media types by lower-case extension with its leading dot, which may be
+// left out in the entries
+func ParseMIMETypes(entries []string) (map[string]string, error) {
+	types := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		ext, typ, ok := strings.Cut(entry, "=")
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		typ = strings.TrimSpace(typ)
+		if !ok || strings.Trim(ext, ".") == "" || strings.ContainsAny(ext, `/\`) {
+			return nil, fmt.Errorf("invalid MIME type mapping: %q (want ext=type)", entry)
+		}
+		if _, _, err := mime.ParseMediaType(typ); err != nil {
+			return nil, fmt.Errorf("invalid MIME type %q for %s: %w", typ, ext, err)
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		types[ext] = typ
+	}
+	return types, nil
+}
+
+// overrideMIMETypes sets the Content-Type of successful GET and HEAD
+// responses for files with a configured extension, replacing the type the
+// WebDAV handler derived from the system MIME table or the content. Other
+// extensions keep the handler's type.
+func overrideMIMETypes(next http.Handler, types map[string]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		typ, ok := types[strings.ToLower(path.Ext(r.URL.Path))]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(&mimeTypeWriter{ResponseWriter: w, typ: typ}, r)
+	})
+}
+
+// mimeTypeWriter replaces the Content-Type of a successful response as its
+// header is written
+type mimeTypeWriter struct {
+	http.ResponseWriter
+	typ         string
+	wroteHeader bool
+}
+
+func (w *mimeTypeWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	// A multi-range response keeps its multipart type
+	multipart := strings.HasPrefix(w.Header().Get("Content-Type"), "multipart/byteranges")
+	if (code == http.StatusOK || code == http.StatusPartialContent) && !multipart {
+		w.Header().Set("Content-Type", w.typ)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *mimeTypeWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *mimeTypeWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseMIMETypes(t *testing.T) {
+	types, err := ParseMIMETypes([]string{".MD=text/markdown", "ts = text/typescript; charset=utf-8"})
+	if err != nil {
+		t.Fatalf("ParseMIMETypes() error = %v", err)
+	}
+	if types[".md"] != "text/markdown" || types[".ts"] != "text/typescript; charset=utf-8" {
+		t.Errorf("ParseMIMETypes() = %v", types)
+	}
+
+	for _, bad := range []string{"md", "=text/plain", ".md=", ".md=not a type", "a/b=text/plain"} {
+		if _, err := ParseMIMETypes([]string{bad}); err == nil {
+			t.Errorf("ParseMIMETypes(%q) should fail", bad)
+		}
+	}
+}
+
+func TestOverrideMIMETypes(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Title\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "page.html"), []byte("<p>hi</p>"), 0644)
+	srv, err := NewWithOptions(Options{Folder: dir, Bind: "127.0.0.1", MIMETypes: map[string]string{".md": "text/markdown"}}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	h := srv.Handler()
+
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		rec := doRequest(h, method, "/README.md", "", nil)
+		if ct := rec.Header().Get("Content-Type"); rec.Code != http.StatusOK || ct != "text/markdown" {
+			t.Errorf("%s /README.md = %d with Content-Type %q, want text/markdown", method, rec.Code, ct)
+		}
+	}
+
+	rec := doRequest(h, http.MethodGet, "/README.md", "", map[string]string{"Range": "bytes=0-1"})
+	if ct := rec.Header().Get("Content-Type"); rec.Code != http.StatusPartialContent || ct != "text/markdown" {
+		t.Errorf("range GET = %d with Content-Type %q, want text/markdown", rec.Code, ct)
+	}
+
+	rec = doRequest(h, http.MethodGet, "/page.html", "", nil)
+	if ct := rec.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("GET /page.html Content-Type = %q, want the detected type", ct)
+	}
+
+	rec = doRequest(h, http.MethodGet, "/missing.md", "", nil)
+	if ct := rec.Header().Get("Content-Type"); rec.Code != http.StatusNotFound || ct == "text/markdown" {
+		t.Errorf("GET /missing.md = %d with Content-Type %q, want the error type", rec.Code, ct)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+// preserveModTimes gives the members created by a successful COPY the
+// modification times of their sources, as the WebDAV handler writes them at
+// the current time. fs serves request paths, which resolve below root once
+// prefix is removed.
+func preserveModTimes(next http.Handler, fs webdav.FileSystem, root, prefix string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dst := destinationPath(r)
+		if r.Method != "COPY" || dst == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// COPY with Depth 0 copies a collection without its members
+		recurse := r.Header.Get("Depth") != "0"
+		apply := func() {
+			copyModTimes(r.Context(), fs, r.URL.Path, dst, recurse, func(name string) string {
+				return localPath(root, strings.TrimPrefix(name, prefix))
+			})
+		}
+		next.ServeHTTP(&modTimeWriter{ResponseWriter: w, apply: apply}, r)
+	})
+}
+
+// copyModTimes sets the modification time of dst, and of its members when
+// recurse is set, to that of the matching source under src. Members are set
+// before their collection, whose time changes when they are touched. Times
+// that cannot be read or set are left as they are.
+func copyModTimes(ctx context.Context, fs webdav.FileSystem, src, dst string, recurse bool, resolve func(string) string) {
+	f, err := fs.OpenFile(ctx, src, os.O_RDONLY, 0)
+	if err != nil {
+		return
+	}
+	fi, err := f.Stat()
+	var entries []os.FileInfo
+	if err == nil && fi.IsDir() && recurse {
+		entries, _ = f.Readdir(-1)
+	}
+	f.Close()
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		copyModTimes(ctx, fs, path.Join(src, e.Name()), path.Join(dst, e.Name()), true, resolve)
+	}
+	// A zero access time is left unchanged
+	os.Chtimes(resolve(dst), time.Time{}, fi.ModTime())
+}
+
+// modTimeWriter runs apply once the WebDAV handler reports success, before
+// the client sees the response
+type modTimeWriter struct {
+	http.ResponseWriter
+	apply       func()
+	wroteHeader bool
+}
+
+func (w *modTimeWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if code == http.StatusCreated || code == http.StatusNoContent {
+			w.apply()
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *modTimeWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer
+func (w *modTimeWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
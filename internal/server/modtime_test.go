@@ -0,0 +1,119 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+func TestPreserveModTimes_File(t *testing.T) {
+	dir := t.TempDir()
+	mtime := time.Now().Add(-48 * time.Hour)
+	src := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(src, []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(src, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	h := davHandler(webdav.Dir(dir), "", webdav.NewMemLS(), Options{PreserveModTimes: true}, nil)
+	w := doRequest(h, "COPY", "/a.txt", "", map[string]string{"Destination": "/b.txt"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("COPY status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	fi, err := os.Stat(filepath.Join(dir, "b.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := fi.ModTime().Sub(mtime).Abs(); d > time.Second {
+		t.Errorf("copy modified = %v, want %v", fi.ModTime(), mtime)
+	}
+}
+
+func TestPreserveModTimes_Collection(t *testing.T) {
+	dir := t.TempDir()
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.MkdirAll(filepath.Join(dir, "src", "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "src", "sub", "a.txt"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"src/sub/a.txt", "src/sub", "src"} {
+		if err := os.Chtimes(filepath.Join(dir, p), mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h := davHandler(webdav.Dir(dir), "/files", webdav.NewMemLS(), Options{PreserveModTimes: true}, nil)
+	w := doRequest(h, "COPY", "/files/src", "", map[string]string{"Destination": "/files/dst"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("COPY status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	for _, p := range []string{"dst/sub/a.txt", "dst/sub", "dst"} {
+		fi, err := os.Stat(filepath.Join(dir, p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !fi.ModTime().Equal(mtime) {
+			t.Errorf("%s modified = %v, want %v", p, fi.ModTime(), mtime)
+		}
+	}
+}
+
+func TestPreserveModTimes_DepthZero(t *testing.T) {
+	dir := t.TempDir()
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Mkdir(filepath.Join(dir, "src"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "src", "a.txt"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(dir, "src"), mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	h := davHandler(webdav.Dir(dir), "", webdav.NewMemLS(), Options{PreserveModTimes: true}, nil)
+	w := doRequest(h, "COPY", "/src", "", map[string]string{"Destination": "/dst", "Depth": "0"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("COPY status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	fi, err := os.Stat(filepath.Join(dir, "dst"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fi.ModTime().Equal(mtime) {
+		t.Errorf("copy modified = %v, want %v", fi.ModTime(), mtime)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "dst", "a.txt")); !os.IsNotExist(err) {
+		t.Errorf("Depth 0 COPY copied members: %v", err)
+	}
+}
+
+func TestPreserveModTimes_Disabled(t *testing.T) {
+	dir := t.TempDir()
+	mtime := time.Now().Add(-48 * time.Hour)
+	src := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(src, []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(src, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	h := davHandler(webdav.Dir(dir), "", webdav.NewMemLS(), Options{}, nil)
+	doRequest(h, "COPY", "/a.txt", "", map[string]string{"Destination": "/b.txt"})
+	fi, err := os.Stat(filepath.Join(dir, "b.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := fi.ModTime().Sub(mtime).Abs(); d < time.Hour {
+		t.Errorf("copy modified = %v, want the time of the copy", fi.ModTime())
+	}
+}
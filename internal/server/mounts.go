@@ -0,0 +1,250 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+// validateMountName rejects names that cannot be a single path segment
+func validateMountName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid mount name: %q", name)
+	}
+	return nil
+}
+
+// mountMux dispatches requests to the collection named by the first path
+// segment. Routing uses the cleaned path while every mount handler still sees
+// the raw one, so "/photos/../docs" reaches the docs handler, whose prefix
+// check rejects it, and no path can leave the mount it is routed to.
+type mountMux struct {
+	root   http.Handler
+	mounts map[string]http.Handler
+}
+
+func (m *mountMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p := path.Clean("/" + r.URL.Path)
+	if p == "/" {
+		m.root.ServeHTTP(w, r)
+		return
+	}
+	name, _, _ := strings.Cut(p[1:], "/")
+	if h, ok := m.mounts[name]; ok {
+		h.ServeHTTP(w, r)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+// prefixFS exposes a file system under a URL prefix, for middleware that
+// resolves r.URL.Path before the WebDAV handler strips the prefix
+type prefixFS struct {
+	webdav.FileSystem
+	prefix string
+}
+
+func (fs prefixFS) strip(name string) (string, error) {
+	rest, ok := strings.CutPrefix(name, fs.prefix)
+	if !ok || (rest != "" && rest[0] != '/') {
+		return "", os.ErrNotExist
+	}
+	if rest == "" {
+		rest = "/"
+	}
+	return rest, nil
+}
+
+func (fs prefixFS) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	name, err := fs.strip(name)
+	if err != nil {
+		return err
+	}
+	return fs.FileSystem.Mkdir(ctx, name, perm)
+}
+
+func (fs prefixFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	name, err := fs.strip(name)
+	if err != nil {
+		return nil, err
+	}
+	return fs.FileSystem.OpenFile(ctx, name, flag, perm)
+}
+
+func (fs prefixFS) RemoveAll(ctx context.Context, name string) error {
+	name, err := fs.strip(name)
+	if err != nil {
+		return err
+	}
+	return fs.FileSystem.RemoveAll(ctx, name)
+}
+
+func (fs prefixFS) Rename(ctx context.Context, oldName, newName string) error {
+	oldName, err := fs.strip(oldName)
+	if err != nil {
+		return err
+	}
+	if newName, err = fs.strip(newName); err != nil {
+		return err
+	}
+	return fs.FileSystem.Rename(ctx, oldName, newName)
+}
+
+func (fs prefixFS) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	name, err := fs.strip(name)
+	if err != nil {
+		return nil, err
+	}
+	return fs.FileSystem.Stat(ctx, name)
+}
+
+// mountsFS is the read-only file system behind "/" when serving named mounts.
+// The root is a collection whose children are the mounts; paths below a mount
+// are resolved by that mount's file system so deep PROPFINDs work.
+type mountsFS map[string]webdav.FileSystem
+
+// resolve returns the mount serving name and the path inside it. The root
+// itself resolves to a nil file system.
+func (fs mountsFS) resolve(name string) (webdav.FileSystem, string, error) {
+	p := path.Clean("/" + name)
+	if p == "/" {
+		return nil, "/", nil
+	}
+	mount, rest, _ := strings.Cut(p[1:], "/")
+	mfs, ok := fs[mount]
+	if !ok {
+		return nil, "", os.ErrNotExist
+	}
+	return mfs, "/" + rest, nil
+}
+
+func (fs mountsFS) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	return os.ErrPermission
+}
+
+func (fs mountsFS) RemoveAll(ctx context.Context, name string) error {
+	return os.ErrPermission
+}
+
+func (fs mountsFS) Rename(ctx context.Context, oldName, newName string) error {
+	return os.ErrPermission
+}
+
+func (fs mountsFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	mfs, rest, err := fs.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC) != 0 {
+		return nil, os.ErrPermission
+	}
+	if mfs != nil {
+		return mfs.OpenFile(ctx, rest, flag, perm)
+	}
+
+	children := fs.children(ctx)
+	return &mountsRoot{info: newRootInfo(children), children: children}, nil
+}
+
+// children returns the root directories of the mounts, named after them
+func (fs mountsFS) children(ctx context.Context) []os.FileInfo {
+	var children []os.FileInfo
+	for name, mfs := range fs {
+		fi, err := mfs.Stat(ctx, "/")
+		if err != nil {
+			continue
+		}
+		children = append(children, mountInfo{FileInfo: fi, name: name})
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].Name() < children[j].Name() })
+	return children
+}
+
+func (fs mountsFS) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	mfs, rest, err := fs.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	if mfs != nil {
+		return mfs.Stat(ctx, rest)
+	}
+	return newRootInfo(fs.children(ctx)), nil
+}
+
+// mountInfo reports a mount's root directory under the mount name
+type mountInfo struct {
+	os.FileInfo
+	name string
+}
+
+func (fi mountInfo) Name() string { return fi.name }
+
+// rootInfo describes the virtual collection listing the mounts. It reports
+// the newest modification time of the mounted directories.
+type rootInfo struct {
+	modTime time.Time
+}
+
+func newRootInfo(children []os.FileInfo) rootInfo {
+	var info rootInfo
+	for _, fi := range children {
+		if fi.ModTime().After(info.modTime) {
+			info.modTime = fi.ModTime()
+		}
+	}
+	return info
+}
+
+func (rootInfo) Name() string         { return "/" }
+func (rootInfo) Size() int64          { return 0 }
+func (rootInfo) Mode() os.FileMode    { return os.ModeDir | 0555 }
+func (i rootInfo) ModTime() time.Time { return i.modTime }
+func (rootInfo) IsDir() bool          { return true }
+func (rootInfo) Sys() any             { return nil }
+
+// mountsRoot is the open virtual root collection
+type mountsRoot struct {
+	info     rootInfo
+	children []os.FileInfo
+	read     bool
+}
+
+func (f *mountsRoot) Close() error { return nil }
+
+func (f *mountsRoot) Read(p []byte) (int, error) {
+	return 0, io.EOF
+}
+
+func (f *mountsRoot) Seek(offset int64, whence int) (int64, error) {
+	return 0, nil
+}
+
+func (f *mountsRoot) Readdir(count int) ([]os.FileInfo, error) {
+	if f.read {
+		if count > 0 {
+			return nil, io.EOF
+		}
+		return nil, nil
+	}
+	f.read = true
+	return f.children, nil
+}
+
+func (f *mountsRoot) Stat() (os.FileInfo, error) {
+	return f.info, nil
+}
+
+func (f *mountsRoot) Write(p []byte) (int, error) {
+	return 0, os.ErrPermission
+}
@@ -0,0 +1,185 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func newMountsServer(t *testing.T, listing bool) (http.Handler, map[string]string) {
+	t.Helper()
+
+	dirs := map[string]string{"photos": t.TempDir(), "docs": t.TempDir()}
+	for name, dir := range dirs {
+		if err := os.WriteFile(filepath.Join(dir, "shared.txt"), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+	srv, err := NewWithOptions(Options{Port: 18080, Bind: "127.0.0.1", Mounts: dirs, DirListing: listing}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	return srv.Handler(), dirs
+}
+
+func TestMountsServeEachDirectory(t *testing.T) {
+	h, dirs := newMountsServer(t, false)
+
+	for _, name := range []string{"photos", "docs"} {
+		rec := doRequest(h, http.MethodGet, "/"+name+"/shared.txt", "", nil)
+		if rec.Code != http.StatusOK || rec.Body.String() != name {
+			t.Errorf("GET /%s/shared.txt = %d %q, want 200 %q", name, rec.Code, rec.Body.String(), name)
+		}
+	}
+
+	rec := doRequest(h, http.MethodPut, "/photos/new.jpg", "image", nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("PUT /photos/new.jpg status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if _, err := os.Stat(filepath.Join(dirs["photos"], "new.jpg")); err != nil {
+		t.Errorf("PUT should create the file in the photos directory: %v", err)
+	}
+
+	rec = doRequest(h, http.MethodGet, "/music/song.mp3", "", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET on unknown mount status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestMountsRootPropfind(t *testing.T) {
+	h, _ := newMountsServer(t, false)
+
+	rec := doRequest(h, "PROPFIND", "/", "", map[string]string{"Depth": "1"})
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("PROPFIND / status = %d, want %d", rec.Code, http.StatusMultiStatus)
+	}
+	body := rec.Body.String()
+	for _, href := range []string{"<D:href>/</D:href>", "<D:href>/docs/</D:href>", "<D:href>/photos/</D:href>"} {
+		if !strings.Contains(body, href) {
+			t.Errorf("PROPFIND / response missing %s:\n%s", href, body)
+		}
+	}
+	if strings.Count(body, "<D:collection") != 3 {
+		t.Errorf("PROPFIND / should report the root and both mounts as collections:\n%s", body)
+	}
+
+	rec = doRequest(h, http.MethodPut, "/new.txt", "data", nil)
+	if rec.Code == http.StatusCreated {
+		t.Error("PUT outside a mount should fail")
+	}
+}
+
+func TestMountsTraversal(t *testing.T) {
+	h, _ := newMountsServer(t, false)
+
+	for _, target := range []string{"/photos/../docs/shared.txt", "/photos/../../shared.txt", "/docs/../../../etc/passwd"} {
+		rec := doRequest(h, http.MethodGet, target, "", nil)
+		if rec.Code == http.StatusOK {
+			t.Errorf("GET %s status = %d, want failure (body %q)", target, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestMountsSeparateLockSystems(t *testing.T) {
+	h, _ := newMountsServer(t, false)
+
+	// Both files are /shared.txt inside their mount; a shared lock system would
+	// report the second LOCK as a conflict
+	lockFile(t, h, "/photos/shared.txt")
+	lockFile(t, h, "/docs/shared.txt")
+}
+
+func TestMountsRootListing(t *testing.T) {
+	h, _ := newMountsServer(t, true)
+
+	rec := doRequest(h, http.MethodGet, "/", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET / status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	for _, link := range []string{`href="/docs/"`, `href="/photos/"`} {
+		if !strings.Contains(rec.Body.String(), link) {
+			t.Errorf("root listing missing %s:\n%s", link, rec.Body.String())
+		}
+	}
+
+	rec = doRequest(h, http.MethodGet, "/photos/", "", nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `href="/photos/shared.txt"`) {
+		t.Errorf("GET /photos/ = %d, want a listing of the mount:\n%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestMountsInvalidName(t *testing.T) {
+	for _, name := range []string{"", "..", "a/b"} {
+		_, err := NewWithOptions(Options{Port: 18080, Bind: "127.0.0.1", Mounts: map[string]string{name: t.TempDir()}}, nil)
+		if err == nil {
+			t.Errorf("NewWithOptions() should reject mount name %q", name)
+		}
+	}
+}
+
+func TestMountsRootNoSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symbolic links requires privileges on Windows")
+	}
+
+	dir, outside := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		t.Fatalf("Symlink() error = %v", err)
+	}
+	srv, err := NewWithOptions(Options{Port: 18080, Bind: "127.0.0.1", Mounts: map[string]string{"m": dir}, NoSymlinks: true}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+
+	rec := doRequest(srv.Handler(), "PROPFIND", "/", "", map[string]string{"Depth": "infinity"})
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("PROPFIND / status = %d, want %d", rec.Code, http.StatusMultiStatus)
+	}
+	if body := rec.Body.String(); strings.Contains(body, "/m/link") || !strings.Contains(body, "<D:href>/m/</D:href>") {
+		t.Errorf("PROPFIND / lists paths behind a symlink leaving the mount:\n%s", body)
+	}
+}
+
+func TestMountsRootHidesConfigAndProperties(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "private"), 0755); err != nil {
+		t.Fatalf("Failed to create test dir: %v", err)
+	}
+	files := map[string]string{
+		dirConfigName:                           `{}`,
+		filepath.Join("private", dirConfigName): `{"hidden": true}`,
+		filepath.Join("private", "secret.txt"):  "secret",
+		"file.txt":                              "data",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+	srv, err := NewWithOptions(Options{Port: 18080, Bind: "127.0.0.1", Mounts: map[string]string{"m": dir}, DirConfig: true, DeadProps: true}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	h := srv.Handler()
+	if rec := doRequest(h, "PROPPATCH", "/m/file.txt", fmt.Sprintf(colorPatch, "red"), nil); rec.Code != http.StatusMultiStatus {
+		t.Fatalf("PROPPATCH status = %d, want %d", rec.Code, http.StatusMultiStatus)
+	}
+
+	rec := doRequest(h, "PROPFIND", "/", "", map[string]string{"Depth": "infinity"})
+	body := rec.Body.String()
+	if rec.Code != http.StatusMultiStatus || !strings.Contains(body, "/m/file.txt") {
+		t.Fatalf("PROPFIND / = %d, want the mount members:\n%s", rec.Code, body)
+	}
+	for _, hidden := range []string{dirConfigName, deadPropsName, "/m/private"} {
+		if strings.Contains(body, hidden) {
+			t.Errorf("PROPFIND / lists %s:\n%s", hidden, body)
+		}
+	}
+}
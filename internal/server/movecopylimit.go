@@ -0,0 +1,71 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+
+	"golang.org/x/net/webdav"
+)
+
+// limitMoveCopy rejects with 403 a COPY or MOVE whose source holds more than
+// limit bytes, before any data is copied
+func limitMoveCopy(next http.Handler, fs webdav.FileSystem, limit int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "COPY" && r.Method != "MOVE" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// COPY with Depth 0 copies a collection without its members
+		recurse := r.Method == "MOVE" || r.Header.Get("Depth") != "0"
+		if size := treeSize(r.Context(), fs, r.URL.Path, recurse, limit); size > limit {
+			http.Error(w, fmt.Sprintf("Forbidden: %s source exceeds %d bytes", r.Method, limit), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// treeSize returns the total size of the files at name, descending into
+// collections when recurse is set. Counting stops once limit is exceeded.
+// Missing resources count as empty and are left to the WebDAV handler.
+func treeSize(ctx context.Context, fs webdav.FileSystem, name string, recurse bool, limit int64) int64 {
+	f, err := fs.OpenFile(ctx, name, 0, 0)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return 0
+	}
+	if !fi.IsDir() {
+		return fi.Size()
+	}
+	if !recurse {
+		return 0
+	}
+
+	entries, err := f.Readdir(-1)
+	if err != nil {
+		return 0
+	}
+	var total int64
+	for _, e := range entries {
+		if e.IsDir() {
+			total += treeSize(ctx, fs, path.Join(name, e.Name()), true, limit-total)
+		} else {
+			total += e.Size()
+		}
+		if total > limit {
+			break
+		}
+	}
+	return total
+}
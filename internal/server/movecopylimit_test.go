@@ -0,0 +1,75 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMoveCopyLimit(t *testing.T) {
+	tmpDir := t.TempDir()
+	for name, size := range map[string]int{
+		"small/a.bin":        40,
+		"small/b.bin":        40,
+		"big/a.bin":          60,
+		"big/nested/b.bin":   60,
+		"big/nested/c/d.bin": 10,
+	} {
+		p := filepath.Join(tmpDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatalf("Failed to create test dir: %v", err)
+		}
+		if err := os.WriteFile(p, []byte(strings.Repeat("x", size)), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	srv, err := NewWithOptions(Options{Folder: tmpDir, Port: 18080, Bind: "127.0.0.1", MaxMoveCopySize: 100}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	h := srv.Handler()
+
+	tests := []struct {
+		method string
+		source string
+		dest   string
+		depth  string
+		want   int
+	}{
+		{method: "COPY", source: "/small", dest: "/small-copy", want: http.StatusCreated},
+		{method: "COPY", source: "/big", dest: "/big-copy", want: http.StatusForbidden},
+		{method: "COPY", source: "/big", dest: "/big-shallow", depth: "0", want: http.StatusCreated},
+		{method: "MOVE", source: "/big", dest: "/big-moved", want: http.StatusForbidden},
+		{method: "MOVE", source: "/big/a.bin", dest: "/a.bin", want: http.StatusCreated},
+		{method: "MOVE", source: "/small", dest: "/small-moved", want: http.StatusCreated},
+	}
+	for _, tt := range tests {
+		headers := map[string]string{"Destination": "http://example.com" + tt.dest}
+		if tt.depth != "" {
+			headers["Depth"] = tt.depth
+		}
+		rec := doRequest(h, tt.method, tt.source, "", headers)
+		if rec.Code != tt.want {
+			t.Errorf("%s %s -> %s status = %d, want %d", tt.method, tt.source, tt.dest, rec.Code, tt.want)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpDir, "big-copy")); !os.IsNotExist(err) {
+		t.Error("rejected COPY should not create the destination")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "big", "nested", "b.bin")); err != nil {
+		t.Errorf("rejected MOVE should leave the source in place: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "small-copy", "b.bin")); err != nil {
+		t.Errorf("COPY under the limit should copy the tree: %v", err)
+	}
+}
+
+func TestMoveCopyLimitInvalid(t *testing.T) {
+	if _, err := NewWithOptions(Options{Folder: t.TempDir(), MaxMoveCopySize: -1}, nil); err == nil {
+		t.Error("NewWithOptions() should reject a negative move/copy limit")
+	}
+}
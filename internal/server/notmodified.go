@@ -0,0 +1,119 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+// ifModifiedSince answers GET and HEAD with 304 Not Modified when the
+// resource has not changed since the If-Modified-Since date, and gives
+// successful responses a Last-Modified header to send back. The WebDAV
+// handler does both for files through http.ServeContent; this extends them
+// to the HTML listings of collections, which change with their members. As
+// RFC 9110 §13.1.3 asks, If-Modified-Since is ignored along If-None-Match.
+func ifModifiedSince(next http.Handler, fs webdav.FileSystem) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		fi, err := fs.Stat(r.Context(), r.URL.Path)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		modTime := lastModified(r.Context(), fs, r.URL.Path, fi)
+		if modTime.IsZero() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		nw := &notModifiedWriter{ResponseWriter: w, modTime: modTime}
+		if r.Header.Get("If-None-Match") == "" {
+			if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil {
+				// HTTP dates have whole seconds
+				nw.notModified = !modTime.Truncate(time.Second).After(since)
+			}
+		}
+		next.ServeHTTP(nw, r)
+	})
+}
+
+// lastModified returns the modification time of name, or for a collection
+// the latest of its own and those of its members
+func lastModified(ctx context.Context, fs webdav.FileSystem, name string, fi os.FileInfo) time.Time {
+	modTime := fi.ModTime()
+	if !fi.IsDir() {
+		return modTime
+	}
+	f, err := fs.OpenFile(ctx, name, os.O_RDONLY, 0)
+	if err != nil {
+		return modTime
+	}
+	defer f.Close()
+	entries, err := f.Readdir(-1)
+	if err != nil {
+		return modTime
+	}
+	for _, e := range entries {
+		if e.ModTime().After(modTime) {
+			modTime = e.ModTime()
+		}
+	}
+	return modTime
+}
+
+// notModifiedWriter adds Last-Modified to a 200 response, and replaces it
+// with 304 and no body when notModified is set
+type notModifiedWriter struct {
+	http.ResponseWriter
+	modTime     time.Time
+	notModified bool
+	wroteHeader bool
+	discard     bool
+}
+
+func (w *notModifiedWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if code != http.StatusOK {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	h := w.Header()
+	if h.Get("Last-Modified") == "" {
+		h.Set("Last-Modified", w.modTime.UTC().Format(http.TimeFormat))
+	}
+	if w.notModified {
+		h.Del("Content-Type")
+		h.Del("Content-Length")
+		h.Del("Content-Encoding")
+		w.discard = true
+		code = http.StatusNotModified
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *notModifiedWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.discard {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer
+func (w *notModifiedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+func newListingHandler(t *testing.T, opts Options) (http.Handler, string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "d"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "d", "a.txt"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, p := range []string{"d/a.txt", "d"} {
+		if err := os.Chtimes(filepath.Join(dir, p), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return davHandler(webdav.Dir(dir), "", webdav.NewMemLS(), opts, nil), dir
+}
+
+func TestIfModifiedSince_Listing(t *testing.T) {
+	h, dir := newListingHandler(t, Options{DirListing: true})
+
+	w := doRequest(h, http.MethodGet, "/d/", "", nil)
+	lm := w.Header().Get("Last-Modified")
+	if w.Code != http.StatusOK || lm != "Thu, 02 Jan 2020 03:04:05 GMT" {
+		t.Fatalf("GET status = %d, Last-Modified = %q", w.Code, lm)
+	}
+
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		w = doRequest(h, method, "/d/", "", map[string]string{"If-Modified-Since": lm})
+		if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Errorf("%s If-Modified-Since status = %d, body %d bytes, want 304 and none", method, w.Code, w.Body.Len())
+		}
+		if got := w.Header().Get("Last-Modified"); got != lm {
+			t.Errorf("%s 304 Last-Modified = %q, want %q", method, got, lm)
+		}
+	}
+
+	// A member changed in place makes the listing newer
+	if err := os.WriteFile(filepath.Join(dir, "d", "a.txt"), []byte("changed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w = doRequest(h, http.MethodGet, "/d/", "", map[string]string{"If-Modified-Since": lm})
+	if w.Code != http.StatusOK || w.Body.Len() == 0 {
+		t.Errorf("GET after change status = %d, want %d with the listing", w.Code, http.StatusOK)
+	}
+}
+
+func TestIfModifiedSince_IgnoredWithIfNoneMatch(t *testing.T) {
+	h, _ := newListingHandler(t, Options{DirListing: true})
+	w := doRequest(h, http.MethodGet, "/d/", "", map[string]string{
+		"If-Modified-Since": time.Now().UTC().Format(http.TimeFormat),
+		"If-None-Match":     `"other"`,
+	})
+	if w.Code != http.StatusOK {
+		t.Errorf("GET status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestIfModifiedSince_File(t *testing.T) {
+	h, _ := newListingHandler(t, Options{DirListing: true, Index: "a.txt"})
+	w := doRequest(h, http.MethodGet, "/d/", "", nil)
+	lm := w.Header().Get("Last-Modified")
+	if w.Code != http.StatusOK || w.Body.String() != "hello" {
+		t.Fatalf("GET status = %d, body %q, want the index file", w.Code, w.Body.String())
+	}
+	w = doRequest(h, http.MethodGet, "/d/", "", map[string]string{"If-Modified-Since": lm})
+	if w.Code != http.StatusNotModified {
+		t.Errorf("GET If-Modified-Since status = %d, want %d", w.Code, http.StatusNotModified)
+	}
+	w = doRequest(h, http.MethodGet, "/d/", "", map[string]string{"If-Modified-Since": "Wed, 01 Jan 2020 00:00:00 GMT"})
+	if w.Code != http.StatusOK {
+		t.Errorf("GET If-Modified-Since earlier status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/webdav"
+)
+
+// uploadOffsetHeader reports the length of the file a partial PUT wrote to,
+// i.e. the offset where the client resumes
+const uploadOffsetHeader = "Upload-Offset"
+
+// errRangeGap rejects a partial PUT starting past the end of its file
+var errRangeGap = newStatusError(http.StatusRequestedRangeNotSatisfiable, "Range Not Satisfiable: Content-Range starts past the end of the file", nil)
+
+// putRange is the byte range of the file a PUT with Content-Range writes.
+// total is -1 when the complete length is unknown.
+type putRange struct {
+	start, end, total int64
+}
+
+// putRangeKey holds the *putRange of a partial PUT in its request context
+type putRangeKey struct{}
+
+// parseContentRange parses a Content-Range request header such as
+// "bytes 100-199/1000" or "bytes 100-199/*"
+func parseContentRange(s string) (putRange, error) {
+	spec, ok := strings.CutPrefix(s, "bytes ")
+	if !ok {
+		return putRange{}, fmt.Errorf("unsupported Content-Range unit: %q", s)
+	}
+	bounds, total, ok := strings.Cut(spec, "/")
+	first, last, ok2 := strings.Cut(bounds, "-")
+	if !ok || !ok2 {
+		return putRange{}, fmt.Errorf("invalid Content-Range: %q", s)
+	}
+	rng := putRange{total: -1}
+	var err error
+	if rng.start, err = strconv.ParseInt(first, 10, 64); err != nil || rng.start < 0 {
+		return putRange{}, fmt.Errorf("invalid Content-Range: %q", s)
+	}
+	if rng.end, err = strconv.ParseInt(last, 10, 64); err != nil || rng.end < rng.start {
+		return putRange{}, fmt.Errorf("invalid Content-Range: %q", s)
+	}
+	if total != "*" {
+		if rng.total, err = strconv.ParseInt(total, 10, 64); err != nil || rng.total <= rng.end {
+			return putRange{}, fmt.Errorf("invalid Content-Range: %q", s)
+		}
+	}
+	return rng, nil
+}
+
+// partialPut lets a PUT with Content-Range write its body into the file at
+// the range offset instead of replacing the file, so that an interrupted
+// upload resumes where it stopped. The range must start within the file or
+// at its end; a range starting at 0 begins a new upload. Responses report
+// the length of the file in the Upload-Offset header.
+func partialPut(next http.Handler, fs webdav.FileSystem) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Content-Range")
+		if r.Method != http.MethodPut || header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		rng, err := parseContentRange(header)
+		if err != nil {
+			http.Error(w, "Bad Request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		length := rng.end - rng.start + 1
+		if r.ContentLength >= 0 && r.ContentLength != length {
+			http.Error(w, "Bad Request: Content-Length does not match Content-Range", http.StatusBadRequest)
+			return
+		}
+
+		var size int64
+		if fi, err := fs.Stat(r.Context(), r.URL.Path); err == nil {
+			size = fi.Size()
+		}
+		if rng.start > size {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+			w.Header().Set(uploadOffsetHeader, strconv.FormatInt(size, 10))
+			http.Error(w, errRangeGap.msg, errRangeGap.status)
+			return
+		}
+
+		// A chunked body may not run past the range
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.LimitReader(r.Body, length), r.Body}
+		r = r.WithContext(context.WithValue(r.Context(), putRangeKey{}, &rng))
+		next.ServeHTTP(&uploadOffsetWriter{ResponseWriter: w, fs: fs, r: r}, r)
+	})
+}
+
+// putRangeFrom returns the range of a partial PUT, or nil for other requests
+func putRangeFrom(ctx context.Context) *putRange {
+	rng, _ := ctx.Value(putRangeKey{}).(*putRange)
+	return rng
+}
+
+// uploadOffsetWriter adds the length of the written file to a successful
+// response
+type uploadOffsetWriter struct {
+	http.ResponseWriter
+	fs          webdav.FileSystem
+	r           *http.Request
+	wroteHeader bool
+}
+
+func (w *uploadOffsetWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if code >= 200 && code < 300 {
+			if fi, err := w.fs.Stat(w.r.Context(), w.r.URL.Path); err == nil {
+				w.Header().Set(uploadOffsetHeader, strconv.FormatInt(fi.Size(), 10))
+			}
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *uploadOffsetWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer
+func (w *uploadOffsetWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// partialPutFS opens the target of a partial PUT without truncating it and
+// positioned at the start of the range. Ranges starting at 0 truncate as a
+// whole PUT does.
+type partialPutFS struct {
+	webdav.FileSystem
+}
+
+func (fs partialPutFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	rng := putRangeFrom(ctx)
+	if rng == nil || rng.start == 0 || flag&os.O_TRUNC == 0 {
+		return fs.FileSystem.OpenFile(ctx, name, flag, perm)
+	}
+	f, err := fs.FileSystem.OpenFile(ctx, name, flag&^os.O_TRUNC, perm)
+	if err != nil {
+		return nil, err
+	}
+	// The file may have shrunk since the request was checked
+	fi, err := f.Stat()
+	if err == nil && fi.Size() < rng.start {
+		err = errRangeGap
+	}
+	if err == nil {
+		_, err = f.Seek(rng.start, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/webdav"
+)
+
+func newPartialPutHandler(t *testing.T, opts Options) (http.Handler, string) {
+	t.Helper()
+	dir := t.TempDir()
+	opts.PartialPut = true
+	return davHandler(webdav.Dir(dir), "", webdav.NewMemLS(), opts, nil), dir
+}
+
+func putRangeRequest(h http.Handler, target, body, contentRange string) *httptest.ResponseRecorder {
+	return doRequest(h, http.MethodPut, target, body, map[string]string{"Content-Range": contentRange})
+}
+
+func TestPartialPut_Resume(t *testing.T) {
+	h, dir := newPartialPutHandler(t, Options{})
+
+	w := putRangeRequest(h, "/big.bin", "hello ", "bytes 0-5/11")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("first range status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	if got := w.Header().Get(uploadOffsetHeader); got != "6" {
+		t.Errorf("Upload-Offset = %q, want 6", got)
+	}
+	w = putRangeRequest(h, "/big.bin", "world", "bytes 6-10/11")
+	if w.Code != http.StatusCreated || w.Header().Get(uploadOffsetHeader) != "11" {
+		t.Errorf("second range status = %d, Upload-Offset = %q", w.Code, w.Header().Get(uploadOffsetHeader))
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "big.bin")); string(data) != "hello world" {
+		t.Errorf("file = %q, want %q", data, "hello world")
+	}
+
+	// Overwriting a range inside the file keeps the rest
+	putRangeRequest(h, "/big.bin", "W", "bytes 6-6/*")
+	if data, _ := os.ReadFile(filepath.Join(dir, "big.bin")); string(data) != "hello World" {
+		t.Errorf("file = %q, want %q", data, "hello World")
+	}
+
+	// A range starting at 0 begins a new upload
+	putRangeRequest(h, "/big.bin", "new", "bytes 0-2/3")
+	if data, _ := os.ReadFile(filepath.Join(dir, "big.bin")); string(data) != "new" {
+		t.Errorf("file = %q, want %q", data, "new")
+	}
+}
+
+func TestPartialPut_Gap(t *testing.T) {
+	h, dir := newPartialPutHandler(t, Options{})
+	if err := os.WriteFile(filepath.Join(dir, "a.bin"), []byte("abc"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := putRangeRequest(h, "/a.bin", "xyz", "bytes 5-7/8")
+	if w.Code != http.StatusRequestedRangeNotSatisfiable {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusRequestedRangeNotSatisfiable)
+	}
+	if w.Header().Get("Content-Range") != "bytes */3" || w.Header().Get(uploadOffsetHeader) != "3" {
+		t.Errorf("Content-Range = %q, Upload-Offset = %q", w.Header().Get("Content-Range"), w.Header().Get(uploadOffsetHeader))
+	}
+	if w := putRangeRequest(h, "/new.bin", "xyz", "bytes 1-3/*"); w.Code != http.StatusRequestedRangeNotSatisfiable {
+		t.Errorf("range into a missing file status = %d, want %d", w.Code, http.StatusRequestedRangeNotSatisfiable)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "a.bin")); string(data) != "abc" {
+		t.Errorf("file = %q, want it unchanged", data)
+	}
+}
+
+func TestPartialPut_Invalid(t *testing.T) {
+	h, dir := newPartialPutHandler(t, Options{})
+	if err := os.WriteFile(filepath.Join(dir, "a.bin"), []byte("abc"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, cr := range []string{"bytes 0-9/10", "items 0-2/3", "bytes 2-1/3", "bytes 0-2/2", "bytes x-2/*"} {
+		if w := putRangeRequest(h, "/a.bin", "xyz", cr); w.Code != http.StatusBadRequest {
+			t.Errorf("Content-Range %q status = %d, want %d", cr, w.Code, http.StatusBadRequest)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "a.bin")); string(data) != "abc" {
+		t.Errorf("file = %q, want it unchanged", data)
+	}
+}
+
+func TestPartialPut_ChunkedBodyLimited(t *testing.T) {
+	h, dir := newPartialPutHandler(t, Options{})
+	if err := os.WriteFile(filepath.Join(dir, "a.bin"), []byte("abc"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPut, "/a.bin", strings.NewReader("defghi"))
+	req.ContentLength = -1
+	req.TransferEncoding = []string{"chunked"}
+	req.Header.Set("Content-Range", "bytes 3-5/6")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "a.bin")); string(data) != "abcdef" {
+		t.Errorf("file = %q, want %q", data, "abcdef")
+	}
+}
+
+func TestPartialPut_QuotaKeepsWrittenRanges(t *testing.T) {
+	h, dir := newPartialPutHandler(t, Options{Quota: 8})
+	putRangeRequest(h, "/a.bin", "abcd", "bytes 0-3/12")
+	w := putRangeRequest(h, "/a.bin", "efghijkl", "bytes 4-11/12")
+	if w.Code != http.StatusInsufficientStorage {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInsufficientStorage)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "a.bin")); err != nil || !strings.HasPrefix(string(data), "abcd") {
+		t.Errorf("file = %q, %v, want the first range kept", data, err)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// strippableProps are the live properties that may be hidden from PROPFIND
+// responses because they reveal file sizes or timestamps
+var strippableProps = map[string]bool{
+	"creationdate":     true,
+	"getcontentlength": true,
+	"getetag":          true,
+	"getlastmodified":  true,
+}
+
+// validateStripProps checks that every name is a strippable live property
+func validateStripProps(names []string) error {
+	for _, name := range names {
+		if !strippableProps[name] {
+			return fmt.Errorf("property %q cannot be stripped", name)
+		}
+	}
+	return nil
+}
+
+// stripProps removes the named DAV: properties from PROPFIND multistatus
+// responses while keeping hrefs and all other properties intact
+func stripProps(next http.Handler, names []string) http.Handler {
+	strip := make(map[string]bool, len(names))
+	for _, name := range names {
+		strip[name] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PROPFIND" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := newBufferedResponse()
+		next.ServeHTTP(rec, r)
+		if rec.status == http.StatusMultiStatus {
+			filtered := removeElements(rec.body.Bytes(), strip)
+			rec.body.Reset()
+			rec.body.Write(filtered)
+			rec.header.Del("Content-Length")
+		}
+		rec.replay(w)
+	})
+}
+
+// removeElements cuts every DAV: element whose local name is in names out of
+// the XML document, leaving the remaining bytes untouched. The document is
+// returned unchanged if it cannot be parsed.
+func removeElements(doc []byte, names map[string]bool) []byte {
+	d := xml.NewDecoder(bytes.NewReader(doc))
+	var out bytes.Buffer
+	var copied, start int64
+	depth := 0
+
+	for {
+		offset := d.InputOffset()
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return doc
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if depth > 0 {
+				depth++
+			} else if t.Name.Space == "DAV:" && names[t.Name.Local] {
+				start = offset
+				depth = 1
+			}
+		case xml.EndElement:
+			if depth > 0 {
+				depth--
+				if depth == 0 {
+					out.Write(doc[copied:start])
+					copied = d.InputOffset()
+				}
+			}
+		}
+	}
+
+	out.Write(doc[copied:])
+	return out.Bytes()
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStripProps(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "file.txt"), []byte("content"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	srv, err := NewWithOptions(Options{
+		Folder:          tmpDir,
+		Port:            18080,
+		Bind:            "127.0.0.1",
+		StripProperties: []string{"getlastmodified"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+
+	rec := doRequest(srv.Handler(), "PROPFIND", "/", "", map[string]string{"Depth": "1"})
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("PROPFIND status = %d, want %d", rec.Code, http.StatusMultiStatus)
+	}
+
+	body := rec.Body.String()
+	if strings.Contains(body, "getlastmodified") {
+		t.Errorf("PROPFIND response should not contain getlastmodified: %s", body)
+	}
+	for _, want := range []string{"/file.txt</D:href>", "getcontentlength", "resourcetype"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("PROPFIND response should contain %q: %s", want, body)
+		}
+	}
+}
+
+func TestStripPropsDefault(t *testing.T) {
+	srv := New(t.TempDir(), 18080, "127.0.0.1", nil)
+
+	rec := doRequest(srv.Handler(), "PROPFIND", "/", "", map[string]string{"Depth": "0"})
+	if !strings.Contains(rec.Body.String(), "getlastmodified") {
+		t.Error("PROPFIND response should contain getlastmodified by default")
+	}
+}
+
+func TestRemoveElements(t *testing.T) {
+	doc := `<D:multistatus xmlns:D="DAV:"><D:prop><D:getetag>"x"</D:getetag><D:getcontentlength/><x:getetag xmlns:x="urn:other">keep</x:getetag></D:prop></D:multistatus>`
+	want := `<D:multistatus xmlns:D="DAV:"><D:prop><x:getetag xmlns:x="urn:other">keep</x:getetag></D:prop></D:multistatus>`
+
+	got := string(removeElements([]byte(doc), map[string]bool{"getetag": true, "getcontentlength": true}))
+	if got != want {
+		t.Errorf("removeElements() = %s, want %s", got, want)
+	}
+}
+
+func TestValidateStripProps(t *testing.T) {
+	if err := validateStripProps([]string{"getlastmodified", "creationdate"}); err != nil {
+		t.Errorf("validateStripProps() error = %v", err)
+	}
+	if err := validateStripProps([]string{"resourcetype"}); err == nil {
+		t.Error("validateStripProps() should reject resourcetype")
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// propfindDepths are the Depth values a PROPFIND can carry
+var propfindDepths = map[string]bool{"0": true, "1": true, "infinity": true}
+
+// parsePropfindDepths validates the allowed Depth values and returns them as
+// a set
+func parsePropfindDepths(values []string) (map[string]bool, error) {
+	allowed := make(map[string]bool, len(values))
+	for _, v := range values {
+		depth := strings.ToLower(strings.TrimSpace(v))
+		if !propfindDepths[depth] {
+			return nil, fmt.Errorf("invalid PROPFIND depth %q (want 0, 1 or infinity)", v)
+		}
+		allowed[depth] = true
+	}
+	return allowed, nil
+}
+
+// limitPropfindDepth rejects PROPFIND requests whose Depth is not in allowed
+// with 403 and a DAV:error body. A missing Depth header means infinity, as
+// RFC 4918 §9.1 defines; values that are not depths at all are left to the
+// WebDAV handler.
+func limitPropfindDepth(next http.Handler, allowed map[string]bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PROPFIND" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		depth := strings.ToLower(r.Header.Get("Depth"))
+		if depth == "" {
+			depth = "infinity"
+		}
+		if !propfindDepths[depth] || allowed[depth] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		refuseDepth(w, depth)
+	})
+}
+
+// refuseDepth answers a request for a depth that is not allowed with 403
+// and a DAV:error body
+func refuseDepth(w http.ResponseWriter, depth string) {
+	// propfind-finite-depth is the precondition RFC 4918 names for refusing
+	// infinity; other depths get an empty error element
+	body := `<D:error xmlns:D="DAV:"/>`
+	if depth == "infinity" {
+		body = `<D:error xmlns:D="DAV:"><D:propfind-finite-depth/></D:error>`
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusForbidden)
+	fmt.Fprintf(w, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n%s\n", body)
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPropfindAllowedDepths(t *testing.T) {
+	srv, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18080, Bind: "127.0.0.1", PropfindAllowedDepths: []string{"0", "1"}}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	h := srv.Handler()
+
+	tests := []struct {
+		name  string
+		depth string
+		want  int
+	}{
+		{name: "depth 0", depth: "0", want: http.StatusMultiStatus},
+		{name: "depth 1", depth: "1", want: http.StatusMultiStatus},
+		{name: "depth infinity", depth: "infinity", want: http.StatusForbidden},
+		{name: "depth infinity in upper case", depth: "Infinity", want: http.StatusForbidden},
+		{name: "no depth", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var headers map[string]string
+			if tt.depth != "" {
+				headers = map[string]string{"Depth": tt.depth}
+			}
+			rec := doRequest(h, "PROPFIND", "/", "", headers)
+			if rec.Code != tt.want {
+				t.Fatalf("PROPFIND status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusForbidden && !strings.Contains(rec.Body.String(), "<D:propfind-finite-depth/>") {
+				t.Errorf("body = %q, want a propfind-finite-depth error", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestPropfindAllowedDepthsInfinityOnly(t *testing.T) {
+	srv, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18080, Bind: "127.0.0.1", PropfindAllowedDepths: []string{"infinity"}}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+
+	rec := doRequest(srv.Handler(), "PROPFIND", "/", "", map[string]string{"Depth": "1"})
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("PROPFIND Depth 1 status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+	if !strings.Contains(rec.Body.String(), "<D:error") || strings.Contains(rec.Body.String(), "propfind-finite-depth") {
+		t.Errorf("body = %q, want an empty DAV error", rec.Body.String())
+	}
+	// Other methods with a Depth header are not affected
+	if rec := doRequest(srv.Handler(), "COPY", "/", "", map[string]string{"Depth": "0", "Destination": "/copy"}); rec.Code == http.StatusForbidden {
+		t.Errorf("COPY status = %d, want it passed through", rec.Code)
+	}
+}
+
+func TestPropfindAllowedDepthsInvalid(t *testing.T) {
+	_, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18080, Bind: "127.0.0.1", PropfindAllowedDepths: []string{"0", "2"}}, nil)
+	if err == nil || !strings.Contains(err.Error(), `"2"`) {
+		t.Errorf("NewWithOptions() error = %v, want invalid depth", err)
+	}
+}
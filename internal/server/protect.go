@@ -0,0 +1,112 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"golang.org/x/net/webdav"
+)
+
+// DefaultProtectedNames are file names that may change how a host treats the
+// served tree if it is ever interpreted by a web server or shell account
+var DefaultProtectedNames = []string{
+	".htaccess",
+	".htpasswd",
+	".user.ini",
+	"web.config",
+	"authorized_keys",
+	"authorized_keys2",
+}
+
+// protectNames rejects with 403 any PUT, COPY, MOVE, DELETE or LOCK that
+// would create, overwrite or remove a file whose base name is protected.
+// Names are compared case-insensitively. Copying, moving, overwriting or
+// removing a collection is rejected if it contains a protected file.
+func protectNames(next http.Handler, fs webdav.FileSystem, names []string) http.Handler {
+	protected := make(map[string]bool, len(names))
+	for _, name := range names {
+		protected[strings.ToLower(name)] = true
+	}
+	isProtected := func(p string) bool {
+		return protected[strings.ToLower(path.Base(p))]
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var targets, trees []string
+		switch r.Method {
+		case http.MethodPut:
+			targets = []string{r.URL.Path}
+		case "COPY":
+			targets = []string{destinationPath(r)}
+			trees = []string{destinationPath(r)}
+			if r.Header.Get("Depth") != "0" {
+				trees = append(trees, r.URL.Path)
+			}
+		case "MOVE":
+			targets = []string{r.URL.Path, destinationPath(r)}
+			trees = []string{r.URL.Path, destinationPath(r)}
+		case http.MethodDelete:
+			targets = []string{r.URL.Path}
+			trees = []string{r.URL.Path}
+		case "LOCK":
+			// Locking an unmapped name creates an empty file
+			targets = []string{r.URL.Path}
+		}
+
+		for _, t := range targets {
+			if t != "" && isProtected(t) {
+				http.Error(w, "Forbidden: protected file name", http.StatusForbidden)
+				return
+			}
+		}
+		for _, t := range trees {
+			if t != "" && containsMatch(r.Context(), fs, t, isProtected) {
+				http.Error(w, "Forbidden: collection contains a protected file", http.StatusForbidden)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// destinationPath returns the path of the Destination header, or an empty
+// string when it is missing or malformed
+func destinationPath(r *http.Request) string {
+	u, err := url.Parse(r.Header.Get("Destination"))
+	if err != nil {
+		return ""
+	}
+	return u.Path
+}
+
+// containsMatch reports whether the collection at name contains, at any depth,
+// an entry whose path satisfies match
+func containsMatch(ctx context.Context, fs webdav.FileSystem, name string, match func(string) bool) bool {
+	f, err := fs.OpenFile(ctx, name, 0, 0)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil || !fi.IsDir() {
+		return false
+	}
+	entries, err := f.Readdir(-1)
+	if err != nil {
+		return false
+	}
+	for _, e := range entries {
+		child := path.Join(name, e.Name())
+		if match(child) || (e.IsDir() && containsMatch(ctx, fs, child, match)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newProtectServer(t *testing.T) (http.Handler, string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, "site"), 0755); err != nil {
+		t.Fatalf("Failed to create test dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "site", ".htaccess"), []byte("Deny from all"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "notes.txt"), []byte("notes"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	srv, err := NewWithOptions(Options{Folder: tmpDir, Port: 18080, Bind: "127.0.0.1", ProtectedNames: DefaultProtectedNames}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	return srv.Handler(), tmpDir
+}
+
+func TestProtectNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		target  string
+		headers map[string]string
+		want    int
+	}{
+		{name: "PUT htaccess", method: http.MethodPut, target: "/.htaccess", want: http.StatusForbidden},
+		{name: "PUT case-insensitive", method: http.MethodPut, target: "/site/WEB.CONFIG", want: http.StatusForbidden},
+		{name: "PUT regular file", method: http.MethodPut, target: "/index.html", want: http.StatusCreated},
+		{name: "DELETE htaccess", method: http.MethodDelete, target: "/site/.htaccess", want: http.StatusForbidden},
+		{name: "DELETE collection with htaccess", method: http.MethodDelete, target: "/site", want: http.StatusForbidden},
+		{
+			name:    "MOVE onto protected name",
+			method:  "MOVE",
+			target:  "/notes.txt",
+			headers: map[string]string{"Destination": "http://example.com/authorized_keys"},
+			want:    http.StatusForbidden,
+		},
+		{
+			name:    "COPY onto protected name",
+			method:  "COPY",
+			target:  "/notes.txt",
+			headers: map[string]string{"Destination": "http://example.com/site/.htpasswd"},
+			want:    http.StatusForbidden,
+		},
+		{
+			name:    "COPY collection with htaccess",
+			method:  "COPY",
+			target:  "/site",
+			headers: map[string]string{"Destination": "http://example.com/copy"},
+			want:    http.StatusForbidden,
+		},
+		{
+			name:    "COPY collection without members",
+			method:  "COPY",
+			target:  "/site",
+			headers: map[string]string{"Destination": "http://example.com/empty", "Depth": "0"},
+			want:    http.StatusCreated,
+		},
+		{
+			name:    "COPY over collection with htaccess",
+			method:  "COPY",
+			target:  "/notes.txt",
+			headers: map[string]string{"Destination": "http://example.com/site"},
+			want:    http.StatusForbidden,
+		},
+		{
+			name:    "MOVE over collection with htaccess",
+			method:  "MOVE",
+			target:  "/notes.txt",
+			headers: map[string]string{"Destination": "http://example.com/site"},
+			want:    http.StatusForbidden,
+		},
+		{name: "LOCK unmapped htaccess", method: "LOCK", target: "/.htaccess", want: http.StatusForbidden},
+		{name: "DELETE regular file", method: http.MethodDelete, target: "/notes.txt", want: http.StatusNoContent},
+	}
+
+	h, tmpDir := newProtectServer(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(h, tt.method, tt.target, "data", tt.headers)
+			if rec.Code != tt.want {
+				t.Errorf("%s %s status = %d, want %d", tt.method, tt.target, rec.Code, tt.want)
+			}
+		})
+	}
+
+	for _, name := range []string{".htaccess", filepath.Join("copy", ".htaccess")} {
+		if _, err := os.Stat(filepath.Join(tmpDir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s was created: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "site", ".htaccess")); err != nil {
+		t.Errorf("protected file was removed: %v", err)
+	}
+}
+
+func TestProtectNamesDisabled(t *testing.T) {
+	h, tmpDir := newIfTestServer(t)
+
+	rec := doRequest(h, http.MethodPut, "/.htaccess", "data", nil)
+	if rec.Code != http.StatusCreated {
+		t.Errorf("PUT .htaccess without protection status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, ".htaccess")); err != nil {
+		t.Errorf("PUT .htaccess should create the file: %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"os"
+	"sync"
+
+	"golang.org/x/net/webdav"
+)
+
+// errQuotaExceeded rejects writes that would take the served tree over its quota
+var errQuotaExceeded = newStatusError(http.StatusInsufficientStorage, "Insufficient Storage: quota exceeded", nil)
+
+// quota tracks the total size of the files in a served tree against a
+// limit. The size is computed once, in the background from startup, and
+// kept up to date by quotaFS afterwards.
+type quota struct {
+	limit int64
+	fs    webdav.FileSystem
+	once  sync.Once
+	mu    sync.Mutex
+	used  int64
+}
+
+// newQuota returns a quota of limit bytes for fs and starts computing the
+// size of fs
+func newQuota(fs webdav.FileSystem, limit int64) *quota {
+	q := &quota{limit: limit, fs: fs}
+	go q.usage()
+	return q
+}
+
+// load computes the initial size on first use. Callers wait for it.
+func (q *quota) load() {
+	q.once.Do(func() {
+		used := treeSize(context.Background(), q.fs, "/", true, math.MaxInt64)
+		q.mu.Lock()
+		q.used += used
+		q.mu.Unlock()
+	})
+}
+
+// usage returns the bytes used
+func (q *quota) usage() int64 {
+	q.load()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.used
+}
+
+// fits reports whether n more bytes stay within the limit
+func (q *quota) fits(n int64) bool {
+	return n <= 0 || q.usage()+n <= q.limit
+}
+
+// reserve adds n bytes to the usage if they fit
+func (q *quota) reserve(n int64) bool {
+	q.load()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if n > 0 && q.used+n > q.limit {
+		return false
+	}
+	q.used += n
+	return true
+}
+
+// release subtracts n bytes from the usage
+func (q *quota) release(n int64) {
+	q.load()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.used = max(q.used-n, 0)
+}
+
+// quotaExceededKey stores the *bool set when a write of the request hit the quota
+type quotaExceededKey struct{}
+
+// middleware rejects PUT, COPY and MKCOL with 507 when their growth of the
+// tree is known up front and does not fit, so no partial data is written.
+// Bodies of unknown length are cut off by quotaFS, and a PUT target left
+// behind is removed. fs resolves request paths.
+func (q *quota) middleware(next http.Handler, fs webdav.FileSystem) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var grow int64
+		switch r.Method {
+		case http.MethodPut:
+			if r.ContentLength > 0 {
+				grow = r.ContentLength
+				if fi, err := fs.Stat(r.Context(), r.URL.Path); err == nil && !fi.IsDir() {
+					grow -= fi.Size()
+				}
+			}
+		case "COPY":
+			grow = treeSize(r.Context(), fs, r.URL.Path, r.Header.Get("Depth") != "0", q.limit)
+		case "MKCOL":
+			// Collections take no space of their own, but a full tree gets no more
+			grow = 1
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !q.fits(grow) {
+			http.Error(w, errQuotaExceeded.msg, errQuotaExceeded.status)
+			return
+		}
+
+		exceeded := false
+		r = r.WithContext(context.WithValue(r.Context(), quotaExceededKey{}, &exceeded))
+		next.ServeHTTP(w, r)
+		// A partial PUT keeps the ranges written before
+		if exceeded && r.Method == http.MethodPut && putRangeFrom(r.Context()) == nil {
+			fs.RemoveAll(r.Context(), r.URL.Path)
+		}
+	})
+}
+
+// quotaFS accounts every change in size of the files of a tree to its quota,
+// and fails writes that do not fit with errQuotaExceeded
+type quotaFS struct {
+	webdav.FileSystem
+	quota *quota
+}
+
+func (fs quotaFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_APPEND|os.O_TRUNC) == 0 {
+		return fs.FileSystem.OpenFile(ctx, name, flag, perm)
+	}
+
+	var size int64
+	if fi, err := fs.FileSystem.Stat(ctx, name); err == nil && !fi.IsDir() {
+		size = fi.Size()
+	}
+	f, err := fs.FileSystem.OpenFile(ctx, name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	if flag&os.O_TRUNC != 0 {
+		fs.quota.release(size)
+		size = 0
+	}
+	qf := &quotaFile{File: f, quota: fs.quota, size: size}
+	if flag&os.O_APPEND != 0 {
+		qf.pos = size
+	}
+	qf.exceeded, _ = ctx.Value(quotaExceededKey{}).(*bool)
+	return qf, nil
+}
+
+func (fs quotaFS) RemoveAll(ctx context.Context, name string) error {
+	before := treeSize(ctx, fs.FileSystem, name, true, math.MaxInt64)
+	err := fs.FileSystem.RemoveAll(ctx, name)
+	if err != nil {
+		// Part of the tree may be gone
+		before -= treeSize(ctx, fs.FileSystem, name, true, math.MaxInt64)
+	}
+	fs.quota.release(before)
+	return err
+}
+
+// quotaFile reserves the bytes a write adds beyond the end of the file
+type quotaFile struct {
+	webdav.File
+	quota    *quota
+	size     int64
+	pos      int64
+	exceeded *bool
+}
+
+func (f *quotaFile) Write(p []byte) (int, error) {
+	start := f.size
+	grow := max(f.pos+int64(len(p))-start, 0)
+	if !f.quota.reserve(grow) {
+		if f.exceeded != nil {
+			*f.exceeded = true
+		}
+		return 0, errQuotaExceeded
+	}
+	n, err := f.File.Write(p)
+	f.pos += int64(n)
+	f.size = max(f.size, f.pos)
+	// Give back what a short write did not use
+	f.quota.release(grow - (f.size - start))
+	return n, err
+}
+
+func (f *quotaFile) Seek(offset int64, whence int) (int64, error) {
+	pos, err := f.File.Seek(offset, whence)
+	if err == nil {
+		f.pos = pos
+	}
+	return pos, err
+}
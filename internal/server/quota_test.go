@@ -0,0 +1,109 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/net/webdav"
+)
+
+func newQuotaServer(t *testing.T, limit int64) (http.Handler, string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	os.Mkdir(filepath.Join(tmpDir, "sub"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "sub", "existing.txt"), make([]byte, 40), 0644)
+	srv, err := NewWithOptions(Options{Folder: tmpDir, Bind: "127.0.0.1", Quota: limit}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	return srv.Handler(), tmpDir
+}
+
+func TestQuotaPut(t *testing.T) {
+	h, dir := newQuotaServer(t, 100)
+
+	if rec := doRequest(h, http.MethodPut, "/a.txt", strings.Repeat("a", 50), nil); rec.Code != http.StatusCreated {
+		t.Fatalf("PUT within the quota status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	rec := doRequest(h, http.MethodPut, "/b.txt", strings.Repeat("b", 20), nil)
+	if rec.Code != http.StatusInsufficientStorage {
+		t.Errorf("PUT over the quota status = %d, want %d", rec.Code, http.StatusInsufficientStorage)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "b.txt")); !os.IsNotExist(err) {
+		t.Errorf("rejected PUT created the file: %v", err)
+	}
+
+	// Overwriting counts the difference in size only
+	if rec := doRequest(h, http.MethodPut, "/a.txt", strings.Repeat("a", 60), nil); rec.Code >= 300 {
+		t.Errorf("PUT replacing a file within the quota status = %d, want success", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPut, "/chunked.txt", strings.NewReader(strings.Repeat("c", 64*1024)))
+	req.ContentLength = -1
+	req.TransferEncoding = []string{"chunked"}
+	chunked := httptest.NewRecorder()
+	h.ServeHTTP(chunked, req)
+	if chunked.Code != http.StatusInsufficientStorage {
+		t.Errorf("chunked PUT over the quota status = %d, want %d", chunked.Code, http.StatusInsufficientStorage)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "chunked.txt")); !os.IsNotExist(err) {
+		t.Errorf("chunked PUT over the quota left the file behind: %v", err)
+	}
+
+	// Deleting frees space for new files
+	if rec := doRequest(h, http.MethodDelete, "/sub", "", nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("DELETE status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if rec := doRequest(h, http.MethodPut, "/b.txt", strings.Repeat("b", 40), nil); rec.Code != http.StatusCreated {
+		t.Errorf("PUT after DELETE status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+}
+
+func TestQuotaCopyMkcol(t *testing.T) {
+	h, _ := newQuotaServer(t, 100)
+
+	if rec := doRequest(h, "COPY", "/sub", "", map[string]string{"Destination": "/copy"}); rec.Code != http.StatusCreated {
+		t.Fatalf("COPY within the quota status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if rec := doRequest(h, "COPY", "/sub", "", map[string]string{"Destination": "/copy2"}); rec.Code != http.StatusInsufficientStorage {
+		t.Errorf("COPY over the quota status = %d, want %d", rec.Code, http.StatusInsufficientStorage)
+	}
+	if rec := doRequest(h, "MKCOL", "/new", "", nil); rec.Code != http.StatusCreated {
+		t.Errorf("MKCOL below the quota status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if rec := doRequest(h, http.MethodPut, "/fill.txt", strings.Repeat("f", 20), nil); rec.Code != http.StatusCreated {
+		t.Fatalf("PUT filling the quota status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if rec := doRequest(h, "MKCOL", "/full", "", nil); rec.Code != http.StatusInsufficientStorage {
+		t.Errorf("MKCOL with the quota used up status = %d, want %d", rec.Code, http.StatusInsufficientStorage)
+	}
+}
+
+func TestQuotaUsageConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.txt"), make([]byte, 10), 0644)
+	os.Mkdir(filepath.Join(dir, "sub"), 0755)
+	os.WriteFile(filepath.Join(dir, "sub", "b.txt"), make([]byte, 5), 0644)
+	q := newQuota(webdav.Dir(dir), 100)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !q.reserve(1) {
+				t.Error("reserve(1) failed below the quota")
+			}
+		}()
+	}
+	wg.Wait()
+	if got := q.usage(); got != 23 {
+		t.Errorf("usage() = %d, want 23", got)
+	}
+}
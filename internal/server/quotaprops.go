@@ -0,0 +1,139 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"strconv"
+
+	"golang.org/x/net/webdav"
+)
+
+// The RFC 4331 quota properties
+var (
+	quotaAvailableProp = xml.Name{Space: "DAV:", Local: "quota-available-bytes"}
+	quotaUsedProp      = xml.Name{Space: "DAV:", Local: "quota-used-bytes"}
+)
+
+// quotaPropsKey marks PROPFIND requests that name a quota property
+type quotaPropsKey struct{}
+
+// quotaPropsRequest marks PROPFIND requests naming quota-available-bytes or
+// quota-used-bytes for quotaPropsFS. RFC 4331 leaves the properties out of
+// allprop, and computing them for every member of a listing is wasted work.
+func quotaPropsRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PROPFIND" || r.Body == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxPropfindBody))
+		if err != nil {
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if namesQuotaProps(body) {
+			r = r.WithContext(context.WithValue(r.Context(), quotaPropsKey{}, true))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// namesQuotaProps reports whether a propfind document names a quota property
+func namesQuotaProps(doc []byte) bool {
+	d := xml.NewDecoder(bytes.NewReader(doc))
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return false
+		}
+		if t, ok := tok.(xml.StartElement); ok && (t.Name == quotaAvailableProp || t.Name == quotaUsedProp) {
+			return true
+		}
+	}
+}
+
+// quotaPropsFS reports the quota properties of collections to PROPFIND
+// requests marked by quotaPropsRequest. The figures are those of the quota
+// when one is set, limited by the free space of the disk, and those of the
+// disk holding dir otherwise.
+type quotaPropsFS struct {
+	webdav.FileSystem
+	dir   string
+	quota *quota
+}
+
+func (fs quotaPropsFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	f, err := fs.FileSystem.OpenFile(ctx, name, flag, perm)
+	if err != nil || flag != os.O_RDONLY || ctx.Value(quotaPropsKey{}) == nil {
+		return f, err
+	}
+	if fi, err := f.Stat(); err != nil || !fi.IsDir() {
+		return f, nil
+	}
+	return &quotaPropsFile{File: f, fs: fs}, nil
+}
+
+// usage returns the bytes used and available
+func (fs quotaPropsFS) usage() (used, available uint64, err error) {
+	var diskErr error
+	if fs.dir != "" {
+		used, available, diskErr = diskUsage(fs.dir)
+	}
+	if fs.quota == nil {
+		return used, available, diskErr
+	}
+
+	quotaUsed := fs.quota.usage()
+	quotaAvailable := uint64(max(fs.quota.limit-quotaUsed, 0))
+	if fs.dir != "" && diskErr == nil {
+		quotaAvailable = min(quotaAvailable, available)
+	}
+	return uint64(quotaUsed), quotaAvailable, nil
+}
+
+// quotaPropsFile offers the quota properties as dead properties, the one
+// way the WebDAV handler takes properties from a file system
+type quotaPropsFile struct {
+	webdav.File
+	fs quotaPropsFS
+}
+
+// DeadProps adds the quota properties to those the file holds itself
+func (f *quotaPropsFile) DeadProps() (map[xml.Name]webdav.Property, error) {
+	props := make(map[xml.Name]webdav.Property)
+	if dph, ok := f.File.(webdav.DeadPropsHolder); ok {
+		held, err := dph.DeadProps()
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(props, held)
+	}
+	used, available, err := f.fs.usage()
+	if err != nil {
+		// Leave the properties out, so they are reported as not found
+		return props, nil
+	}
+	props[quotaAvailableProp] = webdav.Property{XMLName: quotaAvailableProp, InnerXML: []byte(strconv.FormatUint(available, 10))}
+	props[quotaUsedProp] = webdav.Property{XMLName: quotaUsedProp, InnerXML: []byte(strconv.FormatUint(used, 10))}
+	return props, nil
+}
+
+// Patch is never called, as only read-only opens get a quotaPropsFile
+func (f *quotaPropsFile) Patch(patches []webdav.Proppatch) ([]webdav.Propstat, error) {
+	pstat := webdav.Propstat{Status: http.StatusForbidden}
+	for _, patch := range patches {
+		for _, p := range patch.Props {
+			pstat.Props = append(pstat.Props, webdav.Property{XMLName: p.XMLName})
+		}
+	}
+	return []webdav.Propstat{pstat}, nil
+}
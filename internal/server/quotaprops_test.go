@@ -0,0 +1,93 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const quotaPropfind = `<?xml version="1.0"?>
+<D:propfind xmlns:D="DAV:"><D:prop><D:quota-available-bytes/><D:quota-used-bytes/></D:prop></D:propfind>`
+
+// quotaValues returns the quota properties of the first response in a
+// multistatus body, or -1 for those missing
+func quotaValues(t *testing.T, body string) (available, used int64) {
+	t.Helper()
+
+	read := func(name string) int64 {
+		m := regexp.MustCompile(`<D:` + name + `[^>]*>(\d+)</D:` + name + `>`).FindStringSubmatch(body)
+		if m == nil {
+			return -1
+		}
+		n, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			t.Fatalf("%s value %q: %v", name, m[1], err)
+		}
+		return n
+	}
+	return read("quota-available-bytes"), read("quota-used-bytes")
+}
+
+func TestReportQuotaDisk(t *testing.T) {
+	dir := t.TempDir()
+	srv, err := NewWithOptions(Options{Folder: dir, Bind: "127.0.0.1", ReportQuota: true}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+
+	rec := doRequest(srv.Handler(), "PROPFIND", "/", quotaPropfind, map[string]string{"Depth": "0"})
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("PROPFIND status = %d, want %d", rec.Code, http.StatusMultiStatus)
+	}
+	available, used := quotaValues(t, rec.Body.String())
+	diskUsed, diskAvailable, err := diskUsage(dir)
+	if err != nil {
+		t.Fatalf("diskUsage() error = %v", err)
+	}
+
+	// Other processes may write to the disk in between
+	const slack = 64 << 20
+	if d := available - int64(diskAvailable); d < -slack || d > slack {
+		t.Errorf("quota-available-bytes = %d, disk has %d available", available, diskAvailable)
+	}
+	if d := used - int64(diskUsed); d < -slack || d > slack {
+		t.Errorf("quota-used-bytes = %d, disk has %d used", used, diskUsed)
+	}
+}
+
+func TestReportQuotaWithQuota(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "file.txt"), make([]byte, 40), 0644)
+	srv, err := NewWithOptions(Options{Folder: dir, Bind: "127.0.0.1", Quota: 1000, ReportQuota: true}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	h := srv.Handler()
+
+	rec := doRequest(h, "PROPFIND", "/", quotaPropfind, map[string]string{"Depth": "0"})
+	if available, used := quotaValues(t, rec.Body.String()); available != 960 || used != 40 {
+		t.Errorf("quota = %d available, %d used, want 960 and 40", available, used)
+	}
+
+	doRequest(h, http.MethodPut, "/more.txt", strings.Repeat("m", 100), nil)
+	rec = doRequest(h, "PROPFIND", "/", quotaPropfind, map[string]string{"Depth": "0"})
+	if available, used := quotaValues(t, rec.Body.String()); available != 860 || used != 140 {
+		t.Errorf("quota after PUT = %d available, %d used, want 860 and 140", available, used)
+	}
+
+	// Files have no quota properties
+	rec = doRequest(h, "PROPFIND", "/file.txt", quotaPropfind, map[string]string{"Depth": "0"})
+	if available, used := quotaValues(t, rec.Body.String()); available != -1 || used != -1 {
+		t.Errorf("PROPFIND of a file reported a quota: %s", rec.Body.String())
+	}
+
+	// RFC 4331 leaves them out of allprop
+	rec = doRequest(h, "PROPFIND", "/", "", map[string]string{"Depth": "0"})
+	if strings.Contains(rec.Body.String(), "quota-") {
+		t.Errorf("allprop PROPFIND reported a quota: %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// rangeHandlers returns handlers serving a file "video.mp4" holding the
+// digits 0-9 with the options that change how GET is answered
+func rangeHandlers(t *testing.T) map[string]http.Handler {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "video.mp4"), []byte("0123456789"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	handlers := make(map[string]http.Handler)
+	for name, opts := range map[string]Options{
+		"default":  {},
+		"buffered": {ResponseBufferSize: 4096},
+		"gzip":     {Gzip: true},
+	} {
+		opts.Folder, opts.Port = dir, 18080
+		srv, err := NewWithOptions(opts, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		handlers[name] = srv.Handler()
+	}
+	return handlers
+}
+
+func TestRange_AcceptRanges(t *testing.T) {
+	for name, h := range rangeHandlers(t) {
+		for _, method := range []string{http.MethodGet, http.MethodHead} {
+			w := doRequest(h, method, "/video.mp4", "", nil)
+			if w.Code != http.StatusOK {
+				t.Errorf("%s: %s status = %d, want %d", name, method, w.Code, http.StatusOK)
+			}
+			if got := w.Header().Get("Accept-Ranges"); got != "bytes" {
+				t.Errorf("%s: %s Accept-Ranges = %q, want bytes", name, method, got)
+			}
+		}
+	}
+}
+
+func TestRange_MidFile(t *testing.T) {
+	for name, h := range rangeHandlers(t) {
+		w := doRequest(h, http.MethodGet, "/video.mp4", "", map[string]string{"Range": "bytes=3-6"})
+		if w.Code != http.StatusPartialContent {
+			t.Errorf("%s: status = %d, want %d", name, w.Code, http.StatusPartialContent)
+		}
+		if got := w.Header().Get("Content-Range"); got != "bytes 3-6/10" {
+			t.Errorf("%s: Content-Range = %q, want %q", name, got, "bytes 3-6/10")
+		}
+		if got := w.Header().Get("Content-Length"); got != "4" {
+			t.Errorf("%s: Content-Length = %q, want 4", name, got)
+		}
+		if got := w.Body.String(); got != "3456" {
+			t.Errorf("%s: body = %q, want %q", name, got, "3456")
+		}
+
+		w = doRequest(h, http.MethodGet, "/video.mp4", "", map[string]string{"Range": "bytes=-2"})
+		if w.Code != http.StatusPartialContent || w.Body.String() != "89" {
+			t.Errorf("%s: suffix range status = %d, body = %q, want 206 and %q", name, w.Code, w.Body.String(), "89")
+		}
+	}
+}
+
+func TestRange_Unsatisfiable(t *testing.T) {
+	for name, h := range rangeHandlers(t) {
+		w := doRequest(h, http.MethodGet, "/video.mp4", "", map[string]string{"Range": "bytes=20-30"})
+		if w.Code != http.StatusRequestedRangeNotSatisfiable {
+			t.Errorf("%s: status = %d, want %d", name, w.Code, http.StatusRequestedRangeNotSatisfiable)
+		}
+		if got := w.Header().Get("Content-Range"); got != "bytes */10" {
+			t.Errorf("%s: Content-Range = %q, want %q", name, got, "bytes */10")
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rate limiter sweeping: buckets idle for rateLimitIdle are evicted every
+// rateLimitSweep
+const (
+	rateLimitSweep = time.Minute
+	rateLimitIdle  = 5 * time.Minute
+)
+
+// rateLimiter keeps a token bucket per client IP. Each bucket holds up to
+// rate tokens and refills at rate tokens per second. Methods with their own
+// rate get a second bucket per client IP, which their requests have to pass
+// as well.
+type rateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	methods map[string]float64
+	buckets map[string]*bucket
+	proxies []*net.IPNet
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter returns a limiter allowing rate requests per second, or any
+// number when rate is 0, and the rates in methods for their methods
+func newRateLimiter(rate int, methods map[string]int, proxies []*net.IPNet) *rateLimiter {
+	perMethod := make(map[string]float64, len(methods))
+	for method, n := range methods {
+		perMethod[method] = float64(n)
+	}
+	return &rateLimiter{
+		rate:    float64(rate),
+		methods: perMethod,
+		buckets: make(map[string]*bucket),
+		proxies: proxies,
+		now:     time.Now,
+	}
+}
+
+// allow takes a token from the bucket of key. When the bucket is empty it
+// returns false and how long until a token is available.
+func (l *rateLimiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.take(key, l.rate)
+}
+
+// allowMethod takes a token from the bucket of key for method, when the
+// method has its own rate, and then from the bucket of key. A request
+// rejected for its method does not use up the overall rate.
+func (l *rateLimiter) allowMethod(key, method string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if rate, ok := l.methods[method]; ok {
+		if ok, wait := l.take(method+" "+key, rate); !ok {
+			return false, wait
+		}
+	}
+	if l.rate == 0 {
+		return true, 0
+	}
+	return l.take(key, l.rate)
+}
+
+// take takes a token from the bucket of key refilling at rate. The caller
+// holds l.mu.
+func (l *rateLimiter) take(key string, rate float64) (bool, time.Duration) {
+	now := l.now()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: rate}
+		l.buckets[key] = b
+	} else {
+		b.tokens = math.Min(rate, b.tokens+now.Sub(b.last).Seconds()*rate)
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep evicts buckets idle for longer than idle. An idle bucket has refilled
+// completely, so dropping it does not change any client's allowance.
+func (l *rateLimiter) sweep(idle time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for key, b := range l.buckets {
+		if now.Sub(b.last) > idle {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// startSweeper sweeps idle buckets of the limiter returned by current in the
+// background until the returned function is called. The limiter is looked up
+// on every sweep since a reload may replace it.
+func startSweeper(current func() *rateLimiter) func() {
+	ticker := time.NewTicker(rateLimitSweep)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if l := current(); l != nil {
+					l.sweep(rateLimitIdle)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+// middleware rejects requests beyond the client's rate with 429 and a
+// Retry-After header in whole seconds
+func (l *rateLimiter) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ok, wait := l.allowMethod(clientIP(r, l.proxies).String(), r.Method)
+		if !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ParseMethodRates parses per-method request rates such as
+// "PROPFIND=10/s,PUT=5/s" into requests per second by method. The "/s"
+// suffix is optional; method names are upper-cased.
+func ParseMethodRates(s string) (map[string]int, error) {
+	rates := make(map[string]int)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		method, value, ok := strings.Cut(item, "=")
+		method = strings.ToUpper(strings.TrimSpace(method))
+		value = strings.TrimSpace(value)
+		if lower := strings.ToLower(value); strings.HasSuffix(lower, "/s") {
+			value = value[:len(value)-len("/s")]
+		}
+		n, err := strconv.Atoi(value)
+		if !ok || method == "" || err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid method rate: %q (want METHOD=N/s)", item)
+		}
+		rates[method] = n
+	}
+	return rates, nil
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := newRateLimiter(2, nil, nil)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.allow("a"); !ok {
+			t.Fatalf("request %d within the burst should be allowed", i+1)
+		}
+	}
+	ok, wait := l.allow("a")
+	if ok {
+		t.Fatal("third request in the same instant should be limited")
+	}
+	if wait <= 0 || wait > time.Second {
+		t.Errorf("wait = %v, want within (0, 1s]", wait)
+	}
+	if ok, _ := l.allow("b"); !ok {
+		t.Error("another client should have its own bucket")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if ok, _ := l.allow("a"); !ok {
+		t.Error("request after refill should be allowed")
+	}
+}
+
+func TestRateLimiterSweep(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := newRateLimiter(5, nil, nil)
+	l.now = func() time.Time { return now }
+
+	l.allow("idle")
+	now = now.Add(10 * time.Minute)
+	l.allow("active")
+	l.sweep(rateLimitIdle)
+
+	if _, ok := l.buckets["idle"]; ok {
+		t.Error("idle bucket should be evicted")
+	}
+	if _, ok := l.buckets["active"]; !ok {
+		t.Error("active bucket should be kept")
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	srv, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18080, Bind: "127.0.0.1", RateLimit: 3}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	srv.ready.Store(true)
+	h := srv.routes()
+
+	propfind := func(remote string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("PROPFIND", "/", nil)
+		r.RemoteAddr = remote
+		r.Header.Set("Depth", "0")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+
+	for i := 0; i < 3; i++ {
+		if rec := propfind("192.0.2.1:1000"); rec.Code != http.StatusMultiStatus {
+			t.Fatalf("request %d status = %d, want %d", i+1, rec.Code, http.StatusMultiStatus)
+		}
+	}
+	rec := propfind("192.0.2.1:1001")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("request over the limit status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if rec.Header().Get("Retry-After") != "1" {
+		t.Errorf("Retry-After = %q, want %q", rec.Header().Get("Retry-After"), "1")
+	}
+	if rec := propfind("192.0.2.2:1000"); rec.Code != http.StatusMultiStatus {
+		t.Errorf("other client status = %d, want %d", rec.Code, http.StatusMultiStatus)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/health", nil)
+	r.RemoteAddr = "192.0.2.1:1000"
+	health := httptest.NewRecorder()
+	h.ServeHTTP(health, r)
+	if health.Code != http.StatusOK {
+		t.Errorf("GET /health from a limited client status = %d, want %d", health.Code, http.StatusOK)
+	}
+}
+
+func TestRateLimitInvalid(t *testing.T) {
+	if _, err := NewWithOptions(Options{Folder: t.TempDir(), RateLimit: -1}, nil); err == nil {
+		t.Error("NewWithOptions() should reject a negative rate limit")
+	}
+}
+
+func TestMethodRateLimits(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "file.txt"), []byte("data"), 0644)
+	srv, err := NewWithOptions(Options{Folder: dir, Port: 18080, Bind: "127.0.0.1", RateLimit: 10, MethodRateLimits: map[string]int{"PROPFIND": 2}}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	h := srv.routes()
+
+	request := func(method string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/file.txt", nil)
+		r.RemoteAddr = "192.0.2.1:1000"
+		r.Header.Set("Depth", "0")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := request("PROPFIND"); rec.Code != http.StatusMultiStatus {
+			t.Fatalf("PROPFIND %d status = %d, want %d", i+1, rec.Code, http.StatusMultiStatus)
+		}
+	}
+	if rec := request("PROPFIND"); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("PROPFIND over its limit status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	// GET has the looser overall limit, which the rejected PROPFIND did not use
+	for i := 0; i < 8; i++ {
+		if rec := request(http.MethodGet); rec.Code != http.StatusOK {
+			t.Fatalf("GET %d status = %d, want %d", i+1, rec.Code, http.StatusOK)
+		}
+	}
+	if rec := request(http.MethodGet); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("GET over the overall limit status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+}
+
+func TestParseMethodRates(t *testing.T) {
+	got, err := ParseMethodRates("PROPFIND=10/s, put=5")
+	if err != nil {
+		t.Fatalf("ParseMethodRates() error = %v", err)
+	}
+	if len(got) != 2 || got["PROPFIND"] != 10 || got["PUT"] != 5 {
+		t.Errorf("ParseMethodRates() = %v, want PROPFIND=10 PUT=5", got)
+	}
+	for _, s := range []string{"PROPFIND", "PROPFIND=0/s", "=5/s", "PUT=fast"} {
+		if _, err := ParseMethodRates(s); err == nil {
+			t.Errorf("ParseMethodRates(%q) should fail", s)
+		}
+	}
+}
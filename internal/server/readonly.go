@@ -0,0 +1,155 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// readOnlyAllow lists the methods accepted in read-only mode
+const readOnlyAllow = "GET, HEAD, OPTIONS, PROPFIND"
+
+// Answers to LOCK and UNLOCK in read-only mode
+const (
+	// ReadOnlyLocksForbid rejects LOCK and UNLOCK with 403
+	ReadOnlyLocksForbid = "forbid"
+	// ReadOnlyLocksGrant answers LOCK with a lock that is not recorded and
+	// UNLOCK with success, for clients that refuse to open files they cannot
+	// lock. Such locks block nobody.
+	ReadOnlyLocksGrant = "grant"
+)
+
+// readOnly rejects every method that could modify the served tree with 405,
+// and LOCK and UNLOCK with 403 unless grantLocks answers them with no-op
+// success. SEARCH is accepted when search is set. OPTIONS responses
+// advertise only the read methods, and drop the locking compliance class, so
+// clients do not offer write operations.
+func readOnly(next http.Handler, grantLocks, search bool) http.Handler {
+	allow := readOnlyAllow
+	if search {
+		allow += ", SEARCH"
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, "PROPFIND":
+			next.ServeHTTP(w, r)
+		case "SEARCH":
+			if !search {
+				w.Header().Set("Allow", allow)
+				http.Error(w, "Method Not Allowed: server is read-only", http.StatusMethodNotAllowed)
+				return
+			}
+			next.ServeHTTP(w, r)
+		case "LOCK", "UNLOCK":
+			switch {
+			case !grantLocks:
+				http.Error(w, "Forbidden: server is read-only", http.StatusForbidden)
+			case r.Method == "LOCK":
+				grantNoopLock(w, r)
+			default:
+				w.WriteHeader(http.StatusNoContent)
+			}
+		case http.MethodOptions:
+			ow := &readOnlyOptionsWriter{ResponseWriter: w, allow: allow}
+			next.ServeHTTP(ow, r)
+			ow.restrict()
+		default:
+			w.Header().Set("Allow", allow)
+			http.Error(w, "Method Not Allowed: server is read-only", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// readOnlyOptionsWriter replaces the method set advertised by the WebDAV
+// handler before the response header is sent
+type readOnlyOptionsWriter struct {
+	http.ResponseWriter
+	allow      string
+	restricted bool
+}
+
+// restrict rewrites the advertised methods once; the WebDAV handler answers
+// OPTIONS without writing, so it also runs after the handler returns
+func (w *readOnlyOptionsWriter) restrict() {
+	if w.restricted {
+		return
+	}
+	w.restricted = true
+	h := w.ResponseWriter.Header()
+	h.Set("Allow", w.allow)
+	if h.Get("DAV") != "" {
+		h.Set("DAV", "1")
+	}
+}
+
+func (w *readOnlyOptionsWriter) WriteHeader(code int) {
+	w.restrict()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *readOnlyOptionsWriter) Write(b []byte) (int, error) {
+	w.restrict()
+	return w.ResponseWriter.Write(b)
+}
+
+// lockTokenPattern finds a lock token in an If header
+var lockTokenPattern = regexp.MustCompile(`<(opaquelocktoken:[^>]+)>`)
+
+// grantNoopLock answers LOCK as if the lock had been granted, without
+// recording it. Refreshes keep the token from the If header.
+func grantNoopLock(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxLockBody))
+	if err != nil {
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	scope := "exclusive"
+	var token string
+	if len(bytes.TrimSpace(body)) == 0 {
+		m := lockTokenPattern.FindStringSubmatch(r.Header.Get("If"))
+		if m == nil {
+			http.Error(w, "Bad Request: lock refresh without a lock token", http.StatusBadRequest)
+			return
+		}
+		token = m[1]
+	} else {
+		var info lockInfo
+		if err := xml.Unmarshal(body, &info); err != nil {
+			http.Error(w, "Bad Request: invalid lockinfo", http.StatusBadRequest)
+			return
+		}
+		if info.Scope.Shared != nil {
+			scope = "shared"
+		}
+		b := make([]byte, 16)
+		rand.Read(b)
+		token = "opaquelocktoken:" + hex.EncodeToString(b)
+		w.Header().Set("Lock-Token", "<"+token+">")
+	}
+
+	depth := "infinity"
+	if r.Header.Get("Depth") == "0" {
+		depth = "0"
+	}
+	var href strings.Builder
+	xml.EscapeText(&href, []byte(r.URL.EscapedPath()))
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "%s<D:prop xmlns:D=\"DAV:\"><D:lockdiscovery><D:activelock>"+
+		"<D:locktype><D:write/></D:locktype><D:lockscope><D:%s/></D:lockscope>"+
+		"<D:depth>%s</D:depth><D:timeout>Second-3600</D:timeout>"+
+		"<D:locktoken><D:href>%s</D:href></D:locktoken>"+
+		"<D:lockroot><D:href>%s</D:href></D:lockroot>"+
+		"</D:activelock></D:lockdiscovery></D:prop>\n", xml.Header, scope, depth, token, href.String())
+}
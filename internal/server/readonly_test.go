@@ -0,0 +1,153 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newReadOnlyServer(t *testing.T) (http.Handler, string) {
+	t.Helper()
+	return newReadOnlyServerLocks(t, "")
+}
+
+func newReadOnlyServerLocks(t *testing.T, locks string) (http.Handler, string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "song.mp3"), []byte("music"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	srv, err := NewWithOptions(Options{Folder: tmpDir, Port: 18080, Bind: "127.0.0.1", ReadOnly: true, ReadOnlyLocks: locks}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	return srv.Handler(), tmpDir
+}
+
+func TestReadOnlyRejectsWrites(t *testing.T) {
+	h, tmpDir := newReadOnlyServer(t)
+
+	for _, method := range []string{http.MethodPut, http.MethodDelete, http.MethodPost, "MKCOL", "MOVE", "COPY", "PROPPATCH"} {
+		t.Run(method, func(t *testing.T) {
+			rec := doRequest(h, method, "/song.mp3", "data", map[string]string{
+				"Destination": "http://example.com/copy.mp3",
+			})
+			if rec.Code != http.StatusMethodNotAllowed {
+				t.Errorf("%s status = %d, want %d", method, rec.Code, http.StatusMethodNotAllowed)
+			}
+			if got := rec.Header().Get("Allow"); got != readOnlyAllow {
+				t.Errorf("%s Allow = %q, want %q", method, got, readOnlyAllow)
+			}
+		})
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "song.mp3"))
+	if err != nil || string(data) != "music" {
+		t.Errorf("file was modified: %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "copy.mp3")); !os.IsNotExist(err) {
+		t.Error("COPY should not create the destination")
+	}
+}
+
+func TestReadOnlyAllowsReads(t *testing.T) {
+	h, _ := newReadOnlyServer(t)
+
+	tests := []struct {
+		method string
+		target string
+		want   int
+	}{
+		{method: http.MethodGet, target: "/song.mp3", want: http.StatusOK},
+		{method: http.MethodHead, target: "/song.mp3", want: http.StatusOK},
+		{method: "PROPFIND", target: "/", want: http.StatusMultiStatus},
+		{method: http.MethodOptions, target: "/song.mp3", want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		rec := doRequest(h, tt.method, tt.target, "", map[string]string{"Depth": "1"})
+		if rec.Code != tt.want {
+			t.Errorf("%s %s status = %d, want %d", tt.method, tt.target, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestReadOnlyOptionsAdvertisesReads(t *testing.T) {
+	h, _ := newReadOnlyServer(t)
+
+	for _, target := range []string{"/", "/song.mp3", "/missing"} {
+		rec := doRequest(h, http.MethodOptions, target, "", nil)
+		if got := rec.Header().Get("Allow"); got != readOnlyAllow {
+			t.Errorf("OPTIONS %s Allow = %q, want %q", target, got, readOnlyAllow)
+		}
+		if got := rec.Header().Get("DAV"); got != "1" {
+			t.Errorf("OPTIONS %s DAV = %q, want %q", target, got, "1")
+		}
+	}
+}
+
+func TestReadOnlyLocks(t *testing.T) {
+	for _, mode := range []string{"", ReadOnlyLocksForbid} {
+		h, _ := newReadOnlyServerLocks(t, mode)
+		for _, method := range []string{"LOCK", "UNLOCK"} {
+			rec := doRequest(h, method, "/song.mp3", lockBody, map[string]string{"Lock-Token": "<opaquelocktoken:abc>"})
+			if rec.Code != http.StatusForbidden {
+				t.Errorf("mode %q: %s status = %d, want %d", mode, method, rec.Code, http.StatusForbidden)
+			}
+		}
+	}
+}
+
+func TestReadOnlyLocksGrant(t *testing.T) {
+	h, tmpDir := newReadOnlyServerLocks(t, ReadOnlyLocksGrant)
+
+	rec := doRequest(h, "LOCK", "/song.mp3", lockBody, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("LOCK status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	token := strings.Trim(rec.Header().Get("Lock-Token"), "<>")
+	if !strings.HasPrefix(token, "opaquelocktoken:") {
+		t.Fatalf("Lock-Token = %q, want an opaquelocktoken", token)
+	}
+	if !strings.Contains(rec.Body.String(), token) || !strings.Contains(rec.Body.String(), "<D:exclusive/>") {
+		t.Errorf("LOCK body = %s, want the lock discovery of the token", rec.Body.String())
+	}
+
+	// The lock is not recorded, so it blocks nobody
+	second := doRequest(h, "LOCK", "/song.mp3", lockBody, nil)
+	if second.Code != http.StatusOK || second.Header().Get("Lock-Token") == rec.Header().Get("Lock-Token") {
+		t.Errorf("second LOCK status = %d, want %d with a new token", second.Code, http.StatusOK)
+	}
+
+	refresh := doRequest(h, "LOCK", "/song.mp3", "", map[string]string{"If": "(<" + token + ">)"})
+	if refresh.Code != http.StatusOK || !strings.Contains(refresh.Body.String(), token) {
+		t.Errorf("refresh status = %d, body %s, want the same token", refresh.Code, refresh.Body.String())
+	}
+	if rec := doRequest(h, "LOCK", "/song.mp3", "", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("refresh without token status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	unlock := doRequest(h, "UNLOCK", "/song.mp3", "", map[string]string{"Lock-Token": "<" + token + ">"})
+	if unlock.Code != http.StatusNoContent {
+		t.Errorf("UNLOCK status = %d, want %d", unlock.Code, http.StatusNoContent)
+	}
+
+	if rec := doRequest(h, http.MethodPut, "/song.mp3", "data", nil); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("PUT status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tmpDir, "song.mp3")); string(data) != "music" {
+		t.Errorf("file was modified: %q", data)
+	}
+	if got := doRequest(h, http.MethodOptions, "/", "", nil).Header().Get("DAV"); got != "1" {
+		t.Errorf("OPTIONS DAV = %q, want %q", got, "1")
+	}
+}
+
+func TestReadOnlyLocksInvalid(t *testing.T) {
+	_, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18080, Bind: "127.0.0.1", ReadOnly: true, ReadOnlyLocks: "maybe"}, nil)
+	if err == nil {
+		t.Error("NewWithOptions() should reject an unknown read-only lock mode")
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/webdav"
+
+	"gowebdavd/internal/logger"
+)
+
+// readOnlyFallbackThreshold is how many writes in a row have to fail with
+// EROFS before the server switches to read-only mode
+const readOnlyFallbackThreshold = 3
+
+// readOnlyProbeInterval is how often a modifying request is let through in
+// read-only mode to find out whether the file system is writable again
+const readOnlyProbeInterval = 10 * time.Second
+
+// readOnlyFallback switches a served tree to read-only mode when its file
+// system keeps rejecting writes with EROFS, e.g. after the kernel remounted
+// it read-only on a disk error, so that clients get 405 instead of a string
+// of 500s. It switches back after a successful write.
+type readOnlyFallback struct {
+	mu        sync.Mutex
+	failures  int
+	degraded  bool
+	lastProbe time.Time
+	now       func() time.Time
+	logger    *logger.Logger
+}
+
+func newReadOnlyFallback(log *logger.Logger) *readOnlyFallback {
+	return &readOnlyFallback{now: time.Now, logger: log}
+}
+
+// record updates the state with the outcome of a write
+func (f *readOnlyFallback) record(err error) {
+	if err != nil && !errors.Is(err, syscall.EROFS) {
+		// Unrelated failures say nothing about the file system
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err == nil {
+		f.failures = 0
+		if f.degraded {
+			f.degraded = false
+			logf(f.logger, "Write succeeded, leaving read-only mode")
+		}
+		return
+	}
+	f.failures++
+	if !f.degraded && f.failures >= readOnlyFallbackThreshold {
+		f.degraded = true
+		f.lastProbe = f.now()
+		logf(f.logger, "Warning: file system is read-only (%v), switching to read-only mode", err)
+	}
+}
+
+// pass reports whether a modifying request is served normally: always
+// outside read-only mode, and once per probe interval within it
+func (f *readOnlyFallback) pass() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.degraded {
+		return true
+	}
+	if now := f.now(); now.Sub(f.lastProbe) >= readOnlyProbeInterval {
+		f.lastProbe = now
+		return true
+	}
+	return false
+}
+
+// middleware answers modifying requests as readOnly does while the file
+// system is read-only, accepting SEARCH when search is set
+func (f *readOnlyFallback) middleware(next http.Handler, search bool) http.Handler {
+	ro := readOnly(next, false, search)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, "PROPFIND", searchMethod:
+			next.ServeHTTP(w, r)
+		case http.MethodOptions:
+			f.mu.Lock()
+			degraded := f.degraded
+			f.mu.Unlock()
+			if degraded {
+				ro.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		default:
+			if f.pass() {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ro.ServeHTTP(w, r)
+		}
+	})
+}
+
+// readOnlyFallbackFS reports the outcome of every write to its fallback
+type readOnlyFallbackFS struct {
+	webdav.FileSystem
+	fallback *readOnlyFallback
+}
+
+func (fs readOnlyFallbackFS) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	err := fs.FileSystem.Mkdir(ctx, name, perm)
+	fs.fallback.record(err)
+	return err
+}
+
+func (fs readOnlyFallbackFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	f, err := fs.FileSystem.OpenFile(ctx, name, flag, perm)
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) != 0 {
+		fs.fallback.record(err)
+	}
+	return f, err
+}
+
+func (fs readOnlyFallbackFS) RemoveAll(ctx context.Context, name string) error {
+	err := fs.FileSystem.RemoveAll(ctx, name)
+	fs.fallback.record(err)
+	return err
+}
+
+func (fs readOnlyFallbackFS) Rename(ctx context.Context, oldName, newName string) error {
+	err := fs.FileSystem.Rename(ctx, oldName, newName)
+	fs.fallback.record(err)
+	return err
+}
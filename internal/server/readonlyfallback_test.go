@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+// remountFS fails every write with EROFS while readOnly is set, like a file
+// system the kernel remounted read-only
+type remountFS struct {
+	webdav.FileSystem
+	readOnly *atomic.Bool
+}
+
+func (fs remountFS) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	if fs.readOnly.Load() {
+		return &os.PathError{Op: "mkdir", Path: name, Err: syscall.EROFS}
+	}
+	return fs.FileSystem.Mkdir(ctx, name, perm)
+}
+
+func (fs remountFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	if fs.readOnly.Load() && flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE) != 0 {
+		return nil, &os.PathError{Op: "open", Path: name, Err: syscall.EROFS}
+	}
+	return fs.FileSystem.OpenFile(ctx, name, flag, perm)
+}
+
+func TestReadOnlyFallback(t *testing.T) {
+	var readOnly atomic.Bool
+	now := time.Unix(0, 0)
+	fallback := newReadOnlyFallback(nil)
+	fallback.now = func() time.Time { return now }
+	h := fallback.middleware(&webdav.Handler{
+		FileSystem: readOnlyFallbackFS{FileSystem: remountFS{FileSystem: webdav.Dir(t.TempDir()), readOnly: &readOnly}, fallback: fallback},
+		LockSystem: webdav.NewMemLS(),
+	}, false)
+
+	if rec := doRequest(h, http.MethodPut, "/file.txt", "data", nil); rec.Code != http.StatusCreated {
+		t.Fatalf("PUT status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+
+	readOnly.Store(true)
+	for i := 0; i < readOnlyFallbackThreshold; i++ {
+		if rec := doRequest(h, http.MethodPut, "/file.txt", "data", nil); rec.Code == http.StatusMethodNotAllowed || rec.Code < 400 {
+			t.Fatalf("PUT %d status = %d, want the file system error", i+1, rec.Code)
+		}
+	}
+
+	// Read-only mode
+	rec := doRequest(h, http.MethodPut, "/file.txt", "data", nil)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("PUT in read-only mode status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+	if got := rec.Header().Get("Allow"); got != readOnlyAllow {
+		t.Errorf("Allow = %q, want %q", got, readOnlyAllow)
+	}
+	if rec := doRequest(h, http.MethodGet, "/file.txt", "", nil); rec.Code != http.StatusOK {
+		t.Errorf("GET in read-only mode status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec := doRequest(h, http.MethodOptions, "/", "", nil); rec.Header().Get("Allow") != readOnlyAllow {
+		t.Errorf("OPTIONS in read-only mode Allow = %q, want %q", rec.Header().Get("Allow"), readOnlyAllow)
+	}
+
+	// A probe while the disk is still read-only keeps the mode
+	now = now.Add(readOnlyProbeInterval)
+	if rec := doRequest(h, http.MethodPut, "/file.txt", "data", nil); rec.Code == http.StatusMethodNotAllowed {
+		t.Errorf("probe PUT status = %d, want it let through", rec.Code)
+	}
+	if rec := doRequest(h, http.MethodPut, "/file.txt", "data", nil); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("PUT after a failed probe status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+
+	// Recovery
+	readOnly.Store(false)
+	now = now.Add(readOnlyProbeInterval)
+	if rec := doRequest(h, http.MethodPut, "/file.txt", "data", nil); rec.Code != http.StatusCreated {
+		t.Fatalf("probe PUT status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if rec := doRequest(h, "MKCOL", "/dir", "", nil); rec.Code != http.StatusCreated {
+		t.Errorf("MKCOL after recovery status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+}
+
+func TestReadOnlyFallbackIgnoresOtherErrors(t *testing.T) {
+	fallback := newReadOnlyFallback(nil)
+	for i := 0; i < 2*readOnlyFallbackThreshold; i++ {
+		fallback.record(os.ErrPermission)
+	}
+	if !fallback.pass() {
+		t.Error("errors other than EROFS should not switch to read-only mode")
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+// lockStore keeps one lock system per served root, so that locks taken by
+// clients survive a reload of the handler chain
+type lockStore struct {
+	systems map[string]webdav.LockSystem
+	// mode selects the lock system, see newLockSystem
+	mode LockMode
+	// backend keeps the locks instead of memory when not nil
+	backend lockBackend
+	// track records the locks granted, so that releaseAll can unlock them
+	track bool
+}
+
+// newLockStore returns a store creating lock systems for mode, kept in the
+// shared lock file at lockFile when it is not empty
+func newLockStore(mode LockMode, lockFile string, track bool) *lockStore {
+	l := &lockStore{systems: make(map[string]webdav.LockSystem), mode: mode, track: track}
+	if lockFile != "" {
+		l.backend = newFileLockBackend(lockFile)
+	}
+	return l
+}
+
+// get returns the lock system of root, creating it on first use
+func (l *lockStore) get(root string) webdav.LockSystem {
+	ls, ok := l.systems[root]
+	if !ok {
+		if l.backend != nil {
+			ls = newSharedLS(l.backend, root)
+		} else {
+			ls = newLockSystem(l.mode)
+		}
+		if l.track {
+			ls = newHeldLocksLS(ls)
+		}
+		l.systems[root] = ls
+	}
+	return ls
+}
+
+// releaseAll unlocks the locks still held in every lock system and returns
+// their roots, prefixed with the served root for mounts. It releases nothing
+// unless the store tracks locks.
+func (l *lockStore) releaseAll(now time.Time) []string {
+	roots := make([]string, 0, len(l.systems))
+	for root := range l.systems {
+		roots = append(roots, root)
+	}
+	sort.Strings(roots)
+
+	var released []string
+	for _, root := range roots {
+		ls, ok := l.systems[root].(*heldLocksLS)
+		if !ok {
+			continue
+		}
+		for _, h := range ls.releaseAll(now) {
+			released = append(released, filepath.Join(root, filepath.FromSlash(h.details.Root)))
+		}
+	}
+	return released
+}
+
+// Reload rebuilds the handler chain from opts and swaps it in without
+// interrupting running requests or open connections. Settings bound to the
+// listener or the served tree cannot change at runtime; they keep their
+// current values and are reported in the returned warnings. On error the
+// current configuration stays in place.
+func (s *WebDAV) Reload(opts Options) ([]string, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	cur := s.opts
+	var warnings []string
+	keep := func(setting string, changed bool) {
+		if changed {
+			warnings = append(warnings, fmt.Sprintf("%s changed, restart required", setting))
+		}
+	}
+	keep("port", opts.Port != cur.Port)
+	keep("bind address", opts.Bind != cur.Bind)
+	keep("served directory", opts.Folder != cur.Folder || opts.ZipFile != cur.ZipFile || !reflect.DeepEqual(opts.Mounts, cur.Mounts))
+	keep("single-instance lock", opts.SingleInstanceLock != cur.SingleInstanceLock)
+	keep("TCP_NODELAY", opts.DisableTCPNoDelay != cur.DisableTCPNoDelay)
+	keep("dual-stack mode", opts.DualStack != cur.DualStack)
+	keep("connection limit per IP", opts.MaxConnsPerIP != cur.MaxConnsPerIP)
+	keep("metrics endpoint", opts.Metrics != cur.Metrics)
+	keep("stats counters", opts.Stats != cur.Stats)
+	keep("shutdown file", opts.ShutdownFile != cur.ShutdownFile)
+	keep("shutdown timeout", opts.ShutdownTimeout != cur.ShutdownTimeout)
+	keep("lock release on shutdown", opts.ShutdownReleaseLocks != cur.ShutdownReleaseLocks)
+	keep("lock mode", opts.LockMode != cur.LockMode)
+	keep("lock file", opts.LockFile != cur.LockFile)
+	keep("connection timeouts", opts.ReadTimeout != cur.ReadTimeout || opts.WriteTimeout != cur.WriteTimeout || opts.IdleTimeout != cur.IdleTimeout)
+	keep("HTTPS", usesTLS(opts) != usesTLS(cur))
+	opts.Port, opts.Bind = cur.Port, cur.Bind
+	opts.Folder, opts.ZipFile, opts.Mounts = cur.Folder, cur.ZipFile, cur.Mounts
+	opts.SingleInstanceLock, opts.DisableTCPNoDelay = cur.SingleInstanceLock, cur.DisableTCPNoDelay
+	opts.MaxConnsPerIP, opts.Metrics, opts.ShutdownFile = cur.MaxConnsPerIP, cur.Metrics, cur.ShutdownFile
+	opts.ShutdownTimeout, opts.DualStack, opts.Stats = cur.ShutdownTimeout, cur.DualStack, cur.Stats
+	opts.ShutdownReleaseLocks, opts.LockMode, opts.LockFile = cur.ShutdownReleaseLocks, cur.LockMode, cur.LockFile
+	opts.ReadTimeout, opts.WriteTimeout, opts.IdleTimeout = cur.ReadTimeout, cur.WriteTimeout, cur.IdleTimeout
+	if usesTLS(opts) != usesTLS(cur) {
+		opts.TLSCert, opts.TLSKey, opts.TLSSelfSigned = cur.TLSCert, cur.TLSKey, cur.TLSSelfSigned
+	}
+
+	c, err := s.build(opts)
+	if err != nil {
+		return warnings, err
+	}
+	s.swap(c)
+	s.opts = opts
+	return warnings, nil
+}
+
+// reloadFromSource reloads the options returned by Options.Reload and reports
+// the outcome
+func (s *WebDAV) reloadFromSource() {
+	opts, err := s.reload()
+	var warnings []string
+	if err == nil {
+		warnings, err = s.Reload(opts)
+	}
+	for _, w := range warnings {
+		logf(s.logger, "Reload warning: %s", w)
+	}
+	if err != nil {
+		logf(s.logger, "Reload failed, keeping current configuration: %v", err)
+		return
+	}
+	logf(s.logger, "Configuration reloaded")
+}
+
+// usesTLS reports whether opts serve HTTPS
+func usesTLS(opts Options) bool {
+	return opts.TLSSelfSigned || opts.TLSCert != "" || opts.TLSKey != ""
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"bytes"
+	"crypto/tls"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReloadSwapsAuthAndAllowlist(t *testing.T) {
+	opts := Options{Folder: t.TempDir(), Port: 18080, Bind: "127.0.0.1"}
+	srv, err := NewWithOptions(opts, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	entry := srv.server.Handler
+
+	if rec := doRequest(entry, "PROPFIND", "/", "", nil); rec.Code != http.StatusMultiStatus {
+		t.Fatalf("PROPFIND before reload status = %d, want %d", rec.Code, http.StatusMultiStatus)
+	}
+
+	opts.Credentials = Credentials{"alice": "secret"}
+	if _, err := srv.Reload(opts); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if rec := doRequest(entry, "PROPFIND", "/", "", nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("PROPFIND after enabling auth status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	opts.AllowedNets = []string{"10.0.0.0/8"}
+	if _, err := srv.Reload(opts); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if rec := doRequest(entry, http.MethodGet, "/health", "", nil); rec.Code != http.StatusForbidden {
+		t.Errorf("GET /health from outside the allowlist status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestReloadInvalidKeepsConfig(t *testing.T) {
+	opts := Options{Folder: t.TempDir(), Port: 18080, Bind: "127.0.0.1", ReadOnly: true}
+	srv, err := NewWithOptions(opts, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+
+	opts.ReadOnly = false
+	opts.AllowedNets = []string{"not-a-cidr"}
+	if _, err := srv.Reload(opts); err == nil {
+		t.Fatal("Reload() should reject an invalid allowlist")
+	}
+
+	rec := doRequest(srv.server.Handler, http.MethodPut, "/file.txt", "data", nil)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("PUT after failed reload status = %d, want read-only %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestReloadRestartRequired(t *testing.T) {
+	dir := t.TempDir()
+	opts := Options{Folder: dir, Port: 18080, Bind: "127.0.0.1"}
+	srv, err := NewWithOptions(opts, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+
+	opts.Port = 18081
+	opts.Folder = t.TempDir()
+	opts.TLSSelfSigned = true
+	opts.IdleTimeout = time.Second
+	warnings, err := srv.Reload(opts)
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if len(warnings) != 4 {
+		t.Errorf("Reload() warnings = %v, want port, directory, timeouts and HTTPS", warnings)
+	}
+	for _, w := range warnings {
+		if !strings.Contains(w, "restart required") {
+			t.Errorf("warning %q does not mention a restart", w)
+		}
+	}
+
+	if srv.Addr() != "127.0.0.1:18080" {
+		t.Errorf("Addr() = %s, want the original address", srv.Addr())
+	}
+	if srv.opts.Folder != dir || srv.opts.TLSSelfSigned || srv.opts.IdleTimeout != 0 {
+		t.Errorf("Reload() applied settings that need a restart: %+v", srv.opts)
+	}
+}
+
+func TestReloadKeepsLocks(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("original"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	opts := Options{Folder: dir, Port: 18080, Bind: "127.0.0.1"}
+	srv, err := NewWithOptions(opts, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	token := lockFile(t, srv.Handler(), "/file.txt")
+
+	opts.Gzip = true
+	if _, err := srv.Reload(opts); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	h := srv.Handler()
+	if rec := doRequest(h, http.MethodPut, "/file.txt", "updated", nil); rec.Code != http.StatusLocked {
+		t.Errorf("PUT without token after reload status = %d, want %d", rec.Code, http.StatusLocked)
+	}
+	rec := doRequest(h, http.MethodPut, "/file.txt", "updated", map[string]string{"If": "(<" + token + ">)"})
+	if rec.Code != http.StatusNoContent && rec.Code != http.StatusCreated {
+		t.Errorf("PUT with token after reload status = %d, want success", rec.Code)
+	}
+}
+
+func TestReloadKeepsSelfSignedCertificate(t *testing.T) {
+	opts := Options{Folder: t.TempDir(), Port: 18443, Bind: "127.0.0.1", TLSSelfSigned: true}
+	srv, err := NewWithOptions(opts, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	before, _ := srv.certificate(nil)
+
+	out, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	stdout := os.Stdout
+	os.Stdout = out
+	opts.Gzip = true
+	_, err = srv.Reload(opts)
+	os.Stdout = stdout
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	after, _ := srv.certificate(nil)
+	if !bytes.Equal(after.Certificate[0], before.Certificate[0]) {
+		t.Error("Reload() replaced the self-signed certificate")
+	}
+	if data, _ := os.ReadFile(out.Name()); bytes.Contains(data, []byte("fingerprint")) {
+		t.Errorf("Reload() printed %q, want no new fingerprint", data)
+	}
+}
+
+func TestReloadTLSCertificate(t *testing.T) {
+	certDir := t.TempDir()
+	certFile, keyFile := writeTestKeyPair(t, certDir)
+	opts := Options{Folder: t.TempDir(), Port: 18443, Bind: "127.0.0.1", TLSCert: certFile, TLSKey: keyFile}
+	srv, err := NewWithOptions(opts, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	url, done := startTestServer(t, srv)
+	url = strings.Replace(url, "http://", "https://", 1)
+
+	peerCert := func() []byte {
+		t.Helper()
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			DisableKeepAlives: true,
+		}}
+		resp, err := client.Get(url + "/health")
+		if err != nil {
+			t.Fatalf("GET over TLS error = %v", err)
+		}
+		resp.Body.Close()
+		return resp.TLS.PeerCertificates[0].Raw
+	}
+
+	before := peerCert()
+	// Rotate the key pair in place, as a certificate renewal would
+	writeTestKeyPair(t, certDir)
+	if _, err := srv.Reload(opts); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if bytes.Equal(peerCert(), before) {
+		t.Error("server still presents the old certificate after reload")
+	}
+
+	srv.shutdown()
+	waitServe(t, done)
+}
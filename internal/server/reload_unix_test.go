@@ -0,0 +1,47 @@
+//go:build !windows
+
+package server
+
+import (
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestSIGHUPReload(t *testing.T) {
+	opts := Options{Folder: t.TempDir(), Port: 18080, Bind: "127.0.0.1"}
+	opts.Reload = func() (Options, error) {
+		next := opts
+		next.HealthBody = "reloaded"
+		return next, nil
+	}
+	srv, err := NewWithOptions(opts, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	_, done := startTestServer(t, srv)
+
+	// Keep SIGHUP from terminating the test before serve subscribes to it
+	ignored := make(chan os.Signal, 1)
+	signal.Notify(ignored, syscall.SIGHUP)
+	defer signal.Stop(ignored)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+		time.Sleep(10 * time.Millisecond)
+		rec := doRequest(srv.server.Handler, http.MethodGet, "/health", "", nil)
+		if rec.Body.String() == "reloaded" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("/health body = %q after SIGHUP, want %q", rec.Body.String(), "reloaded")
+		}
+	}
+
+	srv.shutdown()
+	waitServe(t, done)
+}
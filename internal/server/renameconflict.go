@@ -0,0 +1,87 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"golang.org/x/net/webdav"
+)
+
+// maxConflictSuffix bounds the search for a free name
+const maxConflictSuffix = 10000
+
+// renameOnConflict stores a PUT to an existing file under a new name such as
+// "file (1).txt" instead of overwriting it, and returns the new path in the
+// Location header of the 201 response. The new name is reserved by creating
+// it exclusively, so concurrent uploads never pick the same one. A PUT with
+// If-Match targets a known version and overwrites as usual.
+func renameOnConflict(next http.Handler, fs webdav.FileSystem) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.Header.Get("If-Match") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		fi, err := fs.Stat(r.Context(), r.URL.Path)
+		if err != nil || fi.IsDir() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		name, err := reserveFreeName(r.Context(), fs, r.URL.Path)
+		if err != nil {
+			recordError(r, err)
+			http.Error(w, "Conflict: no free name for the upload", http.StatusConflict)
+			return
+		}
+
+		r2 := r.Clone(r.Context())
+		r2.URL.Path, r2.URL.RawPath = name, ""
+		rw := &conflictWriter{ResponseWriter: w, status: http.StatusOK}
+		rw.Header().Set("Location", (&url.URL{Path: name}).EscapedPath())
+		next.ServeHTTP(rw, r2)
+		if rw.status >= 300 {
+			// Do not leave the reserved empty file behind
+			fs.RemoveAll(context.Background(), name)
+		}
+	})
+}
+
+// reserveFreeName creates the first of "name (1).ext", "name (2).ext", ...
+// next to name that does not exist yet and returns its path
+func reserveFreeName(ctx context.Context, fs webdav.FileSystem, name string) (string, error) {
+	dir, base := path.Split(name)
+	ext := path.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	for i := 1; i <= maxConflictSuffix; i++ {
+		candidate := fmt.Sprintf("%s%s (%d)%s", dir, stem, i, ext)
+		f, err := fs.OpenFile(ctx, candidate, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+		if err == nil {
+			f.Close()
+			return candidate, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("all %d alternative names of %s are taken", maxConflictSuffix, name)
+}
+
+// conflictWriter records the status of the renamed upload
+type conflictWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *conflictWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
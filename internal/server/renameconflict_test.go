@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenameOnConflict(t *testing.T) {
+	tmpDir := t.TempDir()
+	srv, err := NewWithOptions(Options{Folder: tmpDir, Port: 18080, Bind: "127.0.0.1", RenameOnConflict: true}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	h := srv.Handler()
+
+	rec := doRequest(h, http.MethodPut, "/report.txt", "first", nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("first PUT status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if loc := rec.Header().Get("Location"); loc != "" {
+		t.Errorf("first PUT Location = %q, want none", loc)
+	}
+
+	for i, want := range []string{"/report (1).txt", "/report (2).txt"} {
+		rec = doRequest(h, http.MethodPut, "/report.txt", "again", nil)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("PUT %d status = %d, want %d", i+2, rec.Code, http.StatusCreated)
+		}
+		if loc, _ := url.PathUnescape(rec.Header().Get("Location")); loc != want {
+			t.Errorf("PUT %d Location = %q, want %q", i+2, loc, want)
+		}
+		data, err := os.ReadFile(filepath.Join(tmpDir, want[1:]))
+		if err != nil || string(data) != "again" {
+			t.Errorf("%s = %q, %v, want the uploaded content", want, data, err)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(tmpDir, "report.txt")); string(data) != "first" {
+		t.Errorf("report.txt = %q, want it left untouched", data)
+	}
+
+	rec = doRequest(h, http.MethodPut, "/report.txt", "replaced", map[string]string{"If-Match": "*"})
+	if rec.Code != http.StatusCreated {
+		t.Errorf("PUT with If-Match status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tmpDir, "report.txt")); string(data) != "replaced" {
+		t.Errorf("report.txt = %q, want If-Match to overwrite", data)
+	}
+}
@@ -0,0 +1,58 @@
+//go:build !windows
+
+package server
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSdNotify(t *testing.T) {
+	// Socket paths are limited to about 100 bytes, keep it short
+	dir, err := os.MkdirTemp("", "sd")
+	if err != nil {
+		t.Fatalf("MkdirTemp() error = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "notify")
+	sock, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: name, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("unixgram sockets not available: %v", err)
+	}
+	defer sock.Close()
+	t.Setenv("NOTIFY_SOCKET", name)
+
+	// receive returns the next notification
+	receive := func() string {
+		t.Helper()
+		buf := make([]byte, 256)
+		sock.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, err := sock.Read(buf)
+		if err != nil {
+			t.Fatalf("no notification received: %v", err)
+		}
+		return string(buf[:n])
+	}
+
+	srv := New(t.TempDir(), 0, "127.0.0.1", nil)
+	_, done := startTestServer(t, srv)
+	if got := receive(); got != "READY=1" {
+		t.Errorf("notification = %q, want READY=1", got)
+	}
+
+	srv.shutdown()
+	if got := receive(); got != "STOPPING=1" {
+		t.Errorf("notification = %q, want STOPPING=1", got)
+	}
+	waitServe(t, done)
+}
+
+func TestSdNotifyWithoutSocket(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	if err := sdNotify("READY=1"); err != nil {
+		t.Errorf("sdNotify() without NOTIFY_SOCKET error = %v", err)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build windows
+
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+// sdNotify does nothing, systemd is not available on Windows
+func sdNotify(state string) error {
+	return nil
+}
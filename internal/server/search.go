@@ -0,0 +1,557 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+// maxSearchBody bounds the SEARCH bodies read
+const maxSearchBody = 1 << 20
+
+// maxSearchResults bounds the matches of a SEARCH, whatever limit it asks for
+const maxSearchResults = 1000
+
+// searchQuery is a parsed DAV:basicsearch query
+type searchQuery struct {
+	// props is the PROPFIND body selecting the properties of each match,
+	// nil for all properties
+	props []byte
+	scope string
+	// depth is 0, 1 or -1 for infinity
+	depth int
+	where searchExpr
+	limit int
+}
+
+// depthHeader returns the scope depth as a Depth header would carry it
+func (q *searchQuery) depthHeader() string {
+	switch q.depth {
+	case 0:
+		return "0"
+	case 1:
+		return "1"
+	}
+	return "infinity"
+}
+
+// searchExpr is a condition of the DAV:where element on a resource
+type searchExpr interface {
+	match(fi os.FileInfo) bool
+}
+
+type (
+	andExpr []searchExpr
+	orExpr  []searchExpr
+	notExpr struct{ searchExpr }
+	// isCollectionExpr matches collections
+	isCollectionExpr struct{}
+	// likeExpr matches display names against a pattern
+	likeExpr struct{ re *regexp.Regexp }
+	// compareExpr compares a property with a literal, cmp returning -1, 0
+	// or +1 like strings.Compare, and false when the property is undefined
+	compareExpr struct {
+		op  string
+		cmp func(fi os.FileInfo) (int, bool)
+	}
+)
+
+func (e andExpr) match(fi os.FileInfo) bool {
+	for _, sub := range e {
+		if !sub.match(fi) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e orExpr) match(fi os.FileInfo) bool {
+	for _, sub := range e {
+		if sub.match(fi) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e notExpr) match(fi os.FileInfo) bool        { return !e.searchExpr.match(fi) }
+func (isCollectionExpr) match(fi os.FileInfo) bool { return fi.IsDir() }
+func (e likeExpr) match(fi os.FileInfo) bool       { return e.re.MatchString(fi.Name()) }
+
+func (e compareExpr) match(fi os.FileInfo) bool {
+	c, ok := e.cmp(fi)
+	if !ok {
+		return false
+	}
+	switch e.op {
+	case "eq":
+		return c == 0
+	case "lt":
+		return c < 0
+	case "lte":
+		return c <= 0
+	case "gt":
+		return c > 0
+	}
+	return c >= 0
+}
+
+// xmlNode is an element of a SEARCH body
+type xmlNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Nodes   []xmlNode  `xml:",any"`
+	Text    string     `xml:",chardata"`
+}
+
+// child returns the first DAV: child element named local
+func (n *xmlNode) child(local string) *xmlNode {
+	for i := range n.Nodes {
+		if n.Nodes[i].XMLName.Space == "DAV:" && n.Nodes[i].XMLName.Local == local {
+			return &n.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// attr returns the value of the attribute named local, empty when unset
+func (n *xmlNode) attr(local string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// parseSearch parses a DAV:searchrequest holding a DAV:basicsearch. Hrefs
+// of the scope are resolved against base.
+func parseSearch(body []byte, base *url.URL) (*searchQuery, error) {
+	var req xmlNode
+	if err := xml.Unmarshal(body, &req); err != nil {
+		return nil, errors.New("invalid XML: " + err.Error())
+	}
+	if req.XMLName.Space != "DAV:" || req.XMLName.Local != "searchrequest" {
+		return nil, errors.New("expected a DAV:searchrequest")
+	}
+	bs := req.child("basicsearch")
+	if bs == nil {
+		return nil, errors.New("only DAV:basicsearch is supported")
+	}
+
+	q := &searchQuery{scope: base.Path, depth: -1, limit: maxSearchResults}
+	if sel := bs.child("select"); sel != nil && sel.child("allprop") == nil {
+		prop := sel.child("prop")
+		if prop == nil {
+			return nil, errors.New("DAV:select needs DAV:prop or DAV:allprop")
+		}
+		q.props = propfindBody(prop)
+	}
+
+	from := bs.child("from")
+	if from == nil || from.child("scope") == nil {
+		return nil, errors.New("missing DAV:from scope")
+	}
+	scope := from.child("scope")
+	if href := scope.child("href"); href != nil {
+		target, err := base.Parse(strings.TrimSpace(href.Text))
+		if err != nil || (target.Host != "" && target.Host != base.Host) {
+			return nil, errors.New("invalid scope href")
+		}
+		q.scope = target.Path
+	}
+	if d := scope.child("depth"); d != nil {
+		switch strings.TrimSpace(d.Text) {
+		case "0":
+			q.depth = 0
+		case "1":
+			q.depth = 1
+		case "infinity":
+		default:
+			return nil, errors.New("invalid scope depth")
+		}
+	}
+
+	if where := bs.child("where"); where != nil {
+		if len(where.Nodes) != 1 {
+			return nil, errors.New("DAV:where needs one condition")
+		}
+		expr, err := parseSearchExpr(&where.Nodes[0])
+		if err != nil {
+			return nil, err
+		}
+		q.where = expr
+	}
+
+	if limit := bs.child("limit"); limit != nil {
+		n, err := strconv.Atoi(strings.TrimSpace(limitText(limit)))
+		if err != nil || n <= 0 {
+			return nil, errors.New("invalid DAV:nresults")
+		}
+		q.limit = min(n, maxSearchResults)
+	}
+	return q, nil
+}
+
+// limitText returns the DAV:nresults of a DAV:limit element
+func limitText(limit *xmlNode) string {
+	if n := limit.child("nresults"); n != nil {
+		return n.Text
+	}
+	return ""
+}
+
+// propfindBody returns a PROPFIND body asking for the properties in prop
+func propfindBody(prop *xmlNode) []byte {
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="utf-8"?><D:propfind xmlns:D="DAV:"><D:prop>`)
+	for _, n := range prop.Nodes {
+		b.WriteString("<")
+		xml.EscapeText(&b, []byte(n.XMLName.Local))
+		b.WriteString(` xmlns="`)
+		xml.EscapeText(&b, []byte(n.XMLName.Space))
+		b.WriteString(`"/>`)
+	}
+	b.WriteString("</D:prop></D:propfind>")
+	return b.Bytes()
+}
+
+// parseSearchExpr parses a condition of the DAV:where element
+func parseSearchExpr(n *xmlNode) (searchExpr, error) {
+	if n.XMLName.Space != "DAV:" {
+		return nil, errors.New("unsupported condition " + n.XMLName.Local)
+	}
+	switch op := n.XMLName.Local; op {
+	case "and", "or", "not":
+		var subs []searchExpr
+		for i := range n.Nodes {
+			sub, err := parseSearchExpr(&n.Nodes[i])
+			if err != nil {
+				return nil, err
+			}
+			subs = append(subs, sub)
+		}
+		switch {
+		case op == "and":
+			return andExpr(subs), nil
+		case op == "or":
+			return orExpr(subs), nil
+		case len(subs) != 1:
+			return nil, errors.New("DAV:not needs one condition")
+		}
+		return notExpr{subs[0]}, nil
+	case "is-collection":
+		return isCollectionExpr{}, nil
+	case "like":
+		prop, literal, err := operands(n)
+		if err != nil {
+			return nil, err
+		}
+		if prop != "displayname" {
+			return nil, errors.New("DAV:like only supports DAV:displayname")
+		}
+		return likeExpr{re: likePattern(literal, n.attr("caseless") != "no")}, nil
+	case "eq", "lt", "lte", "gt", "gte":
+		prop, literal, err := operands(n)
+		if err != nil {
+			return nil, err
+		}
+		cmp, err := comparison(prop, literal, n.attr("caseless") != "no")
+		if err != nil {
+			return nil, err
+		}
+		return compareExpr{op: op, cmp: cmp}, nil
+	}
+	return nil, errors.New("unsupported condition " + n.XMLName.Local)
+}
+
+// operands returns the DAV: property and the literal an operator compares
+func operands(n *xmlNode) (prop, literal string, err error) {
+	p, l := n.child("prop"), n.child("literal")
+	if p == nil || l == nil || len(p.Nodes) != 1 || p.Nodes[0].XMLName.Space != "DAV:" {
+		return "", "", errors.New("DAV:" + n.XMLName.Local + " needs a DAV: property and a literal")
+	}
+	return p.Nodes[0].XMLName.Local, l.Text, nil
+}
+
+// comparison returns the comparison of the property prop with literal
+func comparison(prop, literal string, caseless bool) (func(os.FileInfo) (int, bool), error) {
+	switch prop {
+	case "displayname":
+		if caseless {
+			literal = strings.ToLower(literal)
+		}
+		return func(fi os.FileInfo) (int, bool) {
+			name := fi.Name()
+			if caseless {
+				name = strings.ToLower(name)
+			}
+			return strings.Compare(name, literal), true
+		}, nil
+	case "getcontentlength":
+		n, err := strconv.ParseInt(strings.TrimSpace(literal), 10, 64)
+		if err != nil {
+			return nil, errors.New("invalid DAV:getcontentlength literal")
+		}
+		return func(fi os.FileInfo) (int, bool) {
+			if fi.IsDir() {
+				return 0, false
+			}
+			return cmpInt(fi.Size(), n), true
+		}, nil
+	case "getlastmodified":
+		t, err := parseSearchTime(literal)
+		if err != nil {
+			return nil, errors.New("invalid DAV:getlastmodified literal")
+		}
+		return func(fi os.FileInfo) (int, bool) {
+			return fi.ModTime().Truncate(time.Second).Compare(t), true
+		}, nil
+	}
+	return nil, errors.New("unsupported property DAV:" + prop)
+}
+
+func cmpInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// parseSearchTime parses an HTTP date as PROPFIND reports it, or RFC 3339
+func parseSearchTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := http.ParseTime(s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// likePattern compiles a DAV:like pattern, in which % matches any string, _
+// any character and \ escapes the next character
+func likePattern(pattern string, caseless bool) *regexp.Regexp {
+	var b strings.Builder
+	if caseless {
+		b.WriteString("(?i)")
+	}
+	b.WriteString("^")
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			b.WriteString(".*")
+		case r == '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// searchMatches walks the scope of q in fs and returns the request paths of
+// the resources matching it in path order, and whether there were more
+// than q.limit. Members of a collection are only listed as far as fs lists
+// them, so hidden files stay hidden.
+func searchMatches(ctx context.Context, fs webdav.FileSystem, q *searchQuery) ([]string, bool, error) {
+	fi, err := fs.Stat(ctx, q.scope)
+	if err != nil {
+		return nil, false, err
+	}
+	var matches []string
+	var walk func(name string, fi os.FileInfo, depth int) error
+	walk = func(name string, fi os.FileInfo, depth int) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if q.where == nil || q.where.match(fi) {
+			if len(matches) == q.limit {
+				return errSearchLimit
+			}
+			matches = append(matches, name)
+		}
+		if !fi.IsDir() || depth == 0 {
+			return nil
+		}
+
+		f, err := fs.OpenFile(ctx, name, os.O_RDONLY, 0)
+		if err != nil {
+			return nil
+		}
+		entries, _ := f.Readdir(-1)
+		f.Close()
+		slices.SortFunc(entries, func(a, b os.FileInfo) int { return strings.Compare(a.Name(), b.Name()) })
+		for _, e := range entries {
+			if err := walk(path.Join(name, e.Name()), e, depth-1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	err = walk(q.scope, fi, q.depth)
+	if errors.Is(err, errSearchLimit) {
+		return matches, true, nil
+	}
+	return matches, false, err
+}
+
+// errSearchLimit stops a walk that found more matches than asked for
+var errSearchLimit = errors.New("search limit reached")
+
+// search answers SEARCH requests with a DAV:basicsearch query (RFC 5323)
+// for the resources in its scope matching the query. The properties of
+// each match are taken from a PROPFIND with Depth 0 through next, so every
+// other option applies to them as to a single PROPFIND and matches next
+// does not serve are left out. A non-empty allowed refuses scope depths it
+// does not hold, as -propfind-allowed-depths does for PROPFIND. OPTIONS
+// responses advertise the grammar.
+func search(next http.Handler, fs webdav.FileSystem, allowed map[string]bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "SEARCH":
+		case http.MethodOptions:
+			w.Header().Set("DASL", "<DAV:basicsearch>")
+			aw := &searchAllowWriter{ResponseWriter: w}
+			next.ServeHTTP(aw, r)
+			aw.advertise()
+			return
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxSearchBody))
+		if err != nil {
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+		q, err := parseSearch(body, r.URL)
+		if err != nil {
+			http.Error(w, "Bad Request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		collection := path.Clean(r.URL.Path)
+		if q.scope = path.Clean(q.scope); q.scope != collection && !strings.HasPrefix(q.scope, strings.TrimSuffix(collection, "/")+"/") {
+			http.Error(w, "Bad Request: scope outside the searched collection", http.StatusBadRequest)
+			return
+		}
+		if depth := q.depthHeader(); len(allowed) > 0 && !allowed[depth] {
+			refuseDepth(w, depth)
+			return
+		}
+
+		// The scope itself must be accessible, as a PROPFIND would find it
+		rec := newBufferedResponse()
+		next.ServeHTTP(rec, propfindRequest(r, q.scope, nil))
+		if rec.status != http.StatusMultiStatus {
+			http.Error(w, http.StatusText(rec.status), rec.status)
+			return
+		}
+		matches, truncated, err := searchMatches(r.Context(), fs, q)
+		if err != nil {
+			if os.IsNotExist(err) {
+				http.Error(w, "Not Found", http.StatusNotFound)
+				return
+			}
+			recordError(r, err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		var b bytes.Buffer
+		b.WriteString(xml.Header)
+		b.WriteString(`<D:multistatus xmlns:D="DAV:">` + "\n")
+		for _, name := range matches {
+			rec := newBufferedResponse()
+			next.ServeHTTP(rec, propfindRequest(r, name, q.props))
+			if rec.status != http.StatusMultiStatus {
+				continue
+			}
+			for _, resp := range responseElements(rec.body.Bytes()) {
+				b.Write(resp)
+				b.WriteByte('\n')
+			}
+		}
+		if truncated {
+			writeHrefStatus(&b, r.URL.EscapedPath(), http.StatusInsufficientStorage)
+		}
+		b.WriteString("</D:multistatus>\n")
+
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.WriteHeader(http.StatusMultiStatus)
+		w.Write(b.Bytes())
+	})
+}
+
+// propfindRequest returns a PROPFIND with Depth 0 for name derived from r
+func propfindRequest(r *http.Request, name string, body []byte) *http.Request {
+	sub := r.Clone(r.Context())
+	sub.Method = "PROPFIND"
+	sub.URL.Path, sub.URL.RawPath = name, ""
+	sub.RequestURI = sub.URL.RequestURI()
+	sub.Header.Set("Depth", "0")
+	sub.Body = io.NopCloser(bytes.NewReader(body))
+	sub.ContentLength = int64(len(body))
+	return sub
+}
+
+// searchAllowWriter adds SEARCH to the Allow header of OPTIONS responses
+type searchAllowWriter struct {
+	http.ResponseWriter
+	done bool
+}
+
+// advertise rewrites the Allow header once; the WebDAV handler answers
+// OPTIONS without writing, so it also runs after the handler returns
+func (w *searchAllowWriter) advertise() {
+	if w.done {
+		return
+	}
+	w.done = true
+	h := w.ResponseWriter.Header()
+	if allow := h.Get("Allow"); allow != "" {
+		h.Set("Allow", fmt.Sprintf("%s, SEARCH", allow))
+	}
+}
+
+func (w *searchAllowWriter) WriteHeader(code int) {
+	w.advertise()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *searchAllowWriter) Write(b []byte) (int, error) {
+	w.advertise()
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer
+func (w *searchAllowWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,228 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+// searchBody returns a basicsearch over scope with the condition where
+func searchBody(scope, depth, where, limit string) string {
+	return `<?xml version="1.0"?>
+<D:searchrequest xmlns:D="DAV:"><D:basicsearch>
+<D:select><D:prop><D:getcontentlength/></D:prop></D:select>
+<D:from><D:scope><D:href>` + scope + `</D:href><D:depth>` + depth + `</D:depth></D:scope></D:from>
+<D:where>` + where + `</D:where>` + limit + `
+</D:basicsearch></D:searchrequest>`
+}
+
+func newSearchTree(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]int{
+		"report.pdf":              100,
+		"notes.txt":               10,
+		"docs/Manual.PDF":         5000,
+		"docs/old/draft.pdf":      50,
+		"docs/old/draft.txt":      50,
+		"private/secret.pdf":      10,
+		"private/.gowebdavd.json": 0,
+	}
+	for name, size := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "private", dirConfigName), []byte(`{"hidden": true}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(dir, "docs", "old", "draft.pdf"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// hrefs returns the hrefs of the responses in a multistatus body
+func hrefs(body string) []string {
+	var out []string
+	for _, part := range strings.Split(body, "<D:href>")[1:] {
+		out = append(out, part[:strings.Index(part, "</D:href>")])
+	}
+	return out
+}
+
+func TestSearch(t *testing.T) {
+	h := davHandler(webdav.Dir(newSearchTree(t)), "", webdav.NewMemLS(), Options{Search: true, DirConfig: true}, nil)
+	like := `<D:like><D:prop><D:displayname/></D:prop><D:literal>%.pdf</D:literal></D:like>`
+
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"like", searchBody("/", "infinity", like, ""), []string{"/docs/Manual.PDF", "/docs/old/draft.pdf", "/report.pdf"}},
+		{"depth 1", searchBody("/", "1", like, ""), []string{"/report.pdf"}},
+		{"scope", searchBody("/docs/old/", "infinity", like, ""), []string{"/docs/old/draft.pdf"}},
+		{"size", searchBody("/", "infinity", `<D:and>`+like+`<D:gt><D:prop><D:getcontentlength/></D:prop><D:literal>99</D:literal></D:gt></D:and>`, ""),
+			[]string{"/docs/Manual.PDF", "/report.pdf"}},
+		{"date", searchBody("/", "infinity", `<D:lt><D:prop><D:getlastmodified/></D:prop><D:literal>Sat, 01 Jan 2022 00:00:00 GMT</D:literal></D:lt>`, ""),
+			[]string{"/docs/old/draft.pdf"}},
+		{"case", searchBody("/", "infinity", `<D:like caseless="no"><D:prop><D:displayname/></D:prop><D:literal>%.pdf</D:literal></D:like>`, ""),
+			[]string{"/docs/old/draft.pdf", "/report.pdf"}},
+		{"collections", searchBody("/docs/", "infinity", `<D:is-collection/>`, ""), []string{"/docs/", "/docs/old/"}},
+		{"limit", searchBody("/", "infinity", like, `<D:limit><D:nresults>1</D:nresults></D:limit>`), []string{"/docs/Manual.PDF", "/"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(h, "SEARCH", "/", tt.body, nil)
+			if rec.Code != http.StatusMultiStatus {
+				t.Fatalf("SEARCH status = %d, want %d: %s", rec.Code, http.StatusMultiStatus, rec.Body.String())
+			}
+			if got := hrefs(rec.Body.String()); strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("SEARCH matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchProperties(t *testing.T) {
+	h := davHandler(webdav.Dir(newSearchTree(t)), "", webdav.NewMemLS(), Options{Search: true}, nil)
+	body := searchBody("/", "1", `<D:eq><D:prop><D:displayname/></D:prop><D:literal>report.pdf</D:literal></D:eq>`, "")
+
+	rec := doRequest(h, "SEARCH", "/", body, nil)
+	if !strings.Contains(rec.Body.String(), "<D:getcontentlength>100</D:getcontentlength>") {
+		t.Errorf("SEARCH body = %s, want the selected property", rec.Body.String())
+	}
+	if strings.Contains(rec.Body.String(), "getlastmodified") {
+		t.Errorf("SEARCH body = %s, want only the selected property", rec.Body.String())
+	}
+}
+
+func TestSearchRejected(t *testing.T) {
+	h := davHandler(webdav.Dir(newSearchTree(t)), "", webdav.NewMemLS(), Options{Search: true, DirConfig: true}, nil)
+	like := `<D:like><D:prop><D:displayname/></D:prop><D:literal>%</D:literal></D:like>`
+
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		want   int
+	}{
+		{"invalid XML", "/", "<D:searchrequest", http.StatusBadRequest},
+		{"unknown grammar", "/", `<D:searchrequest xmlns:D="DAV:"><X:q xmlns:X="urn:x"/></D:searchrequest>`, http.StatusBadRequest},
+		{"unknown property", "/", searchBody("/", "1", `<D:eq><D:prop><D:owner/></D:prop><D:literal>x</D:literal></D:eq>`, ""), http.StatusBadRequest},
+		{"scope outside", "/docs/", searchBody("/", "1", like, ""), http.StatusBadRequest},
+		{"scope escaping", "/docs/", searchBody("/docs/../", "1", like, ""), http.StatusBadRequest},
+		{"missing scope", "/", searchBody("/missing/", "1", like, ""), http.StatusNotFound},
+		{"hidden scope", "/", searchBody("/private/", "1", like, ""), http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := doRequest(h, "SEARCH", tt.target, tt.body, nil); rec.Code != tt.want {
+				t.Errorf("SEARCH status = %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+
+	// Hidden subtrees and configuration files are never matched
+	rec := doRequest(h, "SEARCH", "/", searchBody("/", "infinity", like, ""), nil)
+	if body := rec.Body.String(); strings.Contains(body, "private") || strings.Contains(body, dirConfigName) {
+		t.Errorf("SEARCH body = %s, want hidden paths left out", body)
+	}
+}
+
+func TestSearchAllowedDepths(t *testing.T) {
+	h := davHandler(webdav.Dir(newSearchTree(t)), "", webdav.NewMemLS(), Options{Search: true, PropfindAllowedDepths: []string{"0", "1"}}, nil)
+	like := `<D:like><D:prop><D:displayname/></D:prop><D:literal>%</D:literal></D:like>`
+
+	rec := doRequest(h, "SEARCH", "/", searchBody("/", "infinity", like, ""), nil)
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "<D:propfind-finite-depth/>") {
+		t.Errorf("SEARCH depth infinity = %d %s, want 403 with propfind-finite-depth", rec.Code, rec.Body.String())
+	}
+	if rec := doRequest(h, "SEARCH", "/", searchBody("/", "1", like, ""), nil); rec.Code != http.StatusMultiStatus {
+		t.Errorf("SEARCH depth 1 status = %d, want %d", rec.Code, http.StatusMultiStatus)
+	}
+}
+
+func TestSearchOptionsAndReadOnly(t *testing.T) {
+	dir := newSearchTree(t)
+	srv, err := NewWithOptions(Options{Folder: dir, Bind: "127.0.0.1", Search: true, ReadOnly: true}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+
+	rec := doRequest(srv.Handler(), "SEARCH", "/", searchBody("/", "1", `<D:is-collection/>`, ""), nil)
+	if rec.Code != http.StatusMultiStatus {
+		t.Errorf("SEARCH on a read-only server status = %d, want %d", rec.Code, http.StatusMultiStatus)
+	}
+	rec = doRequest(srv.Handler(), http.MethodOptions, "/", "", nil)
+	if rec.Header().Get("DASL") == "" || rec.Header().Get("Allow") != readOnlyAllow+", SEARCH" {
+		t.Errorf("read-only OPTIONS DASL = %q, Allow = %q, want SEARCH advertised", rec.Header().Get("DASL"), rec.Header().Get("Allow"))
+	}
+	if rec := doRequest(srv.Handler(), http.MethodPut, "/new.txt", "data", nil); !strings.HasSuffix(rec.Header().Get("Allow"), ", SEARCH") {
+		t.Errorf("read-only PUT Allow = %q, want SEARCH", rec.Header().Get("Allow"))
+	}
+	srv, err = NewWithOptions(Options{Folder: dir, Bind: "127.0.0.1", ReadOnly: true}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	rec = doRequest(srv.Handler(), "SEARCH", "/", searchBody("/", "1", `<D:is-collection/>`, ""), nil)
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != readOnlyAllow {
+		t.Errorf("SEARCH on a read-only server without the option = %d, Allow %q, want %d", rec.Code, rec.Header().Get("Allow"), http.StatusMethodNotAllowed)
+	}
+
+	h := davHandler(webdav.Dir(dir), "", webdav.NewMemLS(), Options{Search: true}, nil)
+	rec = doRequest(h, http.MethodOptions, "/", "", nil)
+	if rec.Header().Get("DASL") != "<DAV:basicsearch>" {
+		t.Errorf("DASL = %q, want %q", rec.Header().Get("DASL"), "<DAV:basicsearch>")
+	}
+	if !strings.HasSuffix(rec.Header().Get("Allow"), ", SEARCH") {
+		t.Errorf("Allow = %q, want SEARCH", rec.Header().Get("Allow"))
+	}
+
+	h = davHandler(webdav.Dir(dir), "", webdav.NewMemLS(), Options{}, nil)
+	if rec := doRequest(h, "SEARCH", "/", searchBody("/", "1", `<D:is-collection/>`, ""), nil); rec.Code == http.StatusMultiStatus {
+		t.Error("SEARCH should not be answered without the option")
+	}
+}
+
+func TestSearchDisabledMethods(t *testing.T) {
+	dir := newSearchTree(t)
+	newServer := func(methods ...string) http.Handler {
+		t.Helper()
+		disabled, err := ParseDisabledMethods(methods)
+		if err != nil {
+			t.Fatalf("ParseDisabledMethods(%v) error = %v", methods, err)
+		}
+		srv, err := NewWithOptions(Options{Folder: dir, Bind: "127.0.0.1", Search: true, DisabledMethods: disabled}, nil)
+		if err != nil {
+			t.Fatalf("NewWithOptions() error = %v", err)
+		}
+		return srv.Handler()
+	}
+
+	h := newServer("DELETE")
+	if rec := doRequest(h, http.MethodDelete, "/notes.txt", "", nil); !strings.HasSuffix(rec.Header().Get("Allow"), ", SEARCH") {
+		t.Errorf("DELETE Allow = %q, want SEARCH", rec.Header().Get("Allow"))
+	}
+
+	h = newServer("search")
+	rec := doRequest(h, "SEARCH", "/", searchBody("/", "1", `<D:is-collection/>`, ""), nil)
+	if rec.Code != http.StatusMethodNotAllowed || strings.Contains(rec.Header().Get("Allow"), "SEARCH") {
+		t.Errorf("disabled SEARCH = %d, Allow %q, want %d without SEARCH", rec.Code, rec.Header().Get("Allow"), http.StatusMethodNotAllowed)
+	}
+	rec = doRequest(h, http.MethodOptions, "/", "", nil)
+	if rec.Header().Get("DASL") != "" || strings.Contains(rec.Header().Get("Allow"), "SEARCH") {
+		t.Errorf("OPTIONS DASL = %q, Allow = %q, want SEARCH not advertised", rec.Header().Get("DASL"), rec.Header().Get("Allow"))
+	}
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSecureHeaders(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "page.html"), []byte("<script>alert(1)</script>"), 0644)
+	srv, err := NewWithOptions(Options{Folder: dir, Port: 18080, Bind: "127.0.0.1", SecureHeaders: true}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	h := srv.Handler()
+
+	rec := doRequest(h, http.MethodGet, "/page.html", "", nil)
+	want := map[string]string{
+		"X-Content-Type-Options":  "nosniff",
+		"X-Frame-Options":         "DENY",
+		"Content-Security-Policy": secureCSP,
+	}
+	for name, value := range want {
+		if got := rec.Header().Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+	if got := rec.Header().Get("Strict-Transport-Security"); got != "" {
+		t.Errorf("Strict-Transport-Security = %q over plain HTTP, want none", got)
+	}
+
+	// Error responses carry the headers too
+	if rec := doRequest(h, http.MethodGet, "/missing.html", "", nil); rec.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Error("404 response lacks X-Content-Type-Options")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/page.html", nil)
+	req.TLS = &tls.ConnectionState{}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if got := rec.Header().Get("Strict-Transport-Security"); got != hstsMaxAge {
+		t.Errorf("Strict-Transport-Security = %q over HTTPS, want %q", got, hstsMaxAge)
+	}
+}
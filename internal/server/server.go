@@ -5,47 +5,1039 @@
 package server
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
 	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"golang.org/x/net/webdav"
 	"gowebdavd/internal/logger"
 )
 
+// DefaultShutdownTimeout bounds how long shutdown waits for in-flight
+// requests when Options.ShutdownTimeout is 0
+const DefaultShutdownTimeout = 30 * time.Second
+
+// DefaultIdleTimeout closes keep-alive connections idle for longer when
+// Options.IdleTimeout is 0
+const DefaultIdleTimeout = 2 * time.Minute
+
+// readHeaderTimeout bounds the reading of request headers, so slowloris
+// clients trickling headers cannot hold connections. Bodies are not bound by
+// it, unlike by Options.ReadTimeout.
+const readHeaderTimeout = 10 * time.Second
+
+// Options configures a WebDAV server
+type Options struct {
+	Folder string
+	Port   int
+	Bind   string
+	// ZipFile serves the contents of a zip archive read-only instead of Folder
+	ZipFile string
+	// Mounts maps collection names to directories served under /name/ instead of Folder
+	Mounts map[string]string
+	// SingleInstanceLock refuses to start when another instance serves Folder or a mount
+	SingleInstanceLock bool
+	// ResponseBufferSize is the copy buffer size for file downloads, 0 keeps the default
+	ResponseBufferSize int
+	// DirListing serves an HTML listing for GET on collections
+	DirListing bool
+	// FancyIndex serves clients accepting text/html a sortable HTML listing
+	// for GET on collections
+	FancyIndex bool
+	// MIMETypes maps lower-case file extensions with their dot to the
+	// Content-Type of GET responses, replacing the detected type
+	MIMETypes map[string]string
+	// Index is served for GET on collections holding a file of this name,
+	// e.g. index.html, instead of a listing
+	Index string
+	// NoSymlinks rejects paths that symbolic links lead outside the served
+	// directory with 403, and leaves them out of listings
+	NoSymlinks bool
+	// CaseInsensitive resolves request paths to existing entries ignoring case
+	CaseInsensitive bool
+	// ReadOnly rejects every method that could modify the served tree
+	ReadOnly bool
+	// ETag selects how ETags of files are derived, ETagModTime (the default
+	// when empty) or ETagStrong
+	ETag string
+	// DisabledMethods are rejected with 405 and left out of Allow headers,
+	// as returned by ParseDisabledMethods
+	DisabledMethods []string
+	// ReadOnlyLocks selects the answer to LOCK and UNLOCK in read-only mode,
+	// ReadOnlyLocksForbid (the default when empty) or ReadOnlyLocksGrant
+	ReadOnlyLocks string
+	// RenameOnConflict stores a PUT to an existing file under a new name
+	// such as "file (1).txt" instead of overwriting it
+	RenameOnConflict bool
+	// ReadOnlyFallback switches to read-only mode while writes keep failing
+	// because the file system is mounted read-only
+	ReadOnlyFallback bool
+	// DeleteMultiStatus reports members of a collection that DELETE could not remove with 207 Multi-Status
+	DeleteMultiStatus bool
+	// CrossDeviceMove completes MOVE requests between filesystems mounted
+	// below the directory by copying, then removing the source
+	CrossDeviceMove bool
+	// DryRun logs every modification of the served tree instead of applying
+	// it
+	DryRun bool
+	// PartialPut writes the body of a PUT with Content-Range into the file at
+	// the range offset, so that interrupted uploads can resume
+	PartialPut bool
+	// PreserveModTimes gives files copied by COPY the modification times of
+	// their sources
+	PreserveModTimes bool
+	// DirConfig applies .gowebdavd.json files to the subtree of their directory
+	DirConfig bool
+	// DeadProps keeps properties set with PROPPATCH in a .gowebdavd-props.json
+	// file per directory, so they survive restarts
+	DeadProps bool
+	// ContentLengthRequired rejects PUT requests without a Content-Length
+	ContentLengthRequired bool
+	// LockOwnerRequired rejects LOCK requests without an owner element
+	LockOwnerRequired bool
+	// LockTimeout caps the timeout of every lock, so that locks of crashed
+	// clients expire; 0 allows any timeout, including infinite ones
+	LockTimeout time.Duration
+	// LockMode selects the lock system, LockModeMemory when empty
+	LockMode LockMode
+	// LockFile keeps the locks in this file instead of memory, so that every
+	// server using it honours them; servers must serve the same paths
+	LockFile string
+	// VerboseErrors includes the underlying error in 5xx response bodies
+	VerboseErrors bool
+	// MaxBodySize rejects request bodies larger than this many bytes with 413, 0 disables the limit
+	MaxBodySize int64
+	// Quota caps the total size of the files of each served directory in
+	// bytes; writes beyond it get 507. 0 disables the cap.
+	Quota int64
+	// ReportQuota answers PROPFIND for the quota-available-bytes and
+	// quota-used-bytes properties of collections, from Quota when set and
+	// from the disk otherwise
+	ReportQuota bool
+	// MaxMoveCopySize rejects COPY and MOVE of sources larger than this many bytes, 0 disables the limit
+	MaxMoveCopySize int64
+	// MinFreeInodes rejects PUT, MKCOL and COPY with 507 when the served
+	// filesystem has fewer free inodes, 0 disables the check. Not supported on Windows.
+	MinFreeInodes int64
+	// ProtectedNames lists file names that can never be written, moved or deleted
+	ProtectedNames []string
+	// StripProperties lists live properties removed from PROPFIND responses
+	StripProperties []string
+	// PropfindAllowedDepths lists the Depth values PROPFIND accepts, "0", "1"
+	// or "infinity"; others are rejected with 403. Empty allows all.
+	PropfindAllowedDepths []string
+	// BatchPropfind answers a PROPFIND listing hrefs in its body for exactly
+	// those resources
+	BatchPropfind bool
+	// Search answers SEARCH requests with a DAV:basicsearch query for the
+	// matching resources below the requested collection
+	Search bool
+	// Checksums answers GET and HEAD requests with a checksum query parameter
+	// or a Want-Digest header with the digest of the file instead of its body
+	Checksums bool
+	// Credentials enable authentication when not empty, HTTP Basic unless DigestAuth is set
+	Credentials Credentials
+	// DigestAuth authenticates with HTTP Digest instead of Basic
+	DigestAuth bool
+	// NonceTTL is how long a Digest nonce stays valid, 0 uses DefaultNonceTTL
+	NonceTTL time.Duration
+	// Gzip compresses responses of at least GzipMinSize bytes for clients accepting it
+	Gzip bool
+	// GzipMinSize is the compression threshold in bytes, 0 uses DefaultGzipMinSize
+	GzipMinSize int
+	// AllowedNets lists the CIDR ranges clients may connect from, empty allows all
+	AllowedNets []string
+	// TrustedProxies lists the CIDR ranges of proxies whose X-Forwarded-For is honoured
+	TrustedProxies []string
+	// BandwidthTotal caps the combined response body bytes per second of all
+	// requests, as MaxRate does for responses; the lower cap applies. 0
+	// disables the cap.
+	BandwidthTotal int64
+	// MaxRate caps the request and response body bytes per second of all
+	// connections together, in each direction; 0 disables the cap
+	MaxRate int64
+	// MaxRatePerConn caps the body bytes per second of each connection, in
+	// each direction; 0 disables the cap
+	MaxRatePerConn int64
+	// RateLimit allows each client IP this many requests per second, 0 disables limiting
+	RateLimit int
+	// MethodRateLimits allows each client IP this many requests per second
+	// of a method, on top of RateLimit
+	MethodRateLimits map[string]int
+	// Metrics serves request and connection counters on /metrics in the
+	// Prometheus text format, behind the credentials of the served files
+	// unless Bind is a loopback address
+	Metrics bool
+	// Stats adds request counters, goroutines and uptime to the /stats
+	// endpoint, which then requires credentials unless Bind is a loopback
+	// address
+	Stats bool
+	// MaxConnsPerIP closes new connections from a client IP that already has
+	// this many open, 0 disables the cap
+	MaxConnsPerIP int
+	// DisableTCPNoDelay re-enables Nagle's algorithm on accepted connections
+	DisableTCPNoDelay bool
+	// DualStack sets IPV6_V6ONLY on IPv6 listeners: DualStackOn accepts IPv4
+	// connections as well, DualStackOff does not. Empty keeps the OS default.
+	DualStack string
+	// TLSCert and TLSKey serve HTTPS with the given PEM key pair when set
+	TLSCert string
+	TLSKey  string
+	// TLSSelfSigned serves HTTPS with a certificate generated at startup
+	TLSSelfSigned bool
+	// SecureHeaders adds nosniff, frame and content security policy headers
+	// to every response, and Strict-Transport-Security over HTTPS
+	SecureHeaders bool
+	// ErrorPagesDir holds custom HTML pages named by status code, e.g.
+	// 404.html, sent to clients accepting text/html instead of error bodies
+	ErrorPagesDir string
+	// AdvertiseFeatures lists the enabled optional features in an
+	// X-GoWebDAVd-Features header on OPTIONS responses
+	AdvertiseFeatures bool
+	// HealthBody and HealthStatus customize the /health response, empty or 0 keep the defaults
+	HealthBody   string
+	HealthStatus int
+	// ReadTimeout and WriteTimeout bound the reading of a whole request and
+	// the writing of its response, 0 disables the limit. Both cut off large
+	// uploads and downloads that run longer.
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	// IdleTimeout closes keep-alive connections idle for longer. 0 uses
+	// DefaultIdleTimeout, a negative value keeps them open without limit.
+	IdleTimeout time.Duration
+	// RequestTimeout answers 503 to requests not answered within it and
+	// bounds the reading of their bodies, 0 disables the limit
+	RequestTimeout time.Duration
+	// UploadTimeout replaces RequestTimeout for PUT, 0 exempts PUT
+	UploadTimeout time.Duration
+	// ShutdownTimeout bounds how long a graceful shutdown waits for in-flight
+	// requests before closing their connections. 0 uses DefaultShutdownTimeout,
+	// a negative value waits without limit.
+	ShutdownTimeout time.Duration
+	// ShutdownFile shuts the server down gracefully once a file at this path
+	// appears, empty disables the watch
+	ShutdownFile string
+	// ShutdownReleaseLocks unlocks and logs the locks clients still hold
+	// once a shutdown has finished the requests in flight
+	ShutdownReleaseLocks bool
+	// WebhookURL receives a JSON event after every successful request with
+	// one of WebhookMethods, empty disables the webhook
+	WebhookURL string
+	// WebhookMethods are the methods reported to WebhookURL, as returned by
+	// ParseWebhookMethods. Empty uses DefaultWebhookMethods.
+	WebhookMethods []string
+	// Reload returns fresh options when the server receives SIGHUP, nil leaves SIGHUP unhandled
+	Reload func() (Options, error)
+}
+
 // WebDAV wraps the WebDAV HTTP server
 type WebDAV struct {
-	handler http.Handler
-	addr    string
-	logger  *logger.Logger
+	// mu guards the fields a reload replaces
+	mu        sync.RWMutex
+	handler   http.Handler
+	endpoints map[string]http.Handler
+	entry     http.Handler
+	limiter   *rateLimiter
+	cert      *tls.Certificate
+
+	// reloadMu serializes reloads and guards opts
+	reloadMu sync.Mutex
+	opts     Options
+	reload   func() (Options, error)
+	locks    *lockStore
+	zip      *zipFS
+	metrics  *metrics
+	stats    *requestStats
+	// ready is set while the server accepts connections and cleared once
+	// a shutdown starts, so /health sends load balancers elsewhere
+	ready atomic.Bool
+
+	server          *http.Server
+	addr            string
+	roots           []string
+	logger          *logger.Logger
+	singleInstance  bool
+	tcpNoDelay      bool
+	dualStack       string
+	shutdownTimeout time.Duration
+	shutdownFile    string
+	shutdownPoll    time.Duration
+}
+
+// chain is everything NewWithOptions and Reload build from Options
+type chain struct {
+	handler   http.Handler
+	endpoints map[string]http.Handler
+	allowed   []*net.IPNet
+	proxies   []*net.IPNet
+	limiter   *rateLimiter
+	cert      *tls.Certificate
+	roots     []string
 }
 
 // New creates a new WebDAV server instance
 func New(folder string, port int, bind string, log *logger.Logger) *WebDAV {
-	davHandler := &webdav.Handler{
-		FileSystem: webdav.Dir(folder),
-		LockSystem: webdav.NewMemLS(),
+	// Without optional features the configuration cannot fail
+	s, _ := NewWithOptions(Options{Folder: folder, Port: port, Bind: bind}, log)
+	return s
+}
+
+// NewWithOptions creates a new WebDAV server instance with optional features
+func NewWithOptions(opts Options, log *logger.Logger) (*WebDAV, error) {
+	s := &WebDAV{
+		opts:            opts,
+		reload:          opts.Reload,
+		locks:           newLockStore(opts.LockMode, opts.LockFile, opts.ShutdownReleaseLocks),
+		addr:            opts.Bind + ":" + strconv.Itoa(opts.Port),
+		logger:          log,
+		singleInstance:  opts.SingleInstanceLock,
+		tcpNoDelay:      !opts.DisableTCPNoDelay,
+		dualStack:       opts.DualStack,
+		shutdownTimeout: opts.ShutdownTimeout,
+		shutdownFile:    opts.ShutdownFile,
+		shutdownPoll:    shutdownFilePoll,
+	}
+	if err := validateDualStack(opts.DualStack); err != nil {
+		return nil, err
+	}
+	if err := opts.LockMode.validate(); err != nil {
+		return nil, err
+	}
+	if opts.LockFile != "" && opts.LockMode != "" && opts.LockMode != LockModeMemory {
+		return nil, fmt.Errorf("a lock file cannot be combined with lock mode %s", opts.LockMode)
+	}
+	if s.shutdownTimeout == 0 {
+		s.shutdownTimeout = DefaultShutdownTimeout
+	}
+	if opts.Metrics {
+		s.metrics = newMetrics()
+	}
+	if opts.Stats {
+		s.stats = newRequestStats()
+	}
+	c, err := s.build(opts)
+	if err != nil {
+		return nil, err
+	}
+	s.roots = c.roots
+	s.swap(c)
+
+	// Connections always get their rate state, so a reload can enable
+	// -max-rate-per-conn
+	s.server = &http.Server{
+		Handler:           http.HandlerFunc(s.serveEntry),
+		ConnContext:       connContext,
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       opts.ReadTimeout,
+		WriteTimeout:      opts.WriteTimeout,
+		IdleTimeout:       opts.IdleTimeout,
+	}
+	if opts.ReadTimeout > 0 {
+		s.server.ReadHeaderTimeout = min(readHeaderTimeout, opts.ReadTimeout)
+	}
+	if opts.IdleTimeout == 0 {
+		s.server.IdleTimeout = DefaultIdleTimeout
+	}
+	var connHooks []func(net.Conn, http.ConnState)
+	if opts.MaxConnsPerIP > 0 {
+		connHooks = append(connHooks, newConnLimiter(opts.MaxConnsPerIP).connState)
+	}
+	if s.metrics != nil {
+		connHooks = append(connHooks, s.metrics.connState)
+	}
+	if len(connHooks) > 0 {
+		s.server.ConnState = func(conn net.Conn, state http.ConnState) {
+			for _, hook := range connHooks {
+				hook(conn, state)
+			}
+		}
+	}
+	if c.cert != nil {
+		s.server.TLSConfig = &tls.Config{
+			GetCertificate: s.certificate,
+			MinVersion:     tls.VersionTLS12,
+		}
+	}
+	return s, nil
+}
+
+// build validates opts and assembles the handler chain
+func (s *WebDAV) build(opts Options) (*chain, error) {
+	log := s.logger
+	if opts.ResponseBufferSize < 0 {
+		return nil, fmt.Errorf("response buffer size must not be negative: %d", opts.ResponseBufferSize)
+	}
+	if opts.GzipMinSize < 0 {
+		return nil, fmt.Errorf("gzip threshold must not be negative: %d", opts.GzipMinSize)
+	}
+	gzipMinSize := opts.GzipMinSize
+	if gzipMinSize == 0 {
+		gzipMinSize = DefaultGzipMinSize
+	}
+	if opts.RateLimit < 0 {
+		return nil, fmt.Errorf("rate limit must not be negative: %d", opts.RateLimit)
+	}
+	for method, n := range opts.MethodRateLimits {
+		if n <= 0 {
+			return nil, fmt.Errorf("rate limit of %s must be positive: %d", method, n)
+		}
+	}
+	if opts.MaxConnsPerIP < 0 {
+		return nil, fmt.Errorf("connection limit per IP must not be negative: %d", opts.MaxConnsPerIP)
+	}
+	if opts.BandwidthTotal < 0 {
+		return nil, fmt.Errorf("bandwidth limit must not be negative: %d", opts.BandwidthTotal)
+	}
+	if opts.MaxRate < 0 {
+		return nil, fmt.Errorf("transfer rate limit must not be negative: %d", opts.MaxRate)
+	}
+	if opts.MaxRatePerConn < 0 {
+		return nil, fmt.Errorf("transfer rate limit per connection must not be negative: %d", opts.MaxRatePerConn)
+	}
+	if opts.ReadTimeout < 0 || opts.WriteTimeout < 0 {
+		return nil, fmt.Errorf("read and write timeouts must not be negative: %s, %s", opts.ReadTimeout, opts.WriteTimeout)
+	}
+	if opts.RequestTimeout < 0 || opts.UploadTimeout < 0 {
+		return nil, fmt.Errorf("request timeouts must not be negative: %s, %s", opts.RequestTimeout, opts.UploadTimeout)
+	}
+	if opts.Quota < 0 {
+		return nil, fmt.Errorf("quota must not be negative: %d", opts.Quota)
+	}
+	if opts.MaxBodySize < 0 {
+		return nil, fmt.Errorf("body size limit must not be negative: %d", opts.MaxBodySize)
+	}
+	if opts.LockTimeout < 0 {
+		return nil, fmt.Errorf("lock timeout must not be negative: %s", opts.LockTimeout)
+	}
+	if opts.LockTimeout > 0 && opts.LockTimeout < time.Second {
+		// Lock timeouts are given in whole seconds
+		return nil, fmt.Errorf("lock timeout must be at least 1s: %s", opts.LockTimeout)
+	}
+	if opts.Index != "" {
+		if err := validateIndex(opts.Index); err != nil {
+			return nil, err
+		}
+	}
+	if opts.MaxMoveCopySize < 0 {
+		return nil, fmt.Errorf("move/copy size limit must not be negative: %d", opts.MaxMoveCopySize)
+	}
+	switch opts.ETag {
+	case "", ETagModTime, ETagStrong:
+	default:
+		return nil, fmt.Errorf("invalid ETag mode %q (want %s or %s)", opts.ETag, ETagModTime, ETagStrong)
+	}
+	switch opts.ReadOnlyLocks {
+	case "", ReadOnlyLocksForbid, ReadOnlyLocksGrant:
+	default:
+		return nil, fmt.Errorf("invalid read-only lock mode %q (want %s or %s)", opts.ReadOnlyLocks, ReadOnlyLocksForbid, ReadOnlyLocksGrant)
+	}
+	if opts.MinFreeInodes < 0 {
+		return nil, fmt.Errorf("free inode reserve must not be negative: %d", opts.MinFreeInodes)
+	}
+	if opts.MinFreeInodes > 0 && !freeInodesSupported {
+		return nil, fmt.Errorf("a free inode reserve is not supported on this platform")
+	}
+
+	if err := validateStripProps(opts.StripProperties); err != nil {
+		return nil, err
+	}
+	allowedDepths, err := parsePropfindDepths(opts.PropfindAllowedDepths)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DigestAuth && len(opts.Credentials) == 0 {
+		return nil, fmt.Errorf("digest authentication requires credentials")
+	}
+	nonceTTL := opts.NonceTTL
+	if nonceTTL == 0 {
+		nonceTTL = DefaultNonceTTL
+	}
+
+	var tlsConfig *tls.Config
+	switch {
+	case opts.TLSSelfSigned && (opts.TLSCert != "" || opts.TLSKey != ""):
+		return nil, fmt.Errorf("a self-signed certificate cannot be combined with a TLS certificate or key")
+	case opts.TLSSelfSigned:
+		if cert, _ := s.certificate(nil); cert != nil && s.opts.TLSSelfSigned {
+			// A reload keeps the generated certificate clients may have pinned
+			tlsConfig = &tls.Config{Certificates: []tls.Certificate{*cert}, MinVersion: tls.VersionTLS12}
+			break
+		}
+		var err error
+		if tlsConfig, err = selfSignedTLSConfig(opts.Bind); err != nil {
+			return nil, err
+		}
+	case opts.TLSCert != "" || opts.TLSKey != "":
+		var err error
+		if tlsConfig, err = loadTLSConfig(opts.TLSCert, opts.TLSKey); err != nil {
+			return nil, err
+		}
+	}
+
+	healthBody := opts.HealthBody
+	if healthBody == "" {
+		healthBody = DefaultHealthBody
+	}
+	healthStatus := opts.HealthStatus
+	if healthStatus == 0 {
+		healthStatus = DefaultHealthStatus
+	}
+	if healthStatus < 100 || healthStatus > 599 {
+		return nil, fmt.Errorf("invalid health status code: %d", healthStatus)
+	}
+
+	allowed, err := parseCIDRs(opts.AllowedNets)
+	if err != nil {
+		return nil, err
+	}
+	proxies, err := parseCIDRs(opts.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	var pages map[int][]byte
+	if opts.ErrorPagesDir != "" {
+		if pages, err = loadErrorPages(opts.ErrorPagesDir); err != nil {
+			return nil, err
+		}
+	}
+	if opts.WebhookURL != "" {
+		if err := validateWebhookURL(opts.WebhookURL); err != nil {
+			return nil, err
+		}
 	}
 
-	var handler http.Handler = davHandler
+	var handler http.Handler
+	var roots []string
+	switch {
+	case opts.ZipFile != "" && len(opts.Mounts) > 0:
+		return nil, fmt.Errorf("a zip archive cannot be served together with mounts")
+	case opts.ZipFile != "":
+		// The archive cannot change at runtime, reloads keep serving it
+		if s.zip == nil {
+			zfs, err := openZipFS(opts.ZipFile)
+			if err != nil {
+				return nil, err
+			}
+			s.zip = zfs
+		}
+		roots = append(roots, opts.ZipFile)
+		handler = forbidWrites(davHandler(s.zip, "", s.locks.get(opts.ZipFile), opts, log))
+	case len(opts.Mounts) > 0:
+		root := mountsFS{}
+		mounts := make(map[string]http.Handler, len(opts.Mounts))
+		names := make([]string, 0, len(opts.Mounts))
+		for name := range opts.Mounts {
+			if err := validateMountName(name); err != nil {
+				return nil, err
+			}
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			dir := resolveRoot(opts.Mounts[name])
+			roots = append(roots, dir)
+			// The root lists the mounts through the same file system as
+			// their handlers, so its filters apply to deep listings
+			mounts[name], root[name] = davHandlerFS(webdav.Dir(dir), "/"+name, s.locks.get(dir), opts, log)
+			if opts.MinFreeInodes > 0 {
+				mounts[name] = requireFreeInodes(mounts[name], dir, uint64(opts.MinFreeInodes), statfsFreeInodes)
+			}
+		}
+		var rootLS webdav.LockSystem = s.locks.get("")
+		if opts.LockTimeout > 0 {
+			rootLS = lockTimeoutLS{LockSystem: rootLS, max: opts.LockTimeout}
+		}
+		var rootHandler http.Handler = &webdav.Handler{FileSystem: root, LockSystem: rootLS}
+		if opts.LockTimeout > 0 {
+			rootHandler = capLockTimeout(rootHandler, opts.LockTimeout)
+		}
+		if opts.DirListing {
+			rootHandler = dirListing(rootHandler, root)
+		}
+		if opts.FancyIndex {
+			rootHandler = fancyIndex(rootHandler, root)
+		}
+		if opts.Index != "" {
+			rootHandler = serveIndex(rootHandler, root, opts.Index)
+		}
+		handler = &mountMux{root: rootHandler, mounts: mounts}
+	default:
+		dir := resolveRoot(opts.Folder)
+		roots = append(roots, dir)
+		handler = davHandler(webdav.Dir(dir), "", s.locks.get(dir), opts, log)
+		if opts.MinFreeInodes > 0 {
+			handler = requireFreeInodes(handler, dir, uint64(opts.MinFreeInodes), statfsFreeInodes)
+		}
+	}
+
+	if len(opts.MIMETypes) > 0 {
+		handler = overrideMIMETypes(handler, opts.MIMETypes)
+	}
+	if opts.AdvertiseFeatures {
+		handler = advertiseFeatures(handler, features(opts))
+	}
+	if opts.LockOwnerRequired {
+		handler = requireLockOwner(handler)
+	}
+	if opts.ContentLengthRequired {
+		handler = requireContentLength(handler)
+	}
+	if len(opts.StripProperties) > 0 {
+		handler = stripProps(handler, opts.StripProperties)
+	}
+	if len(allowedDepths) > 0 {
+		handler = limitPropfindDepth(handler, allowedDepths)
+	}
+	if opts.BatchPropfind {
+		handler = batchPropfind(handler)
+	}
+	if opts.ReadOnly {
+		handler = readOnly(handler, opts.ReadOnlyLocks == ReadOnlyLocksGrant, opts.Search)
+	}
+	if len(opts.DisabledMethods) > 0 {
+		handler = disableMethods(handler, opts.DisabledMethods, opts.Search)
+	}
+	if opts.WebhookURL != "" {
+		handler = newWebhook(opts.WebhookURL, opts.WebhookMethods, log).middleware(handler)
+	}
+	if opts.DigestAuth {
+		handler = digestAuth(handler, opts.Credentials, nonceTTL)
+	} else if len(opts.Credentials) > 0 {
+		handler = basicAuth(handler, opts.Credentials)
+	}
+	if pages != nil {
+		handler = errorPages(handler, pages)
+	}
+	if opts.SecureHeaders {
+		handler = secureHeaders(handler)
+	}
+	if opts.Gzip {
+		handler = compress(handler, gzipMinSize)
+	}
+	if egress := minRate(opts.MaxRate, opts.BandwidthTotal); egress > 0 || opts.MaxRatePerConn > 0 {
+		handler = limitRate(handler, opts.MaxRate, egress, opts.MaxRatePerConn)
+	}
+	if opts.RequestTimeout > 0 || opts.UploadTimeout > 0 {
+		handler = requestTimeout(handler, opts.RequestTimeout, opts.UploadTimeout)
+	}
+	var limiter *rateLimiter
+	if opts.RateLimit > 0 || len(opts.MethodRateLimits) > 0 {
+		limiter = newRateLimiter(opts.RateLimit, opts.MethodRateLimits, proxies)
+		handler = limiter.middleware(handler)
+	}
 	if log != nil && log.Enabled() {
-		handler = log.Middleware(davHandler)
+		handler = log.Middleware(handler)
+	}
+	if s.metrics != nil {
+		handler = s.metrics.middleware(handler)
+	}
+	if s.stats != nil {
+		handler = s.stats.middleware(handler)
+	}
+	var metricsEndpoint, statsEndpoint http.Handler
+	if s.metrics != nil {
+		// Metrics reveal paths and traffic, they use the credentials of
+		// the served files when there are any
+		if metricsEndpoint, err = protectStats(s.metrics.handler(), opts, nonceTTL, false); err != nil {
+			return nil, err
+		}
+	}
+	if s.stats != nil || log.Async() {
+		if statsEndpoint, err = protectStats(statsHandler(log, s.stats), opts, nonceTTL, s.stats != nil); err != nil {
+			return nil, err
+		}
 	}
 
-	return &WebDAV{
+	c := &chain{
 		handler: handler,
-		addr:    bind + ":" + strconv.Itoa(port),
-		logger:  log,
+		endpoints: map[string]http.Handler{
+			healthPath: healthHandler(healthBody, healthStatus, s.ready.Load, roots),
+			livezPath:  http.HandlerFunc(livezHandler),
+			readyzPath: readyzHandler(s.ready.Load, roots),
+		},
+		allowed: allowed,
+		proxies: proxies,
+		limiter: limiter,
+		roots:   roots,
+	}
+	if metricsEndpoint != nil {
+		c.endpoints[metricsPath] = metricsEndpoint
 	}
+	if statsEndpoint != nil {
+		c.endpoints[statsPath] = statsEndpoint
+	}
+	if tlsConfig != nil {
+		c.cert = &tlsConfig.Certificates[0]
+	}
+	return c, nil
 }
 
-// Start starts the WebDAV server (blocking)
+// swap makes c the chain serving new requests. Requests already running keep
+// the handlers they started with.
+func (s *WebDAV) swap(c *chain) {
+	entry := s.routes()
+	if len(c.allowed) > 0 {
+		entry = allowClients(entry, c.allowed, c.proxies, s.logger)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handler = c.handler
+	s.endpoints = c.endpoints
+	s.entry = entry
+	s.limiter = c.limiter
+	s.cert = c.cert
+}
+
+// serveEntry hands r to the current outermost handler
+func (s *WebDAV) serveEntry(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	entry := s.entry
+	s.mu.RUnlock()
+	entry.ServeHTTP(w, r)
+}
+
+// certificate returns the current TLS certificate for every handshake
+func (s *WebDAV) certificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cert, nil
+}
+
+// currentLimiter returns the rate limiter of the current chain
+func (s *WebDAV) currentLimiter() *rateLimiter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.limiter
+}
+
+// davHandler serves fs under prefix with the lock system ls, wrapped in the
+// options that resolve request paths against fs
+func davHandler(fs webdav.FileSystem, prefix string, ls webdav.LockSystem, opts Options, log *logger.Logger) http.Handler {
+	handler, _ := davHandlerFS(fs, prefix, ls, opts, log)
+	return handler
+}
+
+// davHandlerFS is davHandler also returning fs wrapped as the WebDAV handler
+// sees it, without the prefix, for other views of the same tree
+func davHandlerFS(fs webdav.FileSystem, prefix string, ls webdav.LockSystem, opts Options, log *logger.Logger) (http.Handler, webdav.FileSystem) {
+	// wrapped is set once fs is wrapped, as wrappers may answer with a statusError
+	wrapped := false
+	root, _ := fs.(webdav.Dir)
+	if d, ok := fs.(webdav.Dir); ok && opts.NoSymlinks {
+		fs = newSymlinkFS(d)
+		wrapped = true
+	}
+	if root != "" && opts.CrossDeviceMove {
+		fs = crossDeviceFS{FileSystem: fs, root: string(root)}
+	}
+	if opts.ETag == ETagStrong {
+		// Inside the other wrappers, which pass file information through
+		fs = strongETagFS{FileSystem: fs, cache: newETagCache(etagCacheSize)}
+	}
+	var fallback *readOnlyFallback
+	if opts.ReadOnlyFallback {
+		// Innermost, to see the outcome of every write on the file system
+		fallback = newReadOnlyFallback(log)
+		fs = readOnlyFallbackFS{FileSystem: fs, fallback: fallback}
+	}
+	var q *quota
+	if opts.Quota > 0 {
+		q = newQuota(fs, opts.Quota)
+		fs = quotaFS{FileSystem: fs, quota: q}
+		wrapped = true
+	}
+	if opts.CaseInsensitive {
+		fs = caseInsensitiveFS{FileSystem: fs}
+		wrapped = true
+	}
+	var configs *dirConfigs
+	if opts.DirConfig {
+		configs = newDirConfigs(fs)
+		fs = dirConfigFS{FileSystem: fs, configs: configs}
+		wrapped = true
+	}
+	if opts.DeleteMultiStatus {
+		fs = deleteFS{FileSystem: fs}
+		wrapped = true
+	}
+	var props *deadPropsStore
+	if opts.DeadProps {
+		// Outside the wrappers that wrap files, which would hide the properties
+		props = newDeadPropsStore(fs, log)
+		fs = deadPropsFS{FileSystem: fs, store: props}
+	}
+	if opts.ReportQuota {
+		// Outermost, so the WebDAV handler sees the properties of its files
+		fs = quotaPropsFS{FileSystem: fs, dir: string(root), quota: q}
+	}
+	if opts.PartialPut {
+		// Outside the others, so they account for the write position
+		fs = partialPutFS{FileSystem: fs}
+		wrapped = true
+	}
+	if opts.DryRun {
+		// Outermost, so no modification reaches the wrappers inside
+		fs = newDryRunFS(fs, log)
+	}
+	if opts.LockTimeout > 0 {
+		ls = lockTimeoutLS{LockSystem: ls, max: opts.LockTimeout}
+	}
+	mfs := fs
+	if prefix != "" {
+		mfs = prefixFS{FileSystem: fs, prefix: prefix}
+	}
+
+	var handler http.Handler = &webdav.Handler{
+		Prefix:     prefix,
+		FileSystem: fs,
+		LockSystem: newConditionLS(ls, fs),
+		Logger:     recordError,
+	}
+	if wrapped {
+		handler = statusErrors(handler)
+	}
+	if opts.LockTimeout > 0 {
+		handler = capLockTimeout(handler, opts.LockTimeout)
+	}
+	if opts.ResponseBufferSize > 0 {
+		handler = bufferedGet(handler, mfs, opts.ResponseBufferSize)
+	}
+	if opts.DirListing {
+		handler = dirListing(handler, mfs)
+	}
+	if opts.FancyIndex {
+		handler = fancyIndex(handler, mfs)
+	}
+	if opts.DirListing || opts.FancyIndex {
+		// Files are compared by the WebDAV handler already. Inside serveIndex,
+		// to compare against the index file it serves.
+		handler = ifModifiedSince(handler, mfs)
+	}
+	if opts.Index != "" {
+		handler = serveIndex(handler, mfs, opts.Index)
+	}
+	if opts.Checksums {
+		// Outside the listings, so a checksum query for a collection is refused
+		handler = checksums(handler, mfs)
+	}
+	if opts.RenameOnConflict {
+		handler = renameOnConflict(handler, mfs)
+	}
+	if len(opts.ProtectedNames) > 0 {
+		handler = protectNames(handler, mfs, opts.ProtectedNames)
+	}
+	if root != "" && opts.PreserveModTimes && !opts.DryRun {
+		handler = preserveModTimes(handler, mfs, string(root), prefix)
+	}
+	if props != nil && !opts.DryRun {
+		handler = copyDeadProps(handler, props, prefix)
+	}
+	if opts.MaxMoveCopySize > 0 {
+		handler = limitMoveCopy(handler, mfs, opts.MaxMoveCopySize)
+	}
+	if opts.MaxBodySize > 0 {
+		handler = limitBody(handler, mfs, opts.MaxBodySize)
+	}
+	if q != nil {
+		handler = q.middleware(handler, mfs)
+	}
+	if opts.PartialPut {
+		handler = partialPut(handler, mfs)
+	}
+	if opts.ReportQuota {
+		handler = quotaPropsRequest(handler)
+	}
+	if opts.DeleteMultiStatus {
+		handler = deleteMultiStatus(handler, prefix)
+	}
+	if configs != nil {
+		handler = dirConfigPolicy(handler, prefix, configs)
+	}
+	if opts.Search {
+		// Outside the directory policy, which checks every match as a
+		// PROPFIND. The depths were validated when the server was built.
+		allowedDepths, _ := parsePropfindDepths(opts.PropfindAllowedDepths)
+		handler = search(handler, mfs, allowedDepths)
+	}
+	if fallback != nil {
+		handler = fallback.middleware(handler, opts.Search)
+	}
+	if opts.ETag == ETagStrong {
+		handler = contentETags(handler)
+	}
+	if log != nil && !log.Enabled() {
+		log = nil
+	}
+	if opts.VerboseErrors || log != nil {
+		handler = errorDetail(handler, opts.VerboseErrors, log)
+	}
+	return handler, fs
+}
+
+// ErrAddrInUse is returned by Start when another socket listens on the address
+var ErrAddrInUse = errors.New("address already in use")
+
+// Start starts the WebDAV server (blocking). It shuts down gracefully on
+// SIGINT or SIGTERM.
 func (s *WebDAV) Start() error {
-	fmt.Printf("WebDAV server: http://%s\n", s.addr)
-	if err := http.ListenAndServe(s.addr, s.handler); err != nil {
+	if s.singleInstance {
+		for _, root := range s.roots {
+			release, err := lockDirectory(root)
+			if err != nil {
+				return err
+			}
+			defer release()
+		}
+	}
+
+	lc := net.ListenConfig{Control: dualStackControl(s.dualStack)}
+	listener, err := lc.Listen(context.Background(), "tcp", s.addr)
+	if err != nil {
+		if IsAddrInUse(err) {
+			return fmt.Errorf("%w: %s, is another server running on this port?", ErrAddrInUse, s.addr)
+		}
 		return fmt.Errorf("server error: %w", err)
 	}
-	return nil
+
+	fmt.Printf("WebDAV server: %s\n", s.URL())
+	return s.serve(&tcpOptionsListener{Listener: listener, noDelay: s.tcpNoDelay})
+}
+
+// serve accepts connections on listener until the server is shut down
+func (s *WebDAV) serve(listener net.Listener) error {
+	stop := startSweeper(s.currentLimiter)
+	defer stop()
+
+	// The listener already queues connections, so every request Serve
+	// picks up finds the server ready
+	s.ready.Store(true)
+	defer s.ready.Store(false)
+	errc := make(chan error, 1)
+	go func() {
+		if s.server.TLSConfig != nil {
+			errc <- s.server.ServeTLS(listener, "", "")
+		} else {
+			errc <- s.server.Serve(listener)
+		}
+	}()
+	if err := sdNotify("READY=1"); err != nil {
+		fmt.Printf("Warning: failed to notify systemd: %v\n", err)
+	}
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+
+	// Without a reload source SIGHUP keeps its default behaviour
+	var hupc chan os.Signal
+	if s.reload != nil {
+		hupc = make(chan os.Signal, 1)
+		signal.Notify(hupc, syscall.SIGHUP)
+		defer signal.Stop(hupc)
+	}
+
+	// SIGUSR1 starts a new log file, when there is one
+	var usr1c chan os.Signal
+	if s.logger != nil && s.logger.Enabled() && len(rotateLogSignals) > 0 {
+		usr1c = make(chan os.Signal, 1)
+		signal.Notify(usr1c, rotateLogSignals...)
+		defer signal.Stop(usr1c)
+	}
+
+	var filec <-chan struct{}
+	if s.shutdownFile != "" {
+		var stopWatch func()
+		filec, stopWatch = watchShutdownFile(s.shutdownFile, s.shutdownPoll)
+		defer stopWatch()
+	}
+
+	for {
+		select {
+		case err := <-errc:
+			if errors.Is(err, http.ErrServerClosed) {
+				return nil
+			}
+			return fmt.Errorf("server error: %w", err)
+		case sig := <-sigc:
+			fmt.Printf("Received %s, shutting down\n", sig)
+			return s.shutdown()
+		case <-filec:
+			fmt.Printf("Found %s, shutting down\n", s.shutdownFile)
+			return s.shutdown()
+		case <-hupc:
+			s.reloadFromSource()
+		case <-usr1c:
+			if err := s.logger.Rotate(); err != nil {
+				logf(s.logger, "Log rotation failed: %v", err)
+			} else {
+				logf(s.logger, "Log file rotated")
+			}
+		}
+	}
+}
+
+// Shutdown stops a server started with Start gracefully, as SIGTERM does.
+// Start returns as soon as the shutdown begins; Shutdown itself returns once
+// in-flight requests have finished or were cut off by the timeout.
+func (s *WebDAV) Shutdown() error {
+	return s.shutdown()
+}
+
+// shutdown stops accepting connections, closes idle keep-alive connections and
+// waits for in-flight requests. Connections still open when the timeout
+// expires are closed forcibly so the process always exits, unless the
+// timeout is negative.
+func (s *WebDAV) shutdown() error {
+	s.ready.Store(false)
+	if err := sdNotify("STOPPING=1"); err != nil {
+		fmt.Printf("Warning: failed to notify systemd: %v\n", err)
+	}
+	ctx := context.Background()
+	if s.shutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.shutdownTimeout)
+		defer cancel()
+	}
+
+	err := s.server.Shutdown(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		fmt.Println("Graceful shutdown timed out, closing remaining connections")
+		err = s.server.Close()
+	}
+	s.releaseLocks()
+	return err
+}
+
+// releaseLocks unlocks the locks clients still hold and logs them, when the
+// lock store tracks them
+func (s *WebDAV) releaseLocks() {
+	for _, name := range s.locks.releaseAll(time.Now()) {
+		logf(s.logger, "Released lock on %s", name)
+	}
+}
+
+// logf prints a server message to stdout and log, once when log already
+// writes to stdout
+func logf(log *logger.Logger, format string, args ...any) {
+	if !log.PrintsToStdout() {
+		fmt.Printf(format+"\n", args...)
+	}
+	if log != nil {
+		log.Printf(format, args...)
+	}
 }
 
 // Addr returns the server address
@@ -53,7 +1045,50 @@ func (s *WebDAV) Addr() string {
 	return s.addr
 }
 
+// URL returns the base URL of the server
+func (s *WebDAV) URL() string {
+	if s.server.TLSConfig != nil {
+		return "https://" + s.addr
+	}
+	return "http://" + s.addr
+}
+
 // Handler returns the HTTP handler
 func (s *WebDAV) Handler() http.Handler {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.handler
 }
+
+// routes serves the server's own endpoints and hands every other request to
+// the WebDAV handler. Paths are matched exactly so WebDAV requests are never
+// cleaned or redirected the way http.ServeMux would.
+func (s *WebDAV) routes() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.RLock()
+		h, ok := s.endpoints[r.URL.Path]
+		if !ok {
+			h = s.handler
+		}
+		s.mu.RUnlock()
+		h.ServeHTTP(w, r)
+	})
+}
+
+// resolveRoot returns the absolute real path of folder so that every path
+// check operates on the same tree the file system follows. The folder is
+// returned unchanged when it cannot be resolved.
+func resolveRoot(folder string) string {
+	abs, err := filepath.Abs(folder)
+	if err != nil {
+		return folder
+	}
+	real, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return folder
+	}
+	if real != abs {
+		fmt.Printf("Serving %s (resolved from symlink %s)\n", real, abs)
+	}
+	return real
+}
@@ -2,7 +2,11 @@ package server
 
 import (
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"golang.org/x/net/webdav"
 	"gowebdavd/internal/logger"
@@ -78,6 +82,37 @@ func TestWebDAVAddr(t *testing.T) {
 	}
 }
 
+func TestServerTimeouts(t *testing.T) {
+	tests := []struct {
+		name                          string
+		opts                          Options
+		read, readHeader, write, idle time.Duration
+	}{
+		{"defaults", Options{}, 0, readHeaderTimeout, 0, DefaultIdleTimeout},
+		{"custom", Options{ReadTimeout: 5 * time.Second, WriteTimeout: time.Minute, IdleTimeout: 30 * time.Second}, 5 * time.Second, 5 * time.Second, time.Minute, 30 * time.Second},
+		{"long read", Options{ReadTimeout: time.Hour}, time.Hour, readHeaderTimeout, 0, DefaultIdleTimeout},
+		{"unlimited idle", Options{IdleTimeout: -1}, 0, readHeaderTimeout, 0, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Folder = t.TempDir()
+			srv, err := NewWithOptions(tt.opts, nil)
+			if err != nil {
+				t.Fatalf("NewWithOptions() error = %v", err)
+			}
+			hs := srv.server
+			if hs.ReadTimeout != tt.read || hs.ReadHeaderTimeout != tt.readHeader || hs.WriteTimeout != tt.write || hs.IdleTimeout != tt.idle {
+				t.Errorf("timeouts = read %s, header %s, write %s, idle %s, want %s, %s, %s, %s",
+					hs.ReadTimeout, hs.ReadHeaderTimeout, hs.WriteTimeout, hs.IdleTimeout, tt.read, tt.readHeader, tt.write, tt.idle)
+			}
+		})
+	}
+
+	if _, err := NewWithOptions(Options{Folder: t.TempDir(), WriteTimeout: -time.Second}, nil); err == nil {
+		t.Error("NewWithOptions() should reject a negative write timeout")
+	}
+}
+
 func TestWebDAVHandler(t *testing.T) {
 	tmpDir := t.TempDir()
 	srv := New(tmpDir, 18080, "127.0.0.1", nil)
@@ -150,3 +185,96 @@ func TestNew_WithDisabledLogger(t *testing.T) {
 		t.Error("Handler.FileSystem is nil")
 	}
 }
+
+func TestNew_SymlinkedRoot(t *testing.T) {
+	realDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(realDir, "file.txt"), []byte("content"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(realDir, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	srv := New(link, 18080, "127.0.0.1", nil)
+
+	want, err := filepath.EvalSymlinks(realDir)
+	if err != nil {
+		t.Fatalf("EvalSymlinks() error = %v", err)
+	}
+	if len(srv.roots) != 1 || srv.roots[0] != want {
+		t.Errorf("roots = %v, want resolved path %s", srv.roots, want)
+	}
+
+	handler := srv.Handler().(*webdav.Handler)
+	if dir, ok := handler.FileSystem.(webdav.Dir); !ok || string(dir) != want {
+		t.Errorf("FileSystem = %v, want webdav.Dir(%s)", handler.FileSystem, want)
+	}
+
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/file.txt", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "content" {
+		t.Errorf("GET through symlinked root = %d %q, want 200 %q", rec.Code, rec.Body.String(), "content")
+	}
+
+	rec = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/../link/file.txt", nil))
+	if rec.Code == http.StatusOK {
+		t.Error("GET escaping the resolved root should not succeed")
+	}
+}
+
+func TestResolveRoot_Missing(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+	if got := resolveRoot(missing); got != missing {
+		t.Errorf("resolveRoot() = %s, want %s unchanged", got, missing)
+	}
+}
+
+func TestZeroBytePut(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{name: "default"},
+		{name: "content length required", opts: Options{ContentLengthRequired: true, MaxBodySize: 1 << 20}},
+		{name: "gzip and bandwidth", opts: Options{Gzip: true, BandwidthTotal: 1 << 20}},
+		{name: "filesystem wrappers", opts: Options{CaseInsensitive: true, DirConfig: true, DeleteMultiStatus: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			existing := filepath.Join(tmpDir, "existing.txt")
+			if err := os.WriteFile(existing, []byte("previous content"), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+			opts := tt.opts
+			opts.Folder, opts.Port, opts.Bind = tmpDir, 18080, "127.0.0.1"
+			srv, err := NewWithOptions(opts, nil)
+			if err != nil {
+				t.Fatalf("NewWithOptions() error = %v", err)
+			}
+
+			for _, name := range []string{"placeholder.txt", "existing.txt"} {
+				req := httptest.NewRequest(http.MethodPut, "/"+name, http.NoBody)
+				req.Header.Set("Content-Length", "0")
+				rec := httptest.NewRecorder()
+				srv.Handler().ServeHTTP(rec, req)
+				if rec.Code != http.StatusCreated {
+					t.Errorf("PUT /%s status = %d, want %d", name, rec.Code, http.StatusCreated)
+				}
+				if rec.Header().Get("ETag") == "" {
+					t.Errorf("PUT /%s should return an ETag", name)
+				}
+				fi, err := os.Stat(filepath.Join(tmpDir, name))
+				if err != nil {
+					t.Fatalf("PUT /%s did not create the file: %v", name, err)
+				}
+				if fi.Size() != 0 {
+					t.Errorf("PUT /%s left %d bytes, want an empty file", name, fi.Size())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/webdav"
+
+	"gowebdavd/internal/pidfile"
+)
+
+// sharedLock is a lock as kept in a lockBackend
+type sharedLock struct {
+	Token string `json:"token"`
+	// Space tells apart the served roots sharing a backend
+	Space     string `json:"space"`
+	Root      string `json:"root"`
+	ZeroDepth bool   `json:"zero_depth,omitempty"`
+	OwnerXML  string `json:"owner,omitempty"`
+	// Expires is the zero time for locks without a timeout
+	Expires time.Time `json:"expires,omitzero"`
+}
+
+// lockBackend keeps the locks of several servers, so that a lock taken on
+// one of them is honoured by all. Both methods give fn exclusive access to
+// the locks; update saves the locks fn returns unless it fails.
+type lockBackend interface {
+	view(fn func(locks []sharedLock) error) error
+	update(fn func(locks []sharedLock) ([]sharedLock, error)) error
+}
+
+// sharedLS is a webdav.LockSystem keeping its locks in a lockBackend. Like
+// the in-memory lock system it grants exclusive write locks only.
+type sharedLS struct {
+	backend lockBackend
+	space   string
+}
+
+func newSharedLS(backend lockBackend, space string) sharedLS {
+	return sharedLS{backend: backend, space: space}
+}
+
+// live returns the unexpired locks of every space
+func live(locks []sharedLock, now time.Time) []sharedLock {
+	kept := locks[:0]
+	for _, l := range locks {
+		if l.Expires.IsZero() || now.Before(l.Expires) {
+			kept = append(kept, l)
+		}
+	}
+	return kept
+}
+
+// covers reports whether lock l applies to name
+func (l sharedLock) covers(name string) bool {
+	return l.Root == name || (!l.ZeroDepth && isAncestor(l.Root, name))
+}
+
+// isAncestor reports whether dir is a proper ancestor of name
+func isAncestor(dir, name string) bool {
+	if dir == "/" {
+		return name != "/"
+	}
+	return strings.HasPrefix(name, dir+"/")
+}
+
+func (ls sharedLS) Confirm(now time.Time, name0, name1 string, conditions ...webdav.Condition) (func(), error) {
+	err := ls.backend.view(func(locks []sharedLock) error {
+		for _, name := range []string{name0, name1} {
+			if name != "" && !ls.held(locks, now, name, conditions) {
+				return webdav.ErrConfirmationFailed
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return func() {}, nil
+}
+
+// held reports whether one of the conditions names a live lock covering
+// name, which is what the in-memory lock system requires
+func (ls sharedLS) held(locks []sharedLock, now time.Time, name string, conditions []webdav.Condition) bool {
+	for _, c := range conditions {
+		for _, l := range live(locks, now) {
+			if l.Space == ls.space && l.Token == c.Token && l.covers(name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (ls sharedLS) Create(now time.Time, details webdav.LockDetails) (string, error) {
+	var token string
+	err := ls.backend.update(func(locks []sharedLock) ([]sharedLock, error) {
+		locks = live(locks, now)
+		for _, l := range locks {
+			if l.Space != ls.space {
+				continue
+			}
+			if l.covers(details.Root) || (!details.ZeroDepth && isAncestor(details.Root, l.Root)) {
+				return nil, webdav.ErrLocked
+			}
+		}
+		var err error
+		if token, err = newLockToken(); err != nil {
+			return nil, err
+		}
+		l := sharedLock{
+			Token:     token,
+			Space:     ls.space,
+			Root:      details.Root,
+			ZeroDepth: details.ZeroDepth,
+			OwnerXML:  details.OwnerXML,
+		}
+		if details.Duration >= 0 {
+			l.Expires = now.Add(details.Duration)
+		}
+		return append(locks, l), nil
+	})
+	return token, err
+}
+
+func (ls sharedLS) Refresh(now time.Time, token string, duration time.Duration) (webdav.LockDetails, error) {
+	var details webdav.LockDetails
+	err := ls.backend.update(func(locks []sharedLock) ([]sharedLock, error) {
+		locks = live(locks, now)
+		for i, l := range locks {
+			if l.Space != ls.space || l.Token != token {
+				continue
+			}
+			locks[i].Expires = time.Time{}
+			if duration >= 0 {
+				locks[i].Expires = now.Add(duration)
+			}
+			details = webdav.LockDetails{Root: l.Root, Duration: duration, OwnerXML: l.OwnerXML, ZeroDepth: l.ZeroDepth}
+			return locks, nil
+		}
+		return nil, webdav.ErrNoSuchLock
+	})
+	return details, err
+}
+
+func (ls sharedLS) Unlock(now time.Time, token string) error {
+	return ls.backend.update(func(locks []sharedLock) ([]sharedLock, error) {
+		locks = live(locks, now)
+		for i, l := range locks {
+			if l.Space == ls.space && l.Token == token {
+				return append(locks[:i], locks[i+1:]...), nil
+			}
+		}
+		return nil, webdav.ErrNoSuchLock
+	})
+}
+
+// Polling of a lock file held by another server
+const (
+	lockFilePoll    = 5 * time.Millisecond
+	lockFileTimeout = 5 * time.Second
+)
+
+// fileLockBackend keeps locks as JSON in a file, for servers sharing a file
+// system. Access is serialized with an advisory lock on a sibling ".lock"
+// file, and the file is replaced atomically on every change.
+type fileLockBackend struct {
+	// mu serializes the goroutines of this server, the advisory lock only
+	// tells processes apart
+	mu   sync.Mutex
+	path string
+	lock pidfile.File
+}
+
+func newFileLockBackend(path string) *fileLockBackend {
+	return &fileLockBackend{path: path, lock: pidfile.NewWithPath(path)}
+}
+
+// acquire takes the advisory lock, waiting up to lockFileTimeout for other
+// servers to release it
+func (b *fileLockBackend) acquire() error {
+	deadline := time.Now().Add(lockFileTimeout)
+	for {
+		err := b.lock.Lock()
+		if !errors.Is(err, pidfile.ErrLocked) {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("lock file %s: %w", b.path, err)
+		}
+		time.Sleep(lockFilePoll)
+	}
+}
+
+// load reads the locks; a missing file holds none
+func (b *fileLockBackend) load() ([]sharedLock, error) {
+	data, err := os.ReadFile(b.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lock file: %w", err)
+	}
+	var locks []sharedLock
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &locks); err != nil {
+			return nil, fmt.Errorf("invalid lock file %s: %w", b.path, err)
+		}
+	}
+	return locks, nil
+}
+
+func (b *fileLockBackend) view(fn func(locks []sharedLock) error) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.acquire(); err != nil {
+		return err
+	}
+	defer b.lock.Unlock()
+
+	locks, err := b.load()
+	if err != nil {
+		return err
+	}
+	return fn(locks)
+}
+
+func (b *fileLockBackend) update(fn func(locks []sharedLock) ([]sharedLock, error)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.acquire(); err != nil {
+		return err
+	}
+	defer b.lock.Unlock()
+
+	locks, err := b.load()
+	if err != nil {
+		return err
+	}
+	if locks, err = fn(locks); err != nil {
+		return err
+	}
+	if locks == nil {
+		locks = []sharedLock{}
+	}
+	data, err := json.MarshalIndent(locks, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := b.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+	if err := os.Rename(tmp, b.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+	return nil
+}
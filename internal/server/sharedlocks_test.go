@@ -0,0 +1,135 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+func TestSharedLSSeesOtherInstance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "locks.json")
+	a := newSharedLS(newFileLockBackend(path), "/srv")
+	b := newSharedLS(newFileLockBackend(path), "/srv")
+	now := time.Now()
+	details := webdav.LockDetails{Root: "/dir", Duration: time.Minute}
+
+	token, err := a.Create(now, details)
+	if err != nil {
+		t.Fatalf("Create() on A error = %v", err)
+	}
+	if _, err := b.Create(now, details); !errors.Is(err, webdav.ErrLocked) {
+		t.Errorf("Create() on B of the same root error = %v, want %v", err, webdav.ErrLocked)
+	}
+	// The lock has infinite depth and covers the members of /dir
+	if _, err := b.Create(now, webdav.LockDetails{Root: "/dir/file.txt", Duration: time.Minute, ZeroDepth: true}); !errors.Is(err, webdav.ErrLocked) {
+		t.Errorf("Create() on B of a member error = %v, want %v", err, webdav.ErrLocked)
+	}
+	if _, err := b.Confirm(now, "/dir/file.txt", ""); !errors.Is(err, webdav.ErrConfirmationFailed) {
+		t.Errorf("Confirm() on B without the token error = %v, want %v", err, webdav.ErrConfirmationFailed)
+	}
+	if _, err := b.Confirm(now, "/dir/file.txt", "", webdav.Condition{Token: token}); err != nil {
+		t.Errorf("Confirm() on B with the token error = %v", err)
+	}
+
+	// Other served roots sharing the file are independent
+	other := newSharedLS(newFileLockBackend(path), "/other")
+	if _, err := other.Create(now, details); err != nil {
+		t.Errorf("Create() in another space error = %v", err)
+	}
+
+	if _, err := b.Refresh(now, token, time.Hour); err != nil {
+		t.Errorf("Refresh() on B error = %v", err)
+	}
+	if err := b.Unlock(now, token); err != nil {
+		t.Errorf("Unlock() on B error = %v", err)
+	}
+	if err := a.Unlock(now, token); !errors.Is(err, webdav.ErrNoSuchLock) {
+		t.Errorf("second Unlock() on A error = %v, want %v", err, webdav.ErrNoSuchLock)
+	}
+	if _, err := a.Create(now, details); err != nil {
+		t.Errorf("Create() on A after unlock error = %v", err)
+	}
+}
+
+func TestSharedLSExpiry(t *testing.T) {
+	ls := newSharedLS(newFileLockBackend(filepath.Join(t.TempDir(), "locks.json")), "/srv")
+	now := time.Now()
+	details := webdav.LockDetails{Root: "/file.txt", Duration: time.Minute, ZeroDepth: true}
+
+	if _, err := ls.Create(now, details); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := ls.Create(now.Add(2*time.Minute), details); err != nil {
+		t.Errorf("Create() after expiry error = %v, want the old lock gone", err)
+	}
+}
+
+func TestLockFileServers(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "file.txt"), []byte("data"), 0644)
+	lockFilePath := filepath.Join(t.TempDir(), "locks.json")
+	newServer := func() http.Handler {
+		srv, err := NewWithOptions(Options{Folder: dir, Port: 18080, Bind: "127.0.0.1", LockFile: lockFilePath}, nil)
+		if err != nil {
+			t.Fatalf("NewWithOptions() error = %v", err)
+		}
+		return srv.Handler()
+	}
+	a, b := newServer(), newServer()
+
+	token := lockFile(t, a, "/file.txt")
+	if rec := doRequest(b, http.MethodPut, "/file.txt", "new", nil); rec.Code != http.StatusLocked {
+		t.Errorf("PUT on B without the token status = %d, want %d", rec.Code, http.StatusLocked)
+	}
+	if rec := doRequest(b, http.MethodPut, "/file.txt", "new", map[string]string{"If": "(<" + token + ">)"}); rec.Code >= 300 {
+		t.Errorf("PUT on B with the token status = %d, want success", rec.Code)
+	}
+	if rec := doRequest(b, "UNLOCK", "/file.txt", "", map[string]string{"Lock-Token": "<" + token + ">"}); rec.Code != http.StatusNoContent {
+		t.Errorf("UNLOCK on B status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if rec := doRequest(a, http.MethodPut, "/file.txt", "again", nil); rec.Code >= 300 {
+		t.Errorf("PUT on A after unlock status = %d, want success", rec.Code)
+	}
+}
+
+func TestLockFileReleasedOnShutdown(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "file.txt"), []byte("data"), 0644)
+	lockFilePath := filepath.Join(t.TempDir(), "locks.json")
+	srv, err := NewWithOptions(Options{Folder: dir, Bind: "127.0.0.1", LockFile: lockFilePath, ShutdownReleaseLocks: true}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	lockFile(t, srv.Handler(), "/file.txt")
+	_, done := startTestServer(t, srv)
+
+	if err := srv.shutdown(); err != nil {
+		t.Errorf("shutdown() error = %v", err)
+	}
+	waitServe(t, done)
+
+	data, err := os.ReadFile(lockFilePath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	var locks []sharedLock
+	if err := json.Unmarshal(data, &locks); err != nil {
+		t.Fatalf("lock file %q does not parse: %v", data, err)
+	}
+	if len(locks) != 0 {
+		t.Errorf("lock file holds %d locks after shutdown, want none", len(locks))
+	}
+}
+
+func TestLockFileInvalidMode(t *testing.T) {
+	_, err := NewWithOptions(Options{Folder: t.TempDir(), LockFile: filepath.Join(t.TempDir(), "locks.json"), LockMode: LockModeNone}, nil)
+	if err == nil {
+		t.Error("NewWithOptions() should reject a lock file with lock mode none")
+	}
+}
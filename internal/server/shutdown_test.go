@@ -0,0 +1,186 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// startTestServer serves srv on a random local port and returns its URL and
+// a channel receiving the result of serve
+func startTestServer(t *testing.T, srv *WebDAV) (string, <-chan error) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.serve(listener)
+	}()
+	return "http://" + listener.Addr().String(), done
+}
+
+// waitServe waits for serve to return
+func waitServe(t *testing.T, done <-chan error) {
+	t.Helper()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("serve() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve() did not return after shutdown")
+	}
+}
+
+func TestShutdownIdleKeepAlive(t *testing.T) {
+	srv := New(t.TempDir(), 0, "127.0.0.1", nil)
+	url, done := startTestServer(t, srv)
+
+	// Leave an idle keep-alive connection open
+	client := &http.Client{Transport: &http.Transport{}}
+	resp, err := client.Get(url + "/health")
+	if err != nil {
+		t.Fatalf("GET /health error = %v", err)
+	}
+	resp.Body.Close()
+
+	start := time.Now()
+	if err := srv.shutdown(); err != nil {
+		t.Errorf("shutdown() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("shutdown() took %s with an idle connection", elapsed)
+	}
+	waitServe(t, done)
+}
+
+func TestShutdownForceClose(t *testing.T) {
+	srv := New(t.TempDir(), 0, "127.0.0.1", nil)
+	srv.shutdownTimeout = 100 * time.Millisecond
+
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	srv.handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+	url, done := startTestServer(t, srv)
+
+	go http.Get(url + "/hang")
+	<-started
+
+	start := time.Now()
+	srv.shutdown()
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("shutdown() took %s despite the timeout", elapsed)
+	}
+	waitServe(t, done)
+}
+
+func TestShutdownWithoutTimeout(t *testing.T) {
+	srv, err := NewWithOptions(Options{Folder: t.TempDir(), Bind: "127.0.0.1", ShutdownTimeout: -1}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	srv.handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+	url, done := startTestServer(t, srv)
+
+	go http.Get(url + "/upload")
+	<-started
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- srv.shutdown() }()
+	select {
+	case err := <-stopped:
+		t.Fatalf("shutdown() = %v before the in-flight request finished", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Errorf("shutdown() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown() did not return after the request finished")
+	}
+	waitServe(t, done)
+}
+
+func TestShutdownFile(t *testing.T) {
+	sentinel := filepath.Join(t.TempDir(), "shutdown")
+	srv, err := NewWithOptions(Options{Folder: t.TempDir(), Bind: "127.0.0.1", ShutdownFile: sentinel}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	srv.shutdownPoll = 10 * time.Millisecond
+	url, done := startTestServer(t, srv)
+
+	resp, err := http.Get(url + "/health")
+	if err != nil {
+		t.Fatalf("GET /health error = %v", err)
+	}
+	resp.Body.Close()
+	select {
+	case err := <-done:
+		t.Fatalf("serve() returned before the shutdown file appeared: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := os.WriteFile(sentinel, nil, 0644); err != nil {
+		t.Fatalf("Failed to create shutdown file: %v", err)
+	}
+	waitServe(t, done)
+	if _, err := os.Stat(sentinel); !os.IsNotExist(err) {
+		t.Error("Shutdown file should be removed after triggering shutdown")
+	}
+}
+
+func TestShutdownReleaseLocks(t *testing.T) {
+	for _, release := range []bool{true, false} {
+		dir := t.TempDir()
+		for _, name := range []string{"locked.txt", "unlocked.txt"} {
+			os.WriteFile(filepath.Join(dir, name), []byte("data"), 0644)
+		}
+		srv, err := NewWithOptions(Options{Folder: dir, Bind: "127.0.0.1", ShutdownReleaseLocks: release}, nil)
+		if err != nil {
+			t.Fatalf("NewWithOptions() error = %v", err)
+		}
+		h := srv.Handler()
+		lockFile(t, h, "/locked.txt")
+		token := lockFile(t, h, "/unlocked.txt")
+		if rec := doRequest(h, "UNLOCK", "/unlocked.txt", "", map[string]string{"Lock-Token": "<" + token + ">"}); rec.Code != http.StatusNoContent {
+			t.Fatalf("UNLOCK status = %d, want %d", rec.Code, http.StatusNoContent)
+		}
+		_, done := startTestServer(t, srv)
+
+		if err := srv.shutdown(); err != nil {
+			t.Errorf("shutdown() error = %v", err)
+		}
+		waitServe(t, done)
+
+		// Released locks no longer block writes
+		rec := doRequest(h, http.MethodPut, "/locked.txt", "data", nil)
+		if locked := rec.Code == http.StatusLocked; locked == release {
+			t.Errorf("release %v: PUT after shutdown status = %d", release, rec.Code)
+		}
+		if got := srv.locks.releaseAll(time.Now()); len(got) != 0 {
+			t.Errorf("release %v: locks left after shutdown = %v, want none", release, got)
+		}
+	}
+}
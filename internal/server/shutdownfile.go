@@ -0,0 +1,37 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"os"
+	"time"
+)
+
+// shutdownFilePoll is how often the shutdown file is checked for
+const shutdownFilePoll = time.Second
+
+// watchShutdownFile polls for path every interval and closes the returned
+// channel once it exists. The file is removed so that the next start is not
+// stopped by it again. Calling stop ends the watch.
+func watchShutdownFile(path string, interval time.Duration) (found <-chan struct{}, stop func()) {
+	foundc := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := os.Stat(path); err == nil {
+					os.Remove(path)
+					close(foundc)
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return foundc, func() { close(done) }
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"os"
+	"syscall"
+)
+
+// rotateLogSignals make the server start a new log file
+var rotateLogSignals = []os.Signal{syscall.SIGUSR1}
@@ -0,0 +1,49 @@
+//go:build !windows
+
+package server
+
+import (
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"gowebdavd/internal/logger"
+)
+
+func TestSIGUSR1RotatesLog(t *testing.T) {
+	dir := t.TempDir()
+	log, err := logger.New(true, dir)
+	if err != nil {
+		t.Fatalf("logger.New() error = %v", err)
+	}
+	defer log.Close()
+	srv, err := NewWithOptions(Options{Folder: t.TempDir(), Bind: "127.0.0.1"}, log)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	_, done := startTestServer(t, srv)
+
+	// Keep SIGUSR1 from terminating the test before serve subscribes to it
+	ignored := make(chan os.Signal, 1)
+	signal.Notify(ignored, syscall.SIGUSR1)
+	defer signal.Stop(ignored)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+		time.Sleep(10 * time.Millisecond)
+		files, _ := filepath.Glob(filepath.Join(dir, "gowebdavd_*.log"))
+		if len(files) >= 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d log files after SIGUSR1, want a new one", len(files))
+		}
+	}
+
+	srv.shutdown()
+	waitServe(t, done)
+}
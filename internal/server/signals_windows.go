@@ -0,0 +1,11 @@
+//go:build windows
+
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import "os"
+
+// rotateLogSignals is empty, Windows has no SIGUSR1
+var rotateLogSignals []os.Signal
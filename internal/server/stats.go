@@ -0,0 +1,122 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+
+	"gowebdavd/internal/logger"
+)
+
+const statsPath = "/stats"
+
+// stats is the JSON document served on the stats endpoint
+type stats struct {
+	LogDropped uint64 `json:"log_dropped"`
+	// runtimeStats is only reported with Options.Stats
+	*runtimeStats
+}
+
+type runtimeStats struct {
+	Requests         uint64            `json:"requests"`
+	RequestsByMethod map[string]uint64 `json:"requests_by_method"`
+	Goroutines       int               `json:"goroutines"`
+	BytesServed      uint64            `json:"bytes_served"`
+	UptimeSeconds    float64           `json:"uptime_seconds"`
+}
+
+// requestStats counts the requests answered by the handler chain since the
+// server started, for the stats endpoint
+type requestStats struct {
+	start time.Time
+
+	mu       sync.Mutex
+	requests uint64
+	byMethod map[string]uint64
+	bytesOut uint64
+}
+
+func newRequestStats() *requestStats {
+	return &requestStats{start: time.Now(), byMethod: make(map[string]uint64)}
+}
+
+// middleware records every request answered by next
+func (s *requestStats) middleware(next http.Handler) http.Handler {
+	return logger.Observe(next, s.observe)
+}
+
+func (s *requestStats) observe(r *http.Request, resp logger.Response) {
+	method := r.Method
+	if !metricMethods[method] {
+		method = "OTHER"
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	s.byMethod[method]++
+	s.bytesOut += uint64(resp.BytesWritten)
+}
+
+func (s *requestStats) snapshot() *runtimeStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	byMethod := make(map[string]uint64, len(s.byMethod))
+	for m, n := range s.byMethod {
+		byMethod[m] = n
+	}
+	return &runtimeStats{
+		Requests:         s.requests,
+		RequestsByMethod: byMethod,
+		Goroutines:       runtime.NumGoroutine(),
+		BytesServed:      s.bytesOut,
+		UptimeSeconds:    time.Since(s.start).Seconds(),
+	}
+}
+
+// statsHandler reports runtime counters as JSON, including the request
+// counters of rs when it is not nil
+func statsHandler(log *logger.Logger, rs *requestStats) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		doc := stats{LogDropped: log.Dropped()}
+		if rs != nil {
+			doc.runtimeStats = rs.snapshot()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(doc)
+	}
+}
+
+// protectStats puts the stats or metrics handler h behind the
+// authentication of the served files, unless the server only listens on loopback addresses.
+// Without credentials, h is refused if required is set and served as is
+// otherwise. Digest nonces expire after nonceTTL.
+func protectStats(h http.Handler, opts Options, nonceTTL time.Duration, required bool) (http.Handler, error) {
+	if isLoopbackBind(opts.Bind) {
+		return h, nil
+	}
+	switch {
+	case opts.DigestAuth:
+		return digestAuth(h, opts.Credentials, nonceTTL), nil
+	case len(opts.Credentials) > 0:
+		return basicAuth(h, opts.Credentials), nil
+	case !required:
+		return h, nil
+	}
+	return nil, fmt.Errorf("the stats endpoint on %q needs credentials; bind it to a loopback address or configure authentication", opts.Bind)
+}
+
+// isLoopbackBind reports whether bind only accepts local connections
+func isLoopbackBind(bind string) bool {
+	if bind == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(bind)
+	return ip != nil && ip.IsLoopback()
+}
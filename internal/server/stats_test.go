@@ -0,0 +1,135 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gowebdavd/internal/logger"
+)
+
+func TestStatsDisabledByDefault(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "stats"), []byte("a file"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	srv := New(dir, 18080, "0.0.0.0", logger.NewWithWriter(io.Discard, true))
+
+	// The path is served from the directory like any other
+	rec := doRequest(srv.routes(), http.MethodGet, "/stats", "", nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != "a file" {
+		t.Errorf("GET /stats = %d %q, want the file", rec.Code, rec.Body.String())
+	}
+}
+
+// stalledWriter blocks every write until release is closed
+type stalledWriter struct{ release chan struct{} }
+
+func (w stalledWriter) Write(p []byte) (int, error) {
+	<-w.release
+	return len(p), nil
+}
+
+func TestStatsReportsLogDrops(t *testing.T) {
+	w := stalledWriter{release: make(chan struct{})}
+	log := logger.NewWithWriter(w, true)
+	log.StartAsync(1)
+	defer log.Close()
+	defer close(w.release)
+	srv := New(t.TempDir(), 18080, "127.0.0.1", log)
+
+	for i := 0; i < 50; i++ {
+		doRequest(srv.routes(), http.MethodGet, "/", "", nil)
+	}
+
+	rec := doRequest(srv.routes(), http.MethodGet, "/stats", "", nil)
+	var got stats
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("GET /stats body is not JSON: %v", err)
+	}
+	if got.LogDropped == 0 {
+		t.Error("log_dropped = 0, want entries dropped while the writer is stalled")
+	}
+}
+
+func TestStatsCounters(t *testing.T) {
+	srv, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18080, Bind: "127.0.0.1", Stats: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := srv.routes()
+	doRequest(h, http.MethodPut, "/a.txt", "hello", nil)
+	doRequest(h, http.MethodGet, "/a.txt", "", nil)
+	doRequest(h, http.MethodGet, "/a.txt", "", nil)
+	doRequest(h, "BREW", "/a.txt", "", nil)
+	doRequest(h, http.MethodGet, "/health", "", nil)
+
+	rec := doRequest(h, http.MethodGet, "/stats", "", nil)
+	var got runtimeStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("GET /stats body is not JSON: %v", err)
+	}
+	if got.Requests != 4 {
+		t.Errorf("requests = %d, want 4", got.Requests)
+	}
+	want := map[string]uint64{"PUT": 1, "GET": 2, "OTHER": 1}
+	for m, n := range want {
+		if got.RequestsByMethod[m] != n {
+			t.Errorf("requests_by_method[%s] = %d, want %d (%v)", m, got.RequestsByMethod[m], n, got.RequestsByMethod)
+		}
+	}
+	if got.BytesServed < 10 {
+		t.Errorf("bytes_served = %d, want at least the two downloads", got.BytesServed)
+	}
+	if got.Goroutines <= 0 || got.UptimeSeconds <= 0 {
+		t.Errorf("goroutines = %d, uptime_seconds = %v, want positive", got.Goroutines, got.UptimeSeconds)
+	}
+}
+
+func TestStatsCountersOmittedByDefault(t *testing.T) {
+	log := logger.NewWithWriter(io.Discard, true)
+	log.StartAsync(16)
+	defer log.Close()
+	srv := New(t.TempDir(), 18080, "127.0.0.1", log)
+	rec := doRequest(srv.routes(), http.MethodGet, "/stats", "", nil)
+	var got map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("GET /stats body is not JSON: %v", err)
+	}
+	if _, ok := got["requests"]; ok {
+		t.Errorf("GET /stats = %v, want no counters without Stats", got)
+	}
+}
+
+func TestStatsRequiresAuthOffLoopback(t *testing.T) {
+	if _, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18080, Bind: "0.0.0.0", Stats: true}, nil); err == nil {
+		t.Error("NewWithOptions() accepted Stats on 0.0.0.0 without credentials")
+	}
+
+	srv, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18080, Bind: "0.0.0.0", Stats: true, Credentials: Credentials{"alice": "secret"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := doRequest(srv.routes(), http.MethodGet, "/stats", "", nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET /stats without credentials status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/stats", nil)
+	req.SetBasicAuth("alice", "secret")
+	rec := httptest.NewRecorder()
+	srv.routes().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET /stats with credentials status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestIsLoopbackBind(t *testing.T) {
+	for bind, want := range map[string]bool{"127.0.0.1": true, "::1": true, "localhost": true, "0.0.0.0": false, "": false, "192.168.1.2": false} {
+		if got := isLoopbackBind(bind); got != want {
+			t.Errorf("isLoopbackBind(%q) = %v, want %v", bind, got, want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+)
+
+// statusError is returned by FileSystem wrappers that need a specific
+// response status, which the WebDAV handler cannot derive from an error.
+// statusErrors turns it into the response.
+type statusError struct {
+	status int
+	msg    string
+	err    error
+}
+
+// newStatusError returns an error answered with status and msg, wrapping
+// err when it is not nil
+func newStatusError(status int, msg string, err error) *statusError {
+	return &statusError{status: status, msg: msg, err: err}
+}
+
+func (e *statusError) Error() string {
+	if e.err != nil {
+		return e.msg + ": " + e.err.Error()
+	}
+	return e.msg
+}
+
+func (e *statusError) Unwrap() error {
+	return e.err
+}
+
+// statusErrors replaces the error response of the WebDAV handler with the
+// status and message of a statusError behind it. Other responses pass
+// unchanged.
+func statusErrors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Share the slot with errorDetail, so it still sees the error
+		slot, ok := r.Context().Value(errorSlotKey{}).(*errorSlot)
+		if !ok {
+			slot = &errorSlot{}
+			r = r.WithContext(context.WithValue(r.Context(), errorSlotKey{}, slot))
+		}
+		sw := &statusErrorWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r)
+		if sw.status == 0 {
+			return
+		}
+
+		var se *statusError
+		if errors.As(slot.err, &se) {
+			w.Header().Del("Content-Length")
+			http.Error(w, se.msg, se.status)
+			return
+		}
+		w.WriteHeader(sw.status)
+		w.Write(sw.body.Bytes())
+	})
+}
+
+// statusErrorWriter holds back error responses until the error behind them
+// is known
+type statusErrorWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *statusErrorWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if code >= 400 {
+		w.status = code
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusErrorWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.status != 0 {
+		return w.body.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
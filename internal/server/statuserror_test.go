@@ -0,0 +1,60 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/webdav"
+	"gowebdavd/internal/logger"
+)
+
+// fullFS rejects new files as if the storage were exhausted
+type fullFS struct {
+	webdav.FileSystem
+}
+
+func (fs fullFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	if flag&os.O_CREATE != 0 {
+		return nil, newStatusError(http.StatusInsufficientStorage, "Insufficient Storage: quota exceeded", errors.New("10 of 10 bytes used"))
+	}
+	return fs.FileSystem.OpenFile(ctx, name, flag, perm)
+}
+
+func TestStatusErrors(t *testing.T) {
+	var buf bytes.Buffer
+	h := statusErrors(&webdav.Handler{
+		FileSystem: fullFS{FileSystem: webdav.Dir(t.TempDir())},
+		LockSystem: webdav.NewMemLS(),
+		Logger:     recordError,
+	})
+	h = errorDetail(h, false, logger.NewWithWriter(&buf, true))
+
+	rec := doRequest(h, http.MethodPut, "/file.txt", "data", nil)
+	if rec.Code != http.StatusInsufficientStorage {
+		t.Fatalf("PUT status = %d, want %d", rec.Code, http.StatusInsufficientStorage)
+	}
+	if !strings.Contains(rec.Body.String(), "quota exceeded") {
+		t.Errorf("body = %q, want the status error message", rec.Body.String())
+	}
+	if strings.Contains(rec.Body.String(), "bytes used") {
+		t.Errorf("body = %q, should not reveal the wrapped error", rec.Body.String())
+	}
+	if !strings.Contains(buf.String(), "PUT /file.txt 507 error: Insufficient Storage: quota exceeded: 10 of 10 bytes used") {
+		t.Errorf("error not logged:\n%s", buf.String())
+	}
+
+	// Errors without a status keep the handler's response
+	rec = doRequest(h, http.MethodGet, "/missing.txt", "", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	rec = doRequest(h, "MKCOL", "/dir", "", nil)
+	if rec.Code != http.StatusCreated {
+		t.Errorf("MKCOL status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/net/webdav"
+)
+
+// errSymlinkEscape rejects names leading outside the served directory. It
+// wraps os.ErrPermission, so PROPFIND leaves such members out.
+var errSymlinkEscape = newStatusError(http.StatusForbidden, "Forbidden: symbolic link leads outside the served directory", os.ErrPermission)
+
+// symlinkFS serves a directory like webdav.Dir, but rejects every name that
+// resolves through symbolic links to a path outside the directory, and
+// leaves such entries out of collection listings. Dangling links are
+// rejected too, as creating a file through one could write anywhere.
+type symlinkFS struct {
+	webdav.Dir
+	// root is the directory with its own symbolic links resolved
+	root string
+}
+
+func newSymlinkFS(dir webdav.Dir) symlinkFS {
+	root, err := filepath.EvalSymlinks(string(dir))
+	if err != nil {
+		root = string(dir)
+	}
+	return symlinkFS{Dir: dir, root: root}
+}
+
+// check returns errSymlinkEscape when name does not resolve to a path inside
+// the root. Names that do not exist yet, such as a PUT target, are checked
+// through their nearest existing ancestor.
+func (fs symlinkFS) check(name string) error {
+	p := filepath.Join(fs.root, filepath.FromSlash(path.Clean("/"+name)))
+	for {
+		real, err := filepath.EvalSymlinks(p)
+		if err == nil {
+			if within(fs.root, real) {
+				return nil
+			}
+			return errSymlinkEscape
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if _, lerr := os.Lstat(p); lerr == nil {
+			// p exists but does not resolve: a dangling link
+			return errSymlinkEscape
+		}
+		parent := filepath.Dir(p)
+		if parent == p {
+			return err
+		}
+		p = parent
+	}
+}
+
+// within reports whether p is root or below it
+func within(root, p string) bool {
+	rel, err := filepath.Rel(root, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func (fs symlinkFS) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	if err := fs.check(name); err != nil {
+		return err
+	}
+	return fs.Dir.Mkdir(ctx, name, perm)
+}
+
+func (fs symlinkFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	if err := fs.check(name); err != nil {
+		return nil, err
+	}
+	f, err := fs.Dir.OpenFile(ctx, name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &symlinkFile{File: f, fs: fs, name: name}, nil
+}
+
+func (fs symlinkFS) RemoveAll(ctx context.Context, name string) error {
+	if err := fs.check(name); err != nil {
+		return err
+	}
+	return fs.Dir.RemoveAll(ctx, name)
+}
+
+func (fs symlinkFS) Rename(ctx context.Context, oldName, newName string) error {
+	if err := fs.check(oldName); err != nil {
+		return err
+	}
+	if err := fs.check(newName); err != nil {
+		return err
+	}
+	return fs.Dir.Rename(ctx, oldName, newName)
+}
+
+func (fs symlinkFS) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	if err := fs.check(name); err != nil {
+		return nil, err
+	}
+	return fs.Dir.Stat(ctx, name)
+}
+
+// symlinkFile leaves entries leading outside the root out of Readdir results
+type symlinkFile struct {
+	webdav.File
+	fs   symlinkFS
+	name string
+}
+
+func (f *symlinkFile) Readdir(count int) ([]os.FileInfo, error) {
+	for {
+		entries, err := f.File.Readdir(count)
+		visible := entries[:0]
+		for _, e := range entries {
+			if f.fs.check(path.Join(f.name, e.Name())) == nil {
+				visible = append(visible, e)
+			}
+		}
+		// A positive count must yield at least one entry unless the end is reached
+		if count <= 0 || len(visible) > 0 || err != nil {
+			return visible, err
+		}
+	}
+}
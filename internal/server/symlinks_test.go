@@ -0,0 +1,94 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestNoSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symbolic links requires privileges on Windows")
+	}
+
+	root := t.TempDir()
+	outside := t.TempDir()
+	os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644)
+	os.WriteFile(filepath.Join(root, "file.txt"), []byte("visible"), 0644)
+	os.Mkdir(filepath.Join(root, "dir"), 0755)
+	for link, target := range map[string]string{
+		"escape":   outside,
+		"secret":   filepath.Join(outside, "secret.txt"),
+		"dangling": filepath.Join(outside, "missing.txt"),
+		"inside":   filepath.Join(root, "dir"),
+	} {
+		if err := os.Symlink(target, filepath.Join(root, link)); err != nil {
+			t.Fatalf("Symlink() error = %v", err)
+		}
+	}
+
+	srv, err := NewWithOptions(Options{Folder: root, Port: 18080, Bind: "127.0.0.1", NoSymlinks: true}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	h := srv.Handler()
+
+	tests := []struct {
+		method, target, body string
+		want                 int
+	}{
+		{http.MethodGet, "/file.txt", "", http.StatusOK},
+		{http.MethodGet, "/secret", "", http.StatusForbidden},
+		{http.MethodGet, "/escape/secret.txt", "", http.StatusForbidden},
+		{http.MethodPut, "/escape/new.txt", "data", http.StatusForbidden},
+		{http.MethodPut, "/dangling", "data", http.StatusForbidden},
+		{"MKCOL", "/escape/sub", "", http.StatusForbidden},
+		{"PROPFIND", "/escape/", "", http.StatusForbidden},
+		{http.MethodPut, "/inside/new.txt", "data", http.StatusCreated},
+		{http.MethodPut, "/new.txt", "data", http.StatusCreated},
+	}
+	for _, tt := range tests {
+		rec := doRequest(h, tt.method, tt.target, tt.body, map[string]string{"Depth": "1"})
+		if rec.Code != tt.want {
+			t.Errorf("%s %s status = %d, want %d", tt.method, tt.target, rec.Code, tt.want)
+		}
+	}
+	for _, name := range []string{"new.txt", "missing.txt"} {
+		if _, err := os.Stat(filepath.Join(outside, name)); !os.IsNotExist(err) {
+			t.Errorf("%s was created outside the served directory", name)
+		}
+	}
+
+	rec := doRequest(h, "PROPFIND", "/", "", map[string]string{"Depth": "infinity"})
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("PROPFIND status = %d, want %d", rec.Code, http.StatusMultiStatus)
+	}
+	body := rec.Body.String()
+	for _, hidden := range []string{"escape", "secret", "dangling"} {
+		if strings.Contains(body, "/"+hidden+"<") || strings.Contains(body, "/"+hidden+"/<") {
+			t.Errorf("PROPFIND lists %s:\n%s", hidden, body)
+		}
+	}
+	if !strings.Contains(body, "/inside/") || !strings.Contains(body, "/file.txt") {
+		t.Errorf("PROPFIND should list entries inside the directory:\n%s", body)
+	}
+}
+
+func TestSymlinksFollowedByDefault(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symbolic links requires privileges on Windows")
+	}
+
+	root := t.TempDir()
+	outside := t.TempDir()
+	os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644)
+	os.Symlink(outside, filepath.Join(root, "escape"))
+
+	srv := New(root, 18080, "127.0.0.1", nil)
+	if rec := doRequest(srv.Handler(), http.MethodGet, "/escape/secret.txt", "", nil); rec.Code != http.StatusOK {
+		t.Errorf("GET through a symlink status = %d, want %d without -no-symlinks", rec.Code, http.StatusOK)
+	}
+}
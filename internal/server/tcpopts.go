@@ -0,0 +1,26 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import "net"
+
+// tcpOptionsListener applies socket options to every accepted TCP connection
+type tcpOptionsListener struct {
+	net.Listener
+	noDelay bool
+}
+
+// Accept waits for the next connection and configures Nagle's algorithm on it.
+// With noDelay small WebDAV requests (PROPFIND, LOCK) are sent immediately;
+// without it, small writes are coalesced, which favours bulk throughput.
+func (l *tcpOptionsListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if tc, ok := conn.(*net.TCPConn); ok {
+		tc.SetNoDelay(l.noDelay)
+	}
+	return conn, nil
+}
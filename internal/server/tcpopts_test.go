@@ -0,0 +1,54 @@
+package server
+
+import (
+	"io"
+	"net"
+	"testing"
+)
+
+func TestTCPOptionsListener(t *testing.T) {
+	for _, noDelay := range []bool{true, false} {
+		inner, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Listen() error = %v", err)
+		}
+		listener := &tcpOptionsListener{Listener: inner, noDelay: noDelay}
+
+		go func() {
+			conn, err := net.Dial("tcp", inner.Addr().String())
+			if err == nil {
+				conn.Write([]byte("ping"))
+				conn.Close()
+			}
+		}()
+
+		conn, err := listener.Accept()
+		if err != nil {
+			t.Fatalf("Accept() error = %v", err)
+		}
+		if _, ok := conn.(*net.TCPConn); !ok {
+			t.Errorf("Accept() returned %T, want *net.TCPConn", conn)
+		}
+		data, _ := io.ReadAll(conn)
+		if string(data) != "ping" {
+			t.Errorf("read %q through wrapped connection, want %q", data, "ping")
+		}
+		conn.Close()
+		listener.Close()
+	}
+}
+
+func TestNewWithOptions_TCPNoDelay(t *testing.T) {
+	srv := New(t.TempDir(), 18080, "127.0.0.1", nil)
+	if !srv.tcpNoDelay {
+		t.Error("TCP_NODELAY should be enabled by default")
+	}
+
+	srv, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18080, Bind: "127.0.0.1", DisableTCPNoDelay: true}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	if srv.tcpNoDelay {
+		t.Error("DisableTCPNoDelay should re-enable Nagle's algorithm")
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// requestTimeout answers 503 to requests whose handler has not started its
+// response within timeout, and bounds the reading of their bodies by the
+// same deadline, so a client stalling mid-upload cannot hold a connection.
+// PUT requests get uploadTimeout instead, as large uploads legitimately run
+// long; 0 exempts them. Unlike http.TimeoutHandler it does not buffer the
+// response, so downloads that started in time are not cut off.
+func requestTimeout(next http.Handler, timeout, uploadTimeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d := timeout
+		if r.Method == http.MethodPut {
+			d = uploadTimeout
+		}
+		if d <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Unblocks body reads; not every ResponseWriter supports it
+		http.NewResponseController(w).SetReadDeadline(time.Now().Add(d))
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		tw := &timeoutWriter{w: w, header: make(http.Header)}
+		done := make(chan struct{})
+		panicked := make(chan any, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicked <- p
+				}
+				close(done)
+			}()
+			next.ServeHTTP(tw, r.WithContext(ctx))
+		}()
+
+		select {
+		case <-done:
+		case <-timer.C:
+			tw.mu.Lock()
+			if !tw.wroteHeader {
+				// Mark the timeout before the handler learns of it
+				tw.timedOut = true
+				tw.mu.Unlock()
+				cancel()
+				http.Error(w, "Service Unavailable: request timed out", http.StatusServiceUnavailable)
+				return
+			}
+			tw.mu.Unlock()
+			// The response is under way and finishes on its own
+			<-done
+		}
+		select {
+		case p := <-panicked:
+			panic(p)
+		default:
+		}
+	})
+}
+
+// timeoutWriter keeps the handler's header apart from the real one until
+// the response starts, so a handler still running after a timeout cannot
+// touch the 503 response
+type timeoutWriter struct {
+	w           http.ResponseWriter
+	header      http.Header
+	mu          sync.Mutex
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+	dst := tw.w.Header()
+	for k, v := range tw.header {
+		dst[k] = v
+	}
+	tw.w.WriteHeader(code)
+}
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.WriteHeader(http.StatusOK)
+	tw.mu.Lock()
+	timedOut := tw.timedOut
+	tw.mu.Unlock()
+	if timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	return tw.w.Write(p)
+}
+
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter {
+	return tw.w
+}
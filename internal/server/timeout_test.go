@@ -0,0 +1,114 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+		w.Header().Set("X-Late", "1")
+		w.Write([]byte("late"))
+	})
+	h := requestTimeout(slow, 20*time.Millisecond, 0)
+
+	rec := doRequest(h, http.MethodGet, "/", "", nil)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("slow GET status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if rec.Header().Get("X-Late") != "" || strings.Contains(rec.Body.String(), "late") {
+		t.Error("the handler wrote to the response after the timeout")
+	}
+
+	// PUT is exempt without an upload timeout
+	start := time.Now()
+	exempt := requestTimeout(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusCreated)
+	}), 20*time.Millisecond, 0)
+	if rec := doRequest(exempt, http.MethodPut, "/", "data", nil); rec.Code != http.StatusCreated {
+		t.Errorf("PUT without upload timeout status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if time.Since(start) < 50*time.Millisecond {
+		t.Error("PUT returned before its handler finished")
+	}
+
+	h = requestTimeout(slow, 0, 20*time.Millisecond)
+	if rec := doRequest(h, http.MethodPut, "/", "data", nil); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("slow PUT status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	fast := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+	})
+	if rec := doRequest(requestTimeout(fast, 0, 20*time.Millisecond), http.MethodGet, "/", "", nil); rec.Code != http.StatusOK {
+		t.Errorf("GET with only an upload timeout status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestRequestTimeoutStartedResponse(t *testing.T) {
+	// A response under way at the deadline is not cut off
+	streaming := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("first "))
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("second"))
+	})
+	rec := doRequest(requestTimeout(streaming, 20*time.Millisecond, 0), http.MethodGet, "/", "", nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != "first second" {
+		t.Errorf("streaming GET = %d %q, want the full response", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/plain" {
+		t.Errorf("Content-Type = %q, want the handler's header", ct)
+	}
+}
+
+func TestRequestTimeoutStalledUpload(t *testing.T) {
+	srv, err := NewWithOptions(Options{Folder: t.TempDir(), Bind: "127.0.0.1", RequestTimeout: 100 * time.Millisecond, UploadTimeout: 200 * time.Millisecond}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	url, done := startTestServer(t, srv)
+	defer func() {
+		srv.shutdown()
+		waitServe(t, done)
+	}()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+	// Announce 100 bytes, send 10 and stall
+	fmt.Fprintf(conn, "PUT /stalled.txt HTTP/1.1\r\nHost: x\r\nContent-Length: 100\r\n\r\n0123456789")
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("stalled PUT got no response: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 400 {
+		t.Errorf("stalled PUT status = %d, want an error", resp.StatusCode)
+	}
+
+	// The health endpoint is not subject to the timeout
+	health, err := http.Get(url + healthPath)
+	if err != nil {
+		t.Fatalf("GET %s error = %v", healthPath, err)
+	}
+	health.Body.Close()
+	if health.StatusCode != http.StatusOK {
+		t.Errorf("GET %s status = %d, want %d", healthPath, health.StatusCode, http.StatusOK)
+	}
+}
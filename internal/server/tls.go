@@ -0,0 +1,114 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// selfSignedValidity is how long a generated certificate stays valid
+const selfSignedValidity = 365 * 24 * time.Hour
+
+// loadTLSConfig validates the certificate and key files and returns a TLS
+// configuration serving them
+func loadTLSConfig(certFile, keyFile string) (*tls.Config, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("both a TLS certificate and key are required")
+	}
+	for _, path := range []string{certFile, keyFile} {
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("failed to access TLS file: %w", err)
+		}
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS key pair: %w", err)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// selfSignedTLSConfig generates an in-memory ECDSA certificate valid for bind,
+// localhost and 127.0.0.1. The key never touches the disk, so every run gets
+// a fresh one.
+func selfSignedTLSConfig(bind string) (*tls.Config, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate TLS key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate certificate serial: %w", err)
+	}
+
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "gowebdavd self-signed"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	for _, host := range certHosts(bind) {
+		if ip := net.ParseIP(host); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create self-signed certificate: %w", err)
+	}
+	fmt.Printf("TLS self-signed certificate SHA-256 fingerprint: %s\n", fingerprint(der))
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// certHosts returns the names a self-signed certificate is issued for.
+// Wildcard bind addresses are not meaningful names and are skipped.
+func certHosts(bind string) []string {
+	hosts := []string{"localhost", "127.0.0.1"}
+	if ip := net.ParseIP(bind); bind == "" || (ip != nil && ip.IsUnspecified()) {
+		return hosts
+	}
+	for _, h := range hosts {
+		if strings.EqualFold(h, bind) {
+			return hosts
+		}
+	}
+	return append([]string{bind}, hosts...)
+}
+
+// fingerprint formats the SHA-256 digest of a DER certificate as colon
+// separated hex pairs, the form most clients show when pinning
+func fingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":")
+}
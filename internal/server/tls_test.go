@@ -0,0 +1,178 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestKeyPair writes a self-signed certificate and its key to dir
+func writeTestKeyPair(t *testing.T, dir string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey() error = %v", err)
+	}
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	return certFile, keyFile
+}
+
+func TestServeTLS(t *testing.T) {
+	certFile, keyFile := writeTestKeyPair(t, t.TempDir())
+	srv, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18443, Bind: "127.0.0.1", TLSCert: certFile, TLSKey: keyFile}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+
+	if srv.URL() != "https://127.0.0.1:18443" {
+		t.Errorf("URL() = %s, want https://127.0.0.1:18443", srv.URL())
+	}
+
+	url, done := startTestServer(t, srv)
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+	resp, err := client.Get(strings.Replace(url, "http://", "https://", 1) + "/health")
+	if err != nil {
+		t.Fatalf("GET over TLS error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.TLS == nil {
+		t.Errorf("GET over TLS = %d (TLS: %v), want 200 over TLS", resp.StatusCode, resp.TLS != nil)
+	}
+	client.CloseIdleConnections()
+
+	srv.shutdown()
+	waitServe(t, done)
+}
+
+func TestLoadTLSConfigErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	certFile, keyFile := writeTestKeyPair(t, tmpDir)
+	otherCert, _ := writeTestKeyPair(t, t.TempDir())
+
+	tests := []struct {
+		name string
+		cert string
+		key  string
+	}{
+		{name: "missing key flag", cert: certFile},
+		{name: "missing cert flag", key: keyFile},
+		{name: "nonexistent file", cert: filepath.Join(tmpDir, "missing.pem"), key: keyFile},
+		{name: "mismatched pair", cert: otherCert, key: keyFile},
+		{name: "not a certificate", cert: keyFile, key: keyFile},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := loadTLSConfig(tt.cert, tt.key); err == nil {
+				t.Error("loadTLSConfig() should fail")
+			}
+		})
+	}
+}
+
+func TestURLPlainHTTP(t *testing.T) {
+	srv := New(t.TempDir(), 8080, "127.0.0.1", nil)
+	if srv.URL() != "http://127.0.0.1:8080" {
+		t.Errorf("URL() = %s, want http://127.0.0.1:8080", srv.URL())
+	}
+}
+
+func TestSelfSignedTLSConfig(t *testing.T) {
+	config, err := selfSignedTLSConfig("files.example.com")
+	if err != nil {
+		t.Fatalf("selfSignedTLSConfig() error = %v", err)
+	}
+	if len(config.Certificates) != 1 {
+		t.Fatalf("selfSignedTLSConfig() returned %d certificates, want 1", len(config.Certificates))
+	}
+	cert, err := x509.ParseCertificate(config.Certificates[0].Certificate[0])
+	if err != nil {
+		t.Fatalf("ParseCertificate() error = %v", err)
+	}
+	for _, host := range []string{"files.example.com", "localhost", "127.0.0.1"} {
+		if err := cert.VerifyHostname(host); err != nil {
+			t.Errorf("certificate not valid for %s: %v", host, err)
+		}
+	}
+
+	again, err := selfSignedTLSConfig("files.example.com")
+	if err != nil {
+		t.Fatalf("selfSignedTLSConfig() error = %v", err)
+	}
+	if fingerprint(again.Certificates[0].Certificate[0]) == fingerprint(cert.Raw) {
+		t.Error("each call should generate a new certificate")
+	}
+}
+
+func TestCertHosts(t *testing.T) {
+	tests := []struct {
+		bind string
+		want int
+	}{
+		{bind: "", want: 2},
+		{bind: "0.0.0.0", want: 2},
+		{bind: "::", want: 2},
+		{bind: "127.0.0.1", want: 2},
+		{bind: "LOCALHOST", want: 2},
+		{bind: "192.168.1.5", want: 3},
+	}
+	for _, tt := range tests {
+		if got := certHosts(tt.bind); len(got) != tt.want {
+			t.Errorf("certHosts(%q) = %v, want %d names", tt.bind, got, tt.want)
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	got := fingerprint([]byte("certificate"))
+	if len(got) != 32*3-1 || strings.Count(got, ":") != 31 {
+		t.Errorf("fingerprint() = %q, want 32 colon separated hex pairs", got)
+	}
+	if got != strings.ToUpper(got) {
+		t.Errorf("fingerprint() = %q, want upper case hex", got)
+	}
+}
+
+func TestSelfSignedConflictsWithKeyPair(t *testing.T) {
+	certFile, keyFile := writeTestKeyPair(t, t.TempDir())
+	_, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18443, Bind: "127.0.0.1", TLSCert: certFile, TLSKey: keyFile, TLSSelfSigned: true}, nil)
+	if err == nil {
+		t.Error("NewWithOptions() should reject -tls-self-signed with a key pair")
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"gowebdavd/internal/logger"
+)
+
+// DefaultWebhookMethods are the methods reported to a webhook unless
+// Options.WebhookMethods names others
+var DefaultWebhookMethods = []string{http.MethodPut, http.MethodDelete, "MOVE", "MKCOL"}
+
+// webhookChangeMethods lists the methods a webhook can report
+var webhookChangeMethods = []string{http.MethodPut, http.MethodDelete, "MOVE", "COPY", "MKCOL", "PROPPATCH"}
+
+const (
+	// webhookAttempts is how often an event is posted before it is given up
+	webhookAttempts = 3
+	// webhookBackoff is the wait before the first retry, doubled for each
+	// following one
+	webhookBackoff = time.Second
+	// webhookTimeout bounds a single post
+	webhookTimeout = 10 * time.Second
+	// webhookMaxPending bounds the events being delivered; further events
+	// are dropped until deliveries finish
+	webhookMaxPending = 64
+)
+
+// ParseWebhookMethods parses a comma-separated list of methods to report to
+// a webhook, such as "PUT,DELETE"
+func ParseWebhookMethods(s string) ([]string, error) {
+	var methods []string
+	for _, item := range strings.Split(s, ",") {
+		m := strings.ToUpper(strings.TrimSpace(item))
+		if m == "" {
+			continue
+		}
+		if !slices.Contains(webhookChangeMethods, m) {
+			return nil, fmt.Errorf("method %q does not change files (want one of %s)", item, strings.Join(webhookChangeMethods, ", "))
+		}
+		if !slices.Contains(methods, m) {
+			methods = append(methods, m)
+		}
+	}
+	return methods, nil
+}
+
+// validateWebhookURL checks that raw is an absolute http or https URL
+func validateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook URL %q: want an http or https URL", raw)
+	}
+	return nil
+}
+
+// webhookEvent is the JSON body posted for a change
+type webhookEvent struct {
+	Method      string    `json:"method"`
+	Path        string    `json:"path"`
+	Destination string    `json:"destination,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+	// Size is the number of bytes a PUT stored
+	Size *int64 `json:"size,omitempty"`
+}
+
+// webhook posts an event to a URL after every successful request with one
+// of its methods. Events are delivered in the background with retries, so
+// clients never wait for the receiver.
+type webhook struct {
+	url     string
+	methods []string
+	client  *http.Client
+	backoff time.Duration
+	pending chan struct{}
+	logger  *logger.Logger
+}
+
+func newWebhook(url string, methods []string, log *logger.Logger) *webhook {
+	if len(methods) == 0 {
+		methods = DefaultWebhookMethods
+	}
+	return &webhook{
+		url:     url,
+		methods: methods,
+		client:  &http.Client{Timeout: webhookTimeout},
+		backoff: webhookBackoff,
+		pending: make(chan struct{}, webhookMaxPending),
+		logger:  log,
+	}
+}
+
+// middleware reports the requests with a webhook method that succeed
+func (h *webhook) middleware(next http.Handler) http.Handler {
+	observed := logger.Observe(next, h.observe)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !slices.Contains(h.methods, r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		observed.ServeHTTP(w, r)
+	})
+}
+
+func (h *webhook) observe(r *http.Request, resp logger.Response) {
+	if resp.Status < 200 || resp.Status > 299 {
+		return
+	}
+	ev := webhookEvent{Method: r.Method, Path: r.URL.Path, Timestamp: time.Now().UTC()}
+	if r.Method == "MOVE" || r.Method == "COPY" {
+		ev.Destination = destinationPath(r)
+	}
+	if r.Method == http.MethodPut {
+		ev.Size = &resp.BytesRead
+	}
+	h.send(ev)
+}
+
+// send delivers ev in the background, or drops it when too many deliveries
+// are pending
+func (h *webhook) send(ev webhookEvent) {
+	select {
+	case h.pending <- struct{}{}:
+	default:
+		logf(h.logger, "Warning: webhook deliveries backed up, dropping %s %s", ev.Method, ev.Path)
+		return
+	}
+	go func() {
+		defer func() { <-h.pending }()
+		if err := h.deliver(ev); err != nil {
+			logf(h.logger, "Warning: webhook for %s %s failed: %v", ev.Method, ev.Path, err)
+		}
+	}()
+}
+
+// deliver posts ev, retrying with a growing delay
+func (h *webhook) deliver(ev webhookEvent) error {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	backoff := h.backoff
+	for attempt := 1; ; attempt++ {
+		err = h.post(payload)
+		if err == nil || attempt == webhookAttempts {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (h *webhook) post(payload []byte) error {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, h.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("receiver answered %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// webhookReceiver collects the events posted to it
+func webhookReceiver(t *testing.T, status func(n int) int) (*httptest.Server, chan webhookEvent, *atomic.Int32) {
+	t.Helper()
+	events := make(chan webhookEvent, 16)
+	var posts atomic.Int32
+	recv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(posts.Add(1))
+		if code := status(n); code != http.StatusOK {
+			w.WriteHeader(code)
+			return
+		}
+		var ev webhookEvent
+		if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
+			t.Errorf("webhook body: %v", err)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("webhook Content-Type = %q", ct)
+		}
+		events <- ev
+	}))
+	t.Cleanup(recv.Close)
+	return recv, events, &posts
+}
+
+func webhookOK(int) int { return http.StatusOK }
+
+func waitEvent(t *testing.T, events chan webhookEvent) webhookEvent {
+	t.Helper()
+	select {
+	case ev := <-events:
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("no webhook event")
+		return webhookEvent{}
+	}
+}
+
+func TestWebhook_Changes(t *testing.T) {
+	recv, events, _ := webhookReceiver(t, webhookOK)
+	srv, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18080, WebhookURL: recv.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := srv.Handler()
+
+	req := httptest.NewRequest(http.MethodPut, "/a.txt", strings.NewReader("hello"))
+	req.ContentLength = -1
+	req.TransferEncoding = []string{"chunked"}
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	ev := waitEvent(t, events)
+	if ev.Method != http.MethodPut || ev.Path != "/a.txt" || ev.Size == nil || *ev.Size != 5 || ev.Timestamp.IsZero() {
+		t.Errorf("PUT event = %+v", ev)
+	}
+
+	doRequest(h, http.MethodGet, "/a.txt", "", nil)
+	doRequest(h, http.MethodPut, "/missing/b.txt", "x", nil)
+	doRequest(h, "MOVE", "/a.txt", "", map[string]string{"Destination": "/b.txt"})
+	ev = waitEvent(t, events)
+	if ev.Method != "MOVE" || ev.Path != "/a.txt" || ev.Destination != "/b.txt" || ev.Size != nil {
+		t.Errorf("MOVE event = %+v, want only the MOVE after GET and a failed PUT", ev)
+	}
+}
+
+func TestWebhook_Methods(t *testing.T) {
+	recv, events, _ := webhookReceiver(t, webhookOK)
+	methods, err := ParseWebhookMethods("put")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18080, WebhookURL: recv.URL, WebhookMethods: methods}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := srv.Handler()
+
+	doRequest(h, "MKCOL", "/dir", "", nil)
+	doRequest(h, http.MethodPut, "/dir/a.txt", "hi", nil)
+	if ev := waitEvent(t, events); ev.Method != http.MethodPut {
+		t.Errorf("event = %+v, want only PUT", ev)
+	}
+	select {
+	case ev := <-events:
+		t.Errorf("unexpected event %+v", ev)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWebhook_Retries(t *testing.T) {
+	recv, events, posts := webhookReceiver(t, func(n int) int {
+		if n < webhookAttempts {
+			return http.StatusServiceUnavailable
+		}
+		return http.StatusOK
+	})
+	hook := newWebhook(recv.URL, nil, nil)
+	hook.backoff = time.Millisecond
+	h := hook.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	start := time.Now()
+	doRequest(h, http.MethodDelete, "/a.txt", "", nil)
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("request waited %v for the webhook", d)
+	}
+	waitEvent(t, events)
+	if n := posts.Load(); n != webhookAttempts {
+		t.Errorf("posts = %d, want %d", n, webhookAttempts)
+	}
+}
+
+func TestWebhook_GivesUp(t *testing.T) {
+	recv, _, posts := webhookReceiver(t, func(int) int { return http.StatusInternalServerError })
+	hook := newWebhook(recv.URL, nil, nil)
+	hook.backoff = time.Millisecond
+	if err := hook.deliver(webhookEvent{Method: http.MethodPut, Path: "/a"}); err == nil {
+		t.Error("deliver() error = nil, want the receiver error")
+	}
+	if n := posts.Load(); n != webhookAttempts {
+		t.Errorf("posts = %d, want %d", n, webhookAttempts)
+	}
+}
+
+func TestParseWebhookMethods(t *testing.T) {
+	got, err := ParseWebhookMethods(" put, Delete,PUT ,")
+	if err != nil {
+		t.Fatalf("ParseWebhookMethods() error = %v", err)
+	}
+	if strings.Join(got, ",") != "PUT,DELETE" {
+		t.Errorf("ParseWebhookMethods() = %v, want [PUT DELETE]", got)
+	}
+	if _, err := ParseWebhookMethods("GET"); err == nil {
+		t.Error("ParseWebhookMethods(GET) error = nil, want error")
+	}
+}
+
+func TestNewWithOptions_InvalidWebhookURL(t *testing.T) {
+	for _, u := range []string{"ftp://example.com/", "/relative", "http://"} {
+		if _, err := NewWithOptions(Options{Folder: t.TempDir(), Port: 18080, WebhookURL: u}, nil); err == nil {
+			t.Errorf("NewWithOptions() accepted webhook URL %q", u)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright (c) 2026 gowebdavd contributors
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+// zipFS is a read-only webdav.FileSystem serving the contents of a zip
+// archive. Directories missing from the archive are synthesized from the
+// paths of the files inside them.
+type zipFS struct {
+	entries map[string]*zipEntry
+}
+
+// zipEntry is a file or directory of the archive
+type zipEntry struct {
+	info     os.FileInfo
+	file     *zip.File
+	children []os.FileInfo
+}
+
+// openZipFS indexes the archive at name. The archive stays open for the
+// lifetime of the process.
+func openZipFS(name string) (*zipFS, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip archive: %w", err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to open zip archive: %w", err)
+	}
+	r, err := zip.NewReader(f, fi.Size())
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("invalid zip archive %s: %w", name, err)
+	}
+	return newZipFS(r, fi.ModTime()), nil
+}
+
+// newZipFS indexes r, dating synthesized directories at modTime
+func newZipFS(r *zip.Reader, modTime time.Time) *zipFS {
+	fs := &zipFS{entries: map[string]*zipEntry{
+		"/": {info: zipDirInfo{name: "/", modTime: modTime}},
+	}}
+
+	// addDir creates name and its parents unless they already exist
+	var addDir func(name string)
+	addDir = func(name string) {
+		if _, ok := fs.entries[name]; ok {
+			return
+		}
+		parent := path.Dir(name)
+		addDir(parent)
+		info := zipDirInfo{name: path.Base(name), modTime: modTime}
+		fs.entries[name] = &zipEntry{info: info}
+		fs.entries[parent].children = append(fs.entries[parent].children, info)
+	}
+
+	for _, f := range r.File {
+		name := path.Clean("/" + f.Name)
+		if name == "/" {
+			continue
+		}
+		if strings.HasSuffix(f.Name, "/") {
+			addDir(name)
+			continue
+		}
+		if _, dup := fs.entries[name]; dup {
+			continue
+		}
+		parent := path.Dir(name)
+		addDir(parent)
+		info := f.FileInfo()
+		fs.entries[name] = &zipEntry{info: info, file: f}
+		fs.entries[parent].children = append(fs.entries[parent].children, info)
+	}
+
+	for _, e := range fs.entries {
+		sort.Slice(e.children, func(i, j int) bool { return e.children[i].Name() < e.children[j].Name() })
+	}
+	return fs
+}
+
+func (fs *zipFS) lookup(name string) (*zipEntry, error) {
+	e, ok := fs.entries[path.Clean("/"+name)]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return e, nil
+}
+
+func (fs *zipFS) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	return os.ErrPermission
+}
+
+func (fs *zipFS) RemoveAll(ctx context.Context, name string) error {
+	return os.ErrPermission
+}
+
+func (fs *zipFS) Rename(ctx context.Context, oldName, newName string) error {
+	return os.ErrPermission
+}
+
+func (fs *zipFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) != 0 {
+		return nil, os.ErrPermission
+	}
+	e, err := fs.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return &zipFile{entry: e}, nil
+}
+
+func (fs *zipFS) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	e, err := fs.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return e.info, nil
+}
+
+// zipDirInfo describes a directory of the archive
+type zipDirInfo struct {
+	name    string
+	modTime time.Time
+}
+
+func (i zipDirInfo) Name() string       { return i.name }
+func (i zipDirInfo) Size() int64        { return 0 }
+func (i zipDirInfo) Mode() os.FileMode  { return os.ModeDir | 0555 }
+func (i zipDirInfo) ModTime() time.Time { return i.modTime }
+func (i zipDirInfo) IsDir() bool        { return true }
+func (i zipDirInfo) Sys() any           { return nil }
+
+// zipFile is an open archive entry. Compressed entries cannot seek, so a
+// backwards seek reopens the entry and reads up to the new offset.
+type zipFile struct {
+	entry  *zipEntry
+	rc     io.ReadCloser
+	pos    int64 // position of rc
+	offset int64 // position requested by Seek
+	dirPos int
+}
+
+func (f *zipFile) Close() error {
+	if f.rc != nil {
+		return f.rc.Close()
+	}
+	return nil
+}
+
+func (f *zipFile) Read(p []byte) (int, error) {
+	if f.entry.file == nil {
+		return 0, errors.New("is a directory")
+	}
+	if f.rc == nil || f.offset < f.pos {
+		if f.rc != nil {
+			f.rc.Close()
+		}
+		rc, err := f.entry.file.Open()
+		if err != nil {
+			return 0, err
+		}
+		f.rc, f.pos = rc, 0
+	}
+	if f.offset > f.pos {
+		n, err := io.CopyN(io.Discard, f.rc, f.offset-f.pos)
+		f.pos += n
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.rc.Read(p)
+	f.pos += int64(n)
+	f.offset = f.pos
+	return n, err
+}
+
+func (f *zipFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.entry.info.Size()
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+func (f *zipFile) Readdir(count int) ([]os.FileInfo, error) {
+	if f.entry.file != nil {
+		return nil, errors.New("not a directory")
+	}
+	rest := f.entry.children[f.dirPos:]
+	if count <= 0 {
+		f.dirPos = len(f.entry.children)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if count > len(rest) {
+		count = len(rest)
+	}
+	f.dirPos += count
+	return rest[:count], nil
+}
+
+func (f *zipFile) Stat() (os.FileInfo, error) {
+	return f.entry.info, nil
+}
+
+func (f *zipFile) Write(p []byte) (int, error) {
+	return 0, os.ErrPermission
+}
+
+// forbidWrites rejects every method that could modify the archive with 403
+func forbidWrites(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, "PROPFIND":
+			next.ServeHTTP(w, r)
+		default:
+			http.Error(w, "Forbidden: zip archives are served read-only", http.StatusForbidden)
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"archive/zip"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestZip creates an archive with a file at the root, a file in an
+// implicit directory and a compressed file in an explicit directory
+func writeTestZip(t *testing.T) string {
+	t.Helper()
+
+	name := filepath.Join(t.TempDir(), "dataset.zip")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatalf("Failed to create zip: %v", err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	entries := []struct {
+		name    string
+		content string
+		method  uint16
+	}{
+		{name: "readme.txt", content: "read me", method: zip.Store},
+		{name: "data/a.csv", content: "id,value\n1,42\n", method: zip.Deflate},
+		{name: "docs/", method: zip.Store},
+		{name: "docs/guide.md", content: strings.Repeat("0123456789", 1000), method: zip.Deflate},
+	}
+	for _, e := range entries {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: e.name, Method: e.method})
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", e.name, err)
+		}
+		if _, err := w.Write([]byte(e.content)); err != nil {
+			t.Fatalf("Failed to write %s: %v", e.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to finish zip: %v", err)
+	}
+	return name
+}
+
+func newZipServer(t *testing.T) http.Handler {
+	t.Helper()
+
+	srv, err := NewWithOptions(Options{ZipFile: writeTestZip(t), Port: 18080, Bind: "127.0.0.1"}, nil)
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	return srv.Handler()
+}
+
+func TestZipPropfind(t *testing.T) {
+	h := newZipServer(t)
+
+	rec := doRequest(h, "PROPFIND", "/", "", map[string]string{"Depth": "1"})
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("PROPFIND / status = %d, want %d", rec.Code, http.StatusMultiStatus)
+	}
+	for _, href := range []string{"<D:href>/readme.txt</D:href>", "<D:href>/data/</D:href>", "<D:href>/docs/</D:href>"} {
+		if !strings.Contains(rec.Body.String(), href) {
+			t.Errorf("PROPFIND / missing %s:\n%s", href, rec.Body.String())
+		}
+	}
+
+	rec = doRequest(h, "PROPFIND", "/data/", "", map[string]string{"Depth": "1"})
+	if !strings.Contains(rec.Body.String(), "<D:href>/data/a.csv</D:href>") {
+		t.Errorf("PROPFIND /data/ missing a.csv:\n%s", rec.Body.String())
+	}
+}
+
+func TestZipGet(t *testing.T) {
+	h := newZipServer(t)
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{target: "/readme.txt", want: "read me"},
+		{target: "/data/a.csv", want: "id,value\n1,42\n"},
+		{target: "/docs/guide.md", want: strings.Repeat("0123456789", 1000)},
+	}
+	for _, tt := range tests {
+		rec := doRequest(h, http.MethodGet, tt.target, "", nil)
+		if rec.Code != http.StatusOK || rec.Body.String() != tt.want {
+			t.Errorf("GET %s = %d with %d bytes, want 200 with %d bytes", tt.target, rec.Code, rec.Body.Len(), len(tt.want))
+		}
+	}
+
+	rec := doRequest(h, http.MethodGet, "/docs/guide.md", "", map[string]string{"Range": "bytes=5003-5006"})
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "3456" {
+		t.Errorf("range GET = %d %q, want 206 %q", rec.Code, rec.Body.String(), "3456")
+	}
+
+	rec = doRequest(h, http.MethodGet, "/missing.txt", "", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET missing status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestZipWritesForbidden(t *testing.T) {
+	h := newZipServer(t)
+
+	for _, method := range []string{http.MethodPut, http.MethodDelete, "MKCOL", "MOVE", "COPY", "PROPPATCH", "LOCK"} {
+		rec := doRequest(h, method, "/readme.txt", "data", map[string]string{"Destination": "http://example.com/copy.txt"})
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s status = %d, want %d", method, rec.Code, http.StatusForbidden)
+		}
+	}
+}
+
+func TestZipInvalidArchive(t *testing.T) {
+	notZip := filepath.Join(t.TempDir(), "plain.zip")
+	if err := os.WriteFile(notZip, []byte("not a zip"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	for _, name := range []string{notZip, filepath.Join(t.TempDir(), "missing.zip")} {
+		if _, err := NewWithOptions(Options{ZipFile: name}, nil); err == nil {
+			t.Errorf("NewWithOptions() should reject %s", name)
+		}
+	}
+}